@@ -32,9 +32,8 @@ func main() {
 		LowerBound: 1024,
 		UpperBound: 4096,
 	}
-	// Create a string for the CPU Architecture so that it can be passed as a pointer
-	// when creating the Filter struct
-	cpuArch := "x86_64"
+	// Create a list of acceptable CPU Architectures, instance types supporting any of them will match
+	cpuArchs := []string{"x86_64"}
 
 	// Create a Filter struct with criteria you would like to filter
 	// The full struct definition can be found here for all of the supported filters:
@@ -42,7 +41,7 @@ func main() {
 	filters := selector.Filters{
 		VCpusRange:      &vcpusRange,
 		MemoryRange:     &memoryRange,
-		CPUArchitecture: &cpuArch,
+		CPUArchitecture: cpuArchs,
 	}
 
 	// Pass the Filter struct to the Filter function of your selector instance
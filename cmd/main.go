@@ -14,27 +14,50 @@
 package main
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"log"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	commandline "github.com/aws/amazon-ec2-instance-selector/pkg/cli"
+	"github.com/aws/amazon-ec2-instance-selector/pkg/controller"
+	"github.com/aws/amazon-ec2-instance-selector/pkg/karpenter"
+	"github.com/aws/amazon-ec2-instance-selector/pkg/optimizer"
+	"github.com/aws/amazon-ec2-instance-selector/pkg/publish"
 	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
 	"github.com/aws/amazon-ec2-instance-selector/pkg/selector/outputs"
+	"github.com/aws/amazon-ec2-instance-selector/pkg/server"
+	"github.com/aws/amazon-ec2-instance-selector/pkg/terraform"
+	"github.com/aws/amazon-ec2-instance-selector/pkg/webhook"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/computeoptimizer"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/ssm"
 )
 
 const (
 	binName = "ec2-instance-selector"
-	// cfnJSON is an output type
-	cfnJSON = "cfn-json"
-	// cfnYAML is an output type
-	cfnYAML         = "cfn-yaml"
-	terraformHCL    = "terraform-hcl"
-	tableOutput     = "table"
-	tableWideOutput = "table-wide"
+	// envVarPrefix is the prefix of the environment variables which set flags, like EC2_INSTANCE_SELECTOR_VCPUS
+	envVarPrefix = "EC2_INSTANCE_SELECTOR_"
+	// zoneIDSeparator separates the region and zone number of a zone id, like use1-az1
+	zoneIDSeparator = "-az"
 )
 
 // Filter Flag Constants
@@ -58,19 +81,149 @@ const (
 	currentGeneration      = "current-generation"
 	networkInterfaces      = "network-interfaces"
 	networkPerformance     = "network-performance"
+	baseInstanceType       = "base-instance-type"
+	instanceID             = "instance-id"
+	preset                 = "preset"
+	service                = "service"
 )
 
 // Configuration Flag Constants
 const (
-	maxResults = "max-results"
-	profile    = "profile"
-	help       = "help"
-	verbose    = "verbose"
-	version    = "version"
-	region     = "region"
-	output     = "output"
+	maxResults   = "max-results"
+	maxPerFamily = "max-per-family"
+	profile      = "profile"
+	help         = "help"
+	verbose      = "verbose"
+	version      = "version"
+	region       = "region"
+	allRegions   = "all-regions"
+	output       = "output"
+	relax        = "relax"
+	explain      = "explain"
+	filtersFile  = "filters-file"
+	nodePoolFile = "karpenter-nodepool"
+	candidates   = "candidates"
+	roleARN      = "role-arn"
+	externalID   = "external-id"
+	ec2Endpoint  = "ec2-endpoint"
+	profileName  = "profile-name"
+	dryRun       = "dry-run"
+	cacheDir     = "cache-dir"
+	cacheTTL     = "cache-ttl"
+	noCache      = "no-cache"
+	snapshotFile = "snapshot-file"
+	outputFile   = "output-file"
+	outputTmpl   = "output-template-file"
+	publishSSM   = "publish-ssm"
+	ssmFormat    = "publish-ssm-format"
+	updateASG    = "update-asg"
+	fromASG      = "from-asg"
+	fromLT       = "from-launch-template"
+	createNG     = "create-nodegroup"
+	eksCluster   = "eks-cluster"
+	capacityType = "capacity-type"
+	nodeRole     = "node-role"
+	subnets      = "subnets"
+	yes          = "yes"
+	spotZones    = "recommend-spot-zones"
+	launchTmplID = "launch-template-id"
+	validate     = "validate"
+	watch        = "watch"
+	minResults   = "min-results"
+	emitFilters  = "emit-filters"
+	showZones    = "show-zones"
+	quiet        = "quiet"
+	stdin        = "stdin"
+	sortBy       = "sort-by"
+	color        = "color"
+	listenAddr   = "listen-address"
+	metricsAddr  = "metrics-address"
+	metricsPols  = "metrics-policies"
+	resync       = "resync-interval"
+	targetCap    = "target-capacity"
+	tlsCertFile  = "tls-cert-file"
+	tlsKeyFile   = "tls-key-file"
 )
 
+// Color Flag Values
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+	// noColorEnvVar disables color when set to any value, see https://no-color.org
+	noColorEnvVar = "NO_COLOR"
+)
+
+// Command Constants
+const (
+	list     = "list"
+	compare  = "compare"
+	describe = "describe"
+	upgrade  = "upgrade"
+	cache    = "cache"
+	clear    = "clear"
+	metadata = "metadata"
+	serve    = "serve"
+	// controllerCmd is not named controller to avoid shadowing the controller package
+	controllerCmd     = "controller"
+	terraformExternal = "terraform-external"
+	recommendRegion   = "recommend-region"
+	rightsize         = "rightsize"
+	webhookCmd        = "webhook"
+	// the explain command uses the same name as the explain flag
+)
+
+// Exit Code Constants
+const (
+	// exitCodeError is returned for invalid input and other failures
+	exitCodeError = 1
+	// exitCodeNoMatches is returned when no instance types match the filters
+	exitCodeNoMatches = 2
+	// exitCodeTooFewMatches is returned when fewer instance types than --min-results match the filters
+	exitCodeTooFewMatches = 3
+	// exitCodeAPIError is returned when an EC2 API request fails
+	exitCodeAPIError = 4
+)
+
+const (
+	defaultMaxResults = 25
+	configDir         = ".ec2-instance-selector"
+	configFile        = "config.yaml"
+	defaultCacheDir   = "cache"
+	defaultListenAddr = "localhost:50051"
+	// defaultWebhookAddr listens on all interfaces since the Kubernetes API server calls the webhook through a Service
+	defaultWebhookAddr = ":8443"
+	webhookPath        = "/validate"
+	defaultResync      = 10 * time.Minute
+	defaultCacheTTL    = 24 * time.Hour
+)
+
+// ec2NameFlags are the flags which pass the matching instance types to EC2 APIs, so they need the EC2 instance type names
+var ec2NameFlags = []string{validate, showZones, updateASG, createNG}
+
+// serviceNameConflict returns the first flag which is set and needs EC2 instance type names when the service renames
+// the matching instance types, like sagemaker, or an empty string if there is none
+func serviceNameConflict(flags map[string]interface{}, serviceName *string) string {
+	if serviceName == nil || !selector.RenamesInstanceTypes(*serviceName) {
+		return ""
+	}
+	for _, flag := range ec2NameFlags {
+		if flags[flag] != nil {
+			return flag
+		}
+	}
+	return ""
+}
+
+// outputFileFormats maps an output file extension to the output format inferred for it
+var outputFileFormats = map[string]string{
+	".json": selector.OutputFormatJSON,
+	".yaml": selector.OutputFormatYAML,
+	".yml":  selector.OutputFormatYAML,
+	".csv":  selector.OutputFormatCSV,
+	".tf":   selector.OutputFormatTerraformHCL,
+}
+
 var (
 	// versionID is overridden at compilation with the version based on the git tag
 	versionID = "dev"
@@ -79,60 +232,193 @@ var (
 func main() {
 
 	log.SetOutput(os.Stderr)
+	// output plugins are registered before the flags so that they are listed as valid output formats
+	selector.RegisterOutputPlugins(os.Getenv("PATH"))
 
 	shortUsage := "A tool to filter EC2 Instance Types based on various resource criteria"
 	longUsage := binName + ` is a CLI tool to filter EC2 instance types based on resource criteria. 
 Filtering allows you to select all the instance types that match your application requirements.
-Full docs can be found at github.com/aws/amazon-` + binName
+Full docs can be found at github.com/aws/amazon-` + binName + `
+
+Commands:
+  list                                     List the instance types matching the filters (default when no command is given)
+  explain                                  List the instance types matching the filters and explain which filters rejected the others
+  compare <instance-type> <instance-type>  Compare the specs of two instance types
+  describe <instance-type>                 Print the full specs of an instance type and the availability zones offering it
+  upgrade <instance-type>                  Suggest newer generation instance types with the same specs
+  cache clear                              Remove the cached EC2 API responses
+  metadata                                 Print when the cached EC2 API responses were refreshed and which regions they cover
+  serve                                    Serve the filter, describe, and compare operations over gRPC
+  controller                               Run in a Kubernetes cluster and publish the instance types matching each InstanceTypeSelection to a ConfigMap
+  terraform-external                       Read a Terraform external data source query from stdin and print the matching instance types as its result
+  recommend-region                         Rank regions by Spot Placement Score, matching instance types, and spot price for the filters and --target-capacity
+  rightsize [arn...]                       Print the Compute Optimizer recommendations of instances and Auto Scaling groups which match the filters
+  webhook                                  Serve a Kubernetes validating admission webhook which rejects manifests referencing instance types that do not match the filters`
 	examples := fmt.Sprintf(`%s --vcpus 4 --region us-east-2 --availability-zone us-east-2b
-%s --memory-min 4096 --memory-max 8192 --vcpus-min 4 --vcpus-max 8 --region us-east-2`, binName, binName)
+%s list --memory-min 4096 --memory-max 8192 --vcpus-min 4 --vcpus-max 8 --region us-east-2
+%s explain --base-instance-type m5.xlarge --region us-east-2
+%s compare m5.xlarge m6i.xlarge --region us-east-2
+%s describe m5.xlarge --region us-east-2
+%s upgrade c5.4xlarge --cpu-architecture x86_64,arm64 --region us-east-2`, binName, binName, binName, binName, binName, binName)
 
 	cli := commandline.New(binName, shortUsage, longUsage, examples)
 
-	cliOutputTypes := []string{
-		tableOutput,
-		tableWideOutput,
-	}
-	resultsOutputFn := outputs.SimpleInstanceTypeOutput
-
 	// Registers flags with specific input types from the cli pkg
 	// Filter Flags - These will be grouped at the top of the help flags
 
 	cli.IntMinMaxRangeFlags(vcpus, cli.StringMe("c"), nil, "Number of vcpus available to the instance type.")
-	cli.IntMinMaxRangeFlags(memory, cli.StringMe("m"), nil, "Amount of Memory available in MiB (Example: 4096)")
+	cli.ByteQuantityMinMaxRangeFlags(memory, cli.StringMe("m"), nil, "Amount of Memory available in MiB unless a unit is given (Example: 4096 or 4 GiB)")
 	cli.RatioFlag(vcpusToMemoryRatio, nil, nil, "The ratio of vcpus to memory in MiB. (Example: 1:2)")
-	cli.StringFlag(cpuArchitecture, cli.StringMe("a"), nil, "CPU architecture [x86_64, i386, or arm64]", nil)
+	cli.StringSliceFlag(cpuArchitecture, cli.StringMe("a"), nil, "CPU architecture [x86_64, i386, or arm64] (comma-separated list matches any)",
+		commandline.OneOfValidator(cpuArchitecture, []string{ec2.ArchitectureTypeX8664, ec2.ArchitectureTypeI386, ec2.ArchitectureTypeArm64}))
 	cli.IntMinMaxRangeFlags(gpus, cli.StringMe("g"), nil, "Total Number of GPUs (Example: 4)")
-	cli.IntMinMaxRangeFlags(gpuMemoryTotal, nil, nil, "Number of GPUs' total memory in MiB (Example: 4096)")
-	cli.StringFlag(placementGroupStrategy, nil, nil, "Placement group strategy: [cluster, partition, spread]", nil)
-	cli.StringFlag(usageClass, cli.StringMe("u"), nil, "Usage class: [spot or on-demand]", nil)
-	cli.StringFlag(rootDeviceType, nil, nil, "Supported root device types: [ebs or instance-store]", nil)
+	cli.ByteQuantityMinMaxRangeFlags(gpuMemoryTotal, nil, nil, "Number of GPUs' total memory in MiB unless a unit is given (Example: 4096 or 4 GiB)")
+	cli.StringSliceFlag(placementGroupStrategy, nil, nil, "Placement group strategy: [cluster, partition, spread] (comma-separated list matches any)", nil)
+	cli.StringSliceFlag(usageClass, cli.StringMe("u"), nil, "Usage class: [spot or on-demand] (comma-separated list matches any)",
+		commandline.OneOfValidator(usageClass, []string{ec2.UsageClassTypeSpot, ec2.UsageClassTypeOnDemand}))
+	cli.StringSliceFlag(rootDeviceType, nil, nil, "Supported root device types: [ebs or instance-store] (comma-separated list matches any)", nil)
 	cli.BoolFlag(enaSupport, cli.StringMe("e"), nil, "Instance types where ENA is supported or required")
 	cli.BoolFlag(hibernationSupport, nil, nil, "Hibernation supported")
 	cli.BoolFlag(baremetal, nil, nil, "Bare Metal instance types (.metal instances)")
 	cli.BoolFlag(fpgaSupport, cli.StringMe("f"), nil, "FPGA instance types")
 	cli.BoolFlag(burstSupport, cli.StringMe("b"), nil, "Burstable instance types")
-	cli.StringFlag(hypervisor, nil, nil, "Hypervisor: [xen or nitro]", nil)
-	cli.StringFlag(availabilityZone, cli.StringMe("z"), nil, "Availability zone or zone id to check only EC2 capacity offered in a specific AZ", nil)
+	cli.StringSliceFlag(hypervisor, nil, nil, "Hypervisor: [xen or nitro] (comma-separated list matches any)", nil)
+	cli.StringFlag(availabilityZone, cli.StringMe("z"), nil, "Availability zone or zone id to check only EC2 capacity offered in a specific AZ, Local Zone, or Wavelength Zone (Example: us-west-2-lax-1a)", locationValidator(availabilityZone))
 	cli.BoolFlag(currentGeneration, nil, nil, "Current generation instance types (explicitly set this to false to not return current generation instance types)")
 	cli.IntMinMaxRangeFlags(networkInterfaces, nil, nil, "Number of network interfaces (ENIs) that can be attached to the instance")
 	cli.IntMinMaxRangeFlags(networkPerformance, nil, nil, "Bandwidth in Gib/s of network performance (Example: 100)")
+	cli.StringFlag(preset, nil, nil, fmt.Sprintf("Built-in preset of filters which other filter flags override: [%s]", strings.Join(selector.PresetNames(), ", ")), func(val interface{}) error {
+		if val == nil {
+			return nil
+		}
+		_, err := selector.FiltersFromPreset(*val.(*string))
+		return err
+	})
+	cli.StringFlag(service, nil, nil, fmt.Sprintf("AWS service the instance types are for. Non-EC2 services only return instance types the service supports, named the way the service names them (Example: ml.m5.large for sagemaker). ecs also prints which instance types support ENI trunking: [%s]", strings.Join(selector.ServiceNames(), ", ")),
+		commandline.OneOfValidator(service, selector.ServiceNames()))
+	cli.StringFlag(instanceID, nil, nil, "Running instance ID used to find instance types similar to its instance type in its availability zone (Example: i-0123456789abcdef0)", nil)
+	cli.StringFlag(baseInstanceType, nil, nil, "Instance type used to find similarly spec'd instance types (vcpus, memory, cpu architecture, gpus, and network performance) (Example: m5.xlarge)", nil)
 
 	// Configuration Flags - These will be grouped at the bottom of the help flags
 
-	cli.ConfigIntFlag(maxResults, nil, cli.IntMe(25), "The maximum number of instance types that match your criteria to return")
+	cli.ConfigIntFlag(maxResults, nil, nil, fmt.Sprintf("The maximum number of instance types that match your criteria to return, keeping the first by --%s or else the smallest current generation instance types (default %d)", sortBy, defaultMaxResults))
+	cli.ConfigIntFlag(maxPerFamily, nil, nil, "The maximum number of instance types to return from each instance family (i.e. m5, c5d), applied before --max-results")
+	cli.ConfigIntFlag(spotZones, nil, nil, "Print the given number of availability zones with the lowest current spot price for each matching instance type, along with the spot price in every zone offering it")
+	cli.ConfigStringFlag(sortBy, nil, nil, fmt.Sprintf("Comma-separated sort keys with an optional :asc or :desc direction applied before --max-results (Example: memory:desc,vcpus) (keys: %s)", strings.Join(selector.SortKeyNames(), ", ")), func(val interface{}) error {
+		if val == nil {
+			return nil
+		}
+		return selector.Filters{SortBy: strings.Split(*val.(*string), ",")}.Validate()
+	})
+	cli.ConfigIntFlag(targetCap, nil, nil, fmt.Sprintf("Number of spot instances the %s command evaluates each region for", recommendRegion))
+	cli.ConfigIntFlag(minResults, nil, nil, fmt.Sprintf("The minimum number of instance types that must match your criteria, otherwise exits with code %d", exitCodeTooFewMatches))
 	cli.ConfigStringFlag(profile, nil, nil, "AWS CLI profile to use for credentials and config", nil)
-	cli.ConfigStringFlag(region, cli.StringMe("r"), nil, "AWS Region to use for API requests (NOTE: if not passed in, uses AWS SDK default precedence)", nil)
-	cli.ConfigStringFlag(output, cli.StringMe("o"), nil, fmt.Sprintf("Specify the output format (%s)", strings.Join(cliOutputTypes, ", ")), nil)
+	cli.ConfigStringFlag(region, cli.StringMe("r"), nil, "AWS Region to use for API requests, or a comma-separated list of regions to filter in each region (NOTE: if not passed in, falls back to AWS_REGION, AWS_DEFAULT_REGION, the shared config, and EC2 instance metadata)", locationValidator(region))
+	cli.ConfigBoolFlag(allRegions, nil, nil, "Filter instance types in every region enabled for the account and print the results of each region")
+	cli.ConfigStringFlag(roleARN, nil, nil, "IAM role ARN to assume for API requests, like a role in another account (Example: arn:aws:iam::123456789012:role/instance-selector)", nil)
+	cli.ConfigStringFlag(externalID, nil, nil, "External ID to use when assuming the role passed to --role-arn", nil)
+	cli.ConfigStringFlag(ec2Endpoint, nil, nil, "EC2 API endpoint URL to use instead of the region's default, like a VPC interface endpoint, a FIPS endpoint, or LocalStack (Example: https://ec2-fips.us-east-1.amazonaws.com)", func(val interface{}) error {
+		if val == nil {
+			return nil
+		}
+		if endpointURL, err := url.Parse(*val.(*string)); err != nil || endpointURL.Scheme == "" || endpointURL.Host == "" {
+			return fmt.Errorf("Invalid input for --%s. A valid example is https://ec2-fips.us-east-1.amazonaws.com", ec2Endpoint)
+		}
+		return nil
+	})
+	cli.ConfigStringFlag(output, cli.StringMe("o"), nil, fmt.Sprintf("Specify the output format (%s)", strings.Join(selector.OutputFormatNames(), ", ")), func(val interface{}) error {
+		if val == nil {
+			return nil
+		}
+		_, err := selector.OutputFormat(*val.(*string))
+		return err
+	})
+	cli.ConfigStringFlag(outputFile, nil, nil, "Write the results to a file instead of stdout. The output format is inferred from a .json, .yaml, .yml, .csv, or .tf extension unless --output is set", nil)
+	cli.ConfigStringFlag(outputTmpl, nil, nil, fmt.Sprintf("Render all of the results through a Go template file, like a capacity report. The template receives the full instance type specs of the --%s json output format", output), func(val interface{}) error {
+		if val == nil {
+			return nil
+		}
+		_, err := outputs.ParseTemplateFile(*val.(*string))
+		return err
+	})
+	cli.ConfigStringFlag(launchTmplID, nil, nil, fmt.Sprintf("Launch template ID to use in the --%s %s config instead of a placeholder", output, selector.OutputFormatEC2Fleet), nil)
+	cli.ConfigBoolFlag(validate, nil, nil, fmt.Sprintf("Submit the --%s %s config to CreateFleet with DryRun to check for malformed configs and missing permissions without launching instances", output, selector.OutputFormatEC2Fleet))
+	cli.ConfigStringFlag(publishSSM, nil, nil, "Write the matching instance types to the SSM Parameter Store parameter, like /ec2/instance-types, so that CloudFormation and pipelines can consume them. The instance types are written with the names of the --service", nil)
+	cli.ConfigStringFlag(ssmFormat, nil, nil, fmt.Sprintf("Format of the parameter written by --%s: [%s (a StringList parameter) or %s (a String parameter holding a JSON array)] (default %s)", publishSSM, publish.SSMFormatStringList, publish.SSMFormatJSON, publish.SSMFormatStringList),
+		commandline.OneOfValidator(ssmFormat, publish.SSMFormats()))
+	cli.ConfigStringFlag(fromASG, nil, nil, "Suggest instance types to add to the Auto Scaling group which fit the spec envelope of the instance types it already uses (filter flags override the envelope)", nil)
+	cli.ConfigStringFlag(fromLT, nil, nil, "Suggest instance types which fit the spec envelope of the launch template's instance type, as a launch template ID or name with an optional :version (filter flags override the envelope)", nil)
+	cli.ConfigStringFlag(updateASG, nil, nil, "Rewrite the instance type overrides of the Auto Scaling group's MixedInstancesPolicy with the matching instance types. The changes are printed and must be confirmed before the group is updated", nil)
+	cli.ConfigStringFlag(createNG, nil, nil, fmt.Sprintf("Create an EKS managed nodegroup with the name in the --%s cluster using the matching instance types", eksCluster), nil)
+	cli.ConfigStringFlag(eksCluster, nil, nil, fmt.Sprintf("Name of the EKS cluster --%s creates the nodegroup in", createNG), nil)
+	cli.ConfigStringFlag(capacityType, nil, nil, fmt.Sprintf("Capacity type of the nodegroup created by --%s: [%s] (default %s)", createNG, strings.Join(publish.EKSCapacityTypes(), ", "), eks.CapacityTypesOnDemand),
+		commandline.OneOfValidator(capacityType, publish.EKSCapacityTypes()))
+	cli.ConfigStringFlag(nodeRole, nil, nil, fmt.Sprintf("ARN of the IAM role the nodes created by --%s use", createNG), nil)
+	cli.ConfigStringFlag(subnets, nil, nil, fmt.Sprintf("Comma-separated subnet IDs to launch the nodes created by --%s in (default the subnets of the cluster)", createNG), nil)
+	cli.ConfigBoolFlag(yes, nil, nil, fmt.Sprintf("Apply --%s without asking for confirmation", updateASG))
+	cli.ConfigStringFlag(filtersFile, nil, nil, "YAML or JSON file of filters to apply (filter flags override values in the file)", nil)
+	cli.ConfigStringFlag(nodePoolFile, nil, nil, "Karpenter NodePool or Provisioner manifest whose requirements are converted to filters (filter flags override the requirements)", nil)
+	cli.ConfigStringFlag(candidates, nil, nil, "File of candidate instance types (or - for stdin) to check against the filters instead of all instance types, like an ASG override list", nil)
+	cli.ConfigBoolFlag(stdin, nil, nil, "Read a newline-separated list of instance types from stdin and only apply the filters to them, like an ASG override list")
+	cli.ConfigStringFlag(profileName, nil, nil, fmt.Sprintf("Named profile of flag values to use from ~/%s/%s (flags override values in the profile)", configDir, configFile), nil)
+	cli.ConfigStringFlag(cacheDir, nil, nil, fmt.Sprintf("Directory to cache EC2 API responses in (default ~/%s/%s)", configDir, defaultCacheDir), nil)
+	cli.ConfigStringFlag(cacheTTL, nil, nil, fmt.Sprintf("How long cached EC2 API responses are used before they are refreshed (Example: 30m or 12h) (default %s)", defaultCacheTTL), func(val interface{}) error {
+		if val == nil {
+			return nil
+		}
+		if _, err := time.ParseDuration(*val.(*string)); err != nil {
+			return fmt.Errorf("Invalid input for --%s. A valid example is 12h", cacheTTL)
+		}
+		return nil
+	})
+	cli.ConfigBoolFlag(noCache, nil, nil, "Do not read or write cached EC2 API responses")
+	cli.ConfigStringFlag(snapshotFile, nil, nil, "JSON snapshot of instance type specs to filter instead of calling the EC2 API, like a file in the cache directory or a saved DescribeInstanceTypes response", nil)
+	cli.ConfigStringFlag(watch, nil, nil, "Re-evaluate the filters at the interval and print instance types which are added, removed, or change lowest spot price (Example: 10m)", func(val interface{}) error {
+		if val == nil {
+			return nil
+		}
+		if interval, err := time.ParseDuration(*val.(*string)); err != nil || interval <= 0 {
+			return fmt.Errorf("Invalid input for --%s. A valid example is 10m", watch)
+		}
+		return nil
+	})
+	cli.ConfigBoolFlag(quiet, cli.StringMe("q"), nil, "Suppress warnings and headers and print exactly one instance type per line, or a single JSON array of instance types with --output json")
+	cli.ConfigStringFlag(color, nil, nil, fmt.Sprintf("Highlight burstable, previous generation, and \"Up to\" network performance instance types in the default output: [%s (in a terminal unless %s is set), %s, or %s] (default %s)", colorAuto, noColorEnvVar, colorAlways, colorNever, colorNever),
+		commandline.OneOfValidator(color, []string{colorAuto, colorAlways, colorNever}))
+	cli.ConfigStringFlag(listenAddr, nil, nil, fmt.Sprintf("Address the %s command listens on for gRPC requests (default %s) or the %s command listens on for HTTPS requests (default %s)", serve, defaultListenAddr, webhookCmd, defaultWebhookAddr), nil)
+	cli.ConfigStringFlag(tlsCertFile, nil, nil, fmt.Sprintf("TLS certificate file the %s command serves HTTPS with", webhookCmd), nil)
+	cli.ConfigStringFlag(tlsKeyFile, nil, nil, fmt.Sprintf("TLS private key file of the --%s", tlsCertFile), nil)
+	cli.ConfigStringFlag(metricsAddr, nil, nil, fmt.Sprintf("Address the %s command serves Prometheus metrics on at /metrics (Example: :9100) (default disabled)", serve), nil)
+	cli.ConfigStringSliceFlag(metricsPols, nil, nil, fmt.Sprintf("Policy names of Filter requests the %s command reports matching instance types for in its metrics. Other policies are reported as \"other\" (comma-separated list)", serve), nil)
+	cli.ConfigStringFlag(resync, nil, nil, fmt.Sprintf("How often the %s command re-evaluates the filters of each InstanceTypeSelection (Example: 30m) (default %s)", controllerCmd, defaultResync), func(val interface{}) error {
+		if val == nil {
+			return nil
+		}
+		if interval, err := time.ParseDuration(*val.(*string)); err != nil || interval <= 0 {
+			return fmt.Errorf("Invalid input for --%s. A valid example is 30m", resync)
+		}
+		return nil
+	})
+	cli.ConfigBoolFlag(emitFilters, nil, nil, "Print the resolved filters as YAML to stderr so the results can be reproduced with --filters-file")
+	cli.ConfigBoolFlag(dryRun, nil, nil, "Print the EC2 API requests that would be made to filter instance types without making them")
+	cli.ConfigBoolFlag(explain, nil, nil, "Explain which filters rejected each instance type that did not match")
+	cli.ConfigBoolFlag(showZones, nil, nil, fmt.Sprintf("Include the availability zones each instance type is offered in with --%s, or --%s %s", verbose, output, strings.Join(zoneOutputFormatNames(), ", ")))
+	cli.ConfigBoolFlag(relax, nil, nil, "If no instance types match, progressively widen range filters and report which filters were relaxed")
 	cli.ConfigBoolFlag(verbose, cli.StringMe("v"), nil, "Verbose - will print out full instance specs")
 	cli.ConfigBoolFlag(help, cli.StringMe("h"), nil, "Help")
 	cli.ConfigBoolFlag(version, nil, nil, "Prints CLI version")
 
+	cli.UseEnvVars(envVarPrefix)
+	homeDir, err := os.UserHomeDir()
+	if err == nil {
+		cli.UseConfigFile(filepath.Join(homeDir, configDir, configFile), profileName)
+	}
+
 	// Parses the user input with the registered flags and runs type specific validation on the user input
 	flags, err := cli.ParseAndValidateFlags()
 	if err != nil {
 		log.Printf("There was an error while parsing the commandline flags: %v", err)
-		os.Exit(1)
+		os.Exit(exitCodeError)
 	}
 
 	if flags[help] != nil {
@@ -144,32 +430,288 @@ Full docs can be found at github.com/aws/amazon-` + binName
 		os.Exit(0)
 	}
 
-	sessOpts := session.Options{}
+	if flags[quiet] != nil {
+		log.SetOutput(ioutil.Discard)
+	}
+
+	command := list
+	args := cli.Args()
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	selectorCacheDir := ""
+	if homeDir != "" {
+		selectorCacheDir = filepath.Join(homeDir, configDir, defaultCacheDir)
+	}
+	if flags[cacheDir] != nil {
+		selectorCacheDir = *cli.StringMe(flags[cacheDir])
+	}
+	if command == cache {
+		if len(args) != 1 || args[0] != clear || selectorCacheDir == "" {
+			fmt.Printf("Usage: %s %s %s [--%s <dir>]", binName, cache, clear, cacheDir)
+			os.Exit(exitCodeError)
+		}
+		if err := selector.ClearCacheDir(selectorCacheDir); err != nil {
+			fmt.Printf("An error occurred when clearing the cache: %v", err)
+			os.Exit(exitCodeError)
+		}
+		os.Exit(0)
+	}
+	if command == metadata {
+		if len(args) != 0 || selectorCacheDir == "" {
+			fmt.Printf("Usage: %s %s [--%s <dir>]", binName, metadata, cacheDir)
+			os.Exit(exitCodeError)
+		}
+		cacheMetadata, err := selector.CacheDirMetadata(selectorCacheDir)
+		if err != nil {
+			fmt.Printf("An error occurred when reading the cache metadata: %v", err)
+			os.Exit(exitCodeError)
+		}
+		printCacheMetadata(selectorCacheDir, cacheMetadata)
+		os.Exit(0)
+	}
+
+	sessOpts := session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+	}
 
 	if flags[region] != nil {
-		sessOpts.Config.Region = cli.StringMe(flags[region])
+		// the first region is used for the session when a list of regions is passed
+		sessOpts.Config.Region = aws.String(regionsFromFlag(flags)[0])
 	}
 	if flags[profile] != nil {
 		sessOpts.Profile = *cli.StringMe(flags[profile])
 	}
 
 	sess := session.Must(session.NewSessionWithOptions(sessOpts))
+	if _, err := selector.ResolveRegion(sess); err != nil {
+		fmt.Printf("%v. Set the region with --%s.", err, region)
+		os.Exit(exitCodeError)
+	}
 
-	instanceSelector := selector.New(sess)
+	selectorOpts := []selector.Option{}
+	if flags[roleARN] != nil {
+		selectorOpts = append(selectorOpts, selector.WithAssumeRole(*cli.StringMe(flags[roleARN]), aws.StringValue(cli.StringMe(flags[externalID]))))
+	} else if flags[externalID] != nil {
+		fmt.Printf("--%s can only be used with --%s", externalID, roleARN)
+		os.Exit(exitCodeError)
+	}
+	if flags[ec2Endpoint] != nil {
+		selectorOpts = append(selectorOpts, selector.WithEndpoint(*cli.StringMe(flags[ec2Endpoint])))
+	}
+	// watching and the controller need fresh results from the EC2 API each interval,
+	// a custom endpoint like LocalStack may not serve the same instance types as the region's cached responses,
+	// and the cache files are named by region so another account's offerings and zone names would be read with a role or profile
+	if flags[noCache] == nil && flags[watch] == nil && flags[ec2Endpoint] == nil && flags[roleARN] == nil && flags[profile] == nil && command != controllerCmd {
+		selectorCacheTTL := defaultCacheTTL
+		if flags[cacheTTL] != nil {
+			selectorCacheTTL, _ = time.ParseDuration(*cli.StringMe(flags[cacheTTL]))
+		}
+		selectorOpts = append(selectorOpts, selector.WithCache(selectorCacheTTL), selector.WithCacheDir(selectorCacheDir))
+	}
+	if flags[snapshotFile] != nil {
+		provider, err := selector.LoadSnapshotFile(*cli.StringMe(flags[snapshotFile]))
+		if err != nil {
+			fmt.Printf("An error occurred when loading the snapshot: %v", err)
+			os.Exit(exitCodeError)
+		}
+		selectorOpts = append(selectorOpts, selector.WithDataProvider(provider))
+	}
+	instanceSelector := selector.NewWithOptions(sess, selectorOpts...)
+	if flags[roleARN] != nil {
+		// the clients of the other services, like Auto Scaling, SSM, and EKS, act in the role's account as the EC2 clients do
+		sess = sess.Copy(&aws.Config{Credentials: stscreds.NewCredentials(sess, *cli.StringMe(flags[roleARN]), func(provider *stscreds.AssumeRoleProvider) {
+			provider.ExternalID = cli.StringMe(flags[externalID])
+		})})
+	}
 
+	switch command {
+	case list, explain:
+		if len(args) != 0 {
+			fmt.Printf("Usage: %s %s [flags]", binName, command)
+			os.Exit(exitCodeError)
+		}
+		listInstanceTypes(&cli, flags, sess, instanceSelector, command == explain || flags[explain] != nil)
+	case compare:
+		if len(args) != 2 {
+			fmt.Printf("Usage: %s %s <instance-type> <instance-type>", binName, compare)
+			os.Exit(exitCodeError)
+		}
+		comparison, err := instanceSelector.Compare(args[0], args[1])
+		if err != nil {
+			fmt.Printf("An error occurred when comparing instance types: %v", err)
+			os.Exit(exitCodeForError(err))
+		}
+		printComparison(comparison)
+	case describe:
+		if len(args) != 1 {
+			fmt.Printf("Usage: %s %s <instance-type>", binName, describe)
+			os.Exit(exitCodeError)
+		}
+		lookup, err := instanceSelector.GetInstanceTypeDetails(args[0])
+		if err != nil {
+			fmt.Printf("An error occurred when describing the instance type: %v", err)
+			os.Exit(exitCodeForError(err))
+		}
+		lookupJSON, err := json.MarshalIndent(lookup, "", "    ")
+		if err != nil {
+			fmt.Printf("An error occurred when printing the instance type: %v", err)
+			os.Exit(exitCodeError)
+		}
+		fmt.Println(string(lookupJSON))
+	case upgrade:
+		if len(args) != 1 {
+			fmt.Printf("Usage: %s %s <instance-type> [--%s <cpu-architectures>]", binName, upgrade, cpuArchitecture)
+			os.Exit(exitCodeError)
+		}
+		suggestions, err := instanceSelector.SuggestNewerGeneration(args[0], cli.StringSliceMe(flags[cpuArchitecture]))
+		if err != nil {
+			fmt.Printf("An error occurred when suggesting newer generation instance types: %v", err)
+			os.Exit(exitCodeForError(err))
+		}
+		for _, suggestion := range suggestions {
+			fmt.Println(suggestion)
+		}
+	case serve:
+		if len(args) != 0 {
+			fmt.Printf("Usage: %s %s [--%s <address>]", binName, serve, listenAddr)
+			os.Exit(exitCodeError)
+		}
+		address := defaultListenAddr
+		if flags[listenAddr] != nil {
+			address = *cli.StringMe(flags[listenAddr])
+		}
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			fmt.Printf("An error occurred when listening on %s: %v", address, err)
+			os.Exit(exitCodeError)
+		}
+		grpcServer := server.NewGRPCServer(instanceSelector)
+		if flags[metricsAddr] != nil {
+			metrics := server.NewMetrics(instanceSelector.CacheStats, cli.StringSliceMe(flags[metricsPols]))
+			if ec2Client, ok := instanceSelector.EC2.(*ec2.EC2); ok {
+				metrics.InstrumentHandlers(&ec2Client.Handlers)
+			}
+			grpcServer = server.NewInstrumentedGRPCServer(instanceSelector, metrics)
+			go serveMetrics(*cli.StringMe(flags[metricsAddr]), metrics)
+		}
+		log.Printf("Serving gRPC requests on %s\n", listener.Addr())
+		if err := grpcServer.Serve(listener); err != nil {
+			fmt.Printf("An error occurred when serving gRPC requests: %v", err)
+			os.Exit(exitCodeError)
+		}
+	case controllerCmd:
+		if len(args) != 0 {
+			fmt.Printf("Usage: %s %s [--%s <interval>]", binName, controllerCmd, resync)
+			os.Exit(exitCodeError)
+		}
+		interval := defaultResync
+		if flags[resync] != nil {
+			interval, _ = time.ParseDuration(*cli.StringMe(flags[resync]))
+		}
+		client, err := controller.NewInClusterClient()
+		if err != nil {
+			fmt.Printf("An error occurred when connecting to the Kubernetes API: %v", err)
+			os.Exit(exitCodeError)
+		}
+		log.Printf("Reconciling %s resources every %s\n", controller.Kind, interval)
+		controller.New(client, instanceSelector, interval).Run(nil)
+	case terraformExternal:
+		if len(args) != 0 {
+			fmt.Printf("Usage: %s %s < query.json", binName, terraformExternal)
+			os.Exit(exitCodeError)
+		}
+		// terraform shows stderr when the program fails, stdout must only contain the result
+		if err := terraform.Run(instanceSelector, os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "An error occurred when running the Terraform external data source: %v", err)
+			os.Exit(exitCodeForError(err))
+		}
+	case recommendRegion:
+		if len(args) != 0 || flags[targetCap] == nil {
+			fmt.Printf("Usage: %s %s --%s <instances> [--%s <regions> | --%s] [flags]", binName, recommendRegion, targetCap, region, allRegions)
+			os.Exit(exitCodeError)
+		}
+		if flags[snapshotFile] != nil {
+			fmt.Printf("--%s cannot be used with %s since the snapshot holds the instance types of a single region", snapshotFile, recommendRegion)
+			os.Exit(exitCodeError)
+		}
+		regions := regionsFromFlag(flags)
+		if flags[allRegions] != nil || len(regions) < 2 {
+			var err error
+			regions, err = instanceSelector.EnabledRegions()
+			if err != nil {
+				fmt.Printf("An error occurred when retrieving the enabled regions: %v", err)
+				os.Exit(exitCodeForError(err))
+			}
+		}
+		recommendations, err := instanceSelector.RecommendRegions(filtersFromFlags(&cli, flags, instanceSelector), regions, *cli.IntMe(flags[targetCap]))
+		if err != nil {
+			fmt.Printf("An error occurred when recommending regions: %v", err)
+			os.Exit(exitCodeForError(err))
+		}
+		printRegionRecommendations(recommendations)
+	case rightsize:
+		recommendations, err := optimizer.Recommendations(computeoptimizer.New(sess), args)
+		if err != nil {
+			fmt.Printf("An error occurred when retrieving Compute Optimizer recommendations: %v", err)
+			os.Exit(exitCodeForError(err))
+		}
+		recommendations, err = optimizer.Intersect(instanceSelector, filtersFromFlags(&cli, flags, instanceSelector), recommendations)
+		if err != nil {
+			fmt.Printf("An error occurred when filtering Compute Optimizer recommendations: %v", err)
+			os.Exit(exitCodeForError(err))
+		}
+		printRightsizingRecommendations(recommendations)
+	case webhookCmd:
+		if len(args) != 0 || flags[tlsCertFile] == nil || flags[tlsKeyFile] == nil {
+			fmt.Printf("Usage: %s %s --%s <file> --%s <file> [--%s <address>] [filter flags]", binName, webhookCmd, tlsCertFile, tlsKeyFile, listenAddr)
+			os.Exit(exitCodeError)
+		}
+		address := defaultWebhookAddr
+		if flags[listenAddr] != nil {
+			address = *cli.StringMe(flags[listenAddr])
+		}
+		mux := http.NewServeMux()
+		mux.Handle(webhookPath, webhook.New(instanceSelector, filtersFromFlags(&cli, flags, instanceSelector)))
+		log.Printf("Serving admission reviews on %s%s\n", address, webhookPath)
+		if err := http.ListenAndServeTLS(address, *cli.StringMe(flags[tlsCertFile]), *cli.StringMe(flags[tlsKeyFile]), mux); err != nil {
+			fmt.Printf("An error occurred when serving admission reviews: %v", err)
+			os.Exit(exitCodeError)
+		}
+	default:
+		fmt.Printf("Unknown command %s, the supported commands are: [%s]", command, strings.Join([]string{list, explain, compare, describe, upgrade, cache, metadata, serve, controllerCmd, terraformExternal, recommendRegion, rightsize, webhookCmd}, ", "))
+		os.Exit(exitCodeError)
+	}
+}
+
+// serveMetrics serves the Prometheus metrics at /metrics on the address and exits if the address cannot be listened on
+func serveMetrics(address string, metrics *server.Metrics) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	log.Printf("Serving Prometheus metrics on %s/metrics\n", address)
+	if err := http.ListenAndServe(address, mux); err != nil {
+		fmt.Printf("An error occurred when serving Prometheus metrics: %v", err)
+		os.Exit(exitCodeError)
+	}
+}
+
+// filtersFromFlags builds the filters from the filter flags, the running instance, stdin, the filters file, and the preset.
+// Filter flags override the values of the other sources.
+func filtersFromFlags(cli *commandline.CommandLineInterface, flags map[string]interface{}, instanceSelector *selector.Selector) selector.Filters {
 	filters := selector.Filters{
 		VCpusRange:             cli.IntRangeMe(flags[vcpus]),
 		MemoryRange:            cli.IntRangeMe(flags[memory]),
 		VCpusToMemoryRatio:     cli.Float64Me(flags[vcpusToMemoryRatio]),
-		CPUArchitecture:        cli.StringMe(flags[cpuArchitecture]),
+		CPUArchitecture:        cli.StringSliceMe(flags[cpuArchitecture]),
 		GpusRange:              cli.IntRangeMe(flags[gpus]),
 		GpuMemoryRange:         cli.IntRangeMe(flags[gpuMemoryTotal]),
-		PlacementGroupStrategy: cli.StringMe(flags[placementGroupStrategy]),
-		UsageClass:             cli.StringMe(flags[usageClass]),
-		RootDeviceType:         cli.StringMe(flags[rootDeviceType]),
+		PlacementGroupStrategy: cli.StringSliceMe(flags[placementGroupStrategy]),
+		UsageClass:             cli.StringSliceMe(flags[usageClass]),
+		RootDeviceType:         cli.StringSliceMe(flags[rootDeviceType]),
 		EnaSupport:             cli.BoolMe(flags[enaSupport]),
 		HibernationSupported:   cli.BoolMe(flags[hibernationSupport]),
-		Hypervisor:             cli.StringMe(flags[hypervisor]),
+		Hypervisor:             cli.StringSliceMe(flags[hypervisor]),
 		BareMetal:              cli.BoolMe(flags[baremetal]),
 		Fpga:                   cli.BoolMe(flags[fpgaSupport]),
 		Burstable:              cli.BoolMe(flags[burstSupport]),
@@ -177,8 +719,76 @@ Full docs can be found at github.com/aws/amazon-` + binName
 		AvailabilityZone:       cli.StringMe(flags[availabilityZone]),
 		CurrentGeneration:      cli.BoolMe(flags[currentGeneration]),
 		MaxResults:             cli.IntMe(flags[maxResults]),
+		MaxResultsPerFamily:    cli.IntMe(flags[maxPerFamily]),
+		SortBy:                 sortByFromFlag(flags),
 		NetworkInterfaces:      cli.IntRangeMe(flags[networkInterfaces]),
 		NetworkPerformance:     cli.IntRangeMe(flags[networkPerformance]),
+		BaseInstanceType:       cli.StringMe(flags[baseInstanceType]),
+		Service:                cli.StringMe(flags[service]),
+	}
+
+	if flags[instanceID] != nil {
+		instanceFilters, err := instanceSelector.FiltersFromInstanceID(*cli.StringMe(flags[instanceID]))
+		if err != nil {
+			fmt.Printf("An error occurred when retrieving the instance: %v", err)
+			os.Exit(exitCodeForError(err))
+		}
+		filters = instanceFilters.Merge(filters)
+	}
+	if flags[stdin] != nil {
+		stdinInstanceTypes, err := loadCandidates("-")
+		if err != nil {
+			fmt.Printf("An error occurred when loading instance types: %v", err)
+			os.Exit(exitCodeError)
+		}
+		filters.InstanceTypes = stdinInstanceTypes
+	}
+	if flags[filtersFile] != nil {
+		fileFilters, err := selector.LoadFiltersFile(*cli.StringMe(flags[filtersFile]))
+		if err != nil {
+			fmt.Printf("An error occurred when loading the filters file: %v", err)
+			os.Exit(exitCodeError)
+		}
+		filters = fileFilters.Merge(filters)
+	}
+	if flags[nodePoolFile] != nil {
+		nodePoolFilters, unsupported, err := karpenter.LoadNodePoolFile(*cli.StringMe(flags[nodePoolFile]))
+		if err != nil {
+			fmt.Printf("An error occurred when loading the Karpenter manifest: %v", err)
+			os.Exit(exitCodeError)
+		}
+		for _, requirement := range unsupported {
+			log.Printf("Ignoring the Karpenter requirement %s %s %s which has no equivalent filter; Karpenter may consider fewer instance types\n", requirement.Key, requirement.Operator, strings.Join(requirement.Values, ","))
+		}
+		filters = nodePoolFilters.Merge(filters)
+	}
+	if flags[preset] != nil {
+		presetFilters, err := selector.FiltersFromPreset(*cli.StringMe(flags[preset]))
+		if err != nil {
+			fmt.Printf("An error occurred when loading the preset: %v", err)
+			os.Exit(exitCodeError)
+		}
+		filters = presetFilters.Merge(filters)
+	}
+	if filters.MaxResults == nil {
+		filters.MaxResults = cli.IntMe(defaultMaxResults)
+	}
+	return filters
+}
+
+// listInstanceTypes builds the filters from the flags and prints the matching instance types in the requested output format.
+// If explainRejections is true, the filters which rejected each non-matching instance type are printed to stderr.
+func listInstanceTypes(cli *commandline.CommandLineInterface, flags map[string]interface{}, sess *session.Session, instanceSelector *selector.Selector, explainRejections bool) {
+	resultsOutputFn := outputs.SimpleInstanceTypeOutput
+	filters := filtersFromFlags(cli, flags, instanceSelector)
+
+	if flags[emitFilters] != nil {
+		filtersYAML, err := filters.ToYAML()
+		if err != nil {
+			fmt.Printf("An error occurred when printing filters due to --%s being specified: %v", emitFilters, err)
+			os.Exit(exitCodeError)
+		}
+		fmt.Fprint(os.Stderr, filtersYAML)
 	}
 
 	if flags[verbose] != nil {
@@ -186,44 +796,740 @@ Full docs can be found at github.com/aws/amazon-` + binName
 		filtersJSON, err := json.MarshalIndent(filters, "", "    ")
 		if err != nil {
 			fmt.Printf("An error occurred when printing filters due to --verbose being specified: %v", err)
-			os.Exit(1)
+			os.Exit(exitCodeError)
 		}
 		log.Println("\n\n\"Filters\":", string(filtersJSON))
 	}
 
+	if regions := regionsFromFlag(flags); len(regions) > 1 || flags[allRegions] != nil {
+		if flags[snapshotFile] != nil {
+			fmt.Printf("--%s can only be used with a single region", snapshotFile)
+			os.Exit(exitCodeError)
+		}
+		if flags[allRegions] != nil {
+			var err error
+			regions, err = instanceSelector.EnabledRegions()
+			if err != nil {
+				fmt.Printf("An error occurred when retrieving the enabled regions: %v", err)
+				os.Exit(exitCodeForError(err))
+			}
+		}
+		if flags[publishSSM] != nil {
+			fmt.Printf("--%s can only be used with a single region", publishSSM)
+			os.Exit(exitCodeError)
+		}
+		if flags[updateASG] != nil {
+			fmt.Printf("--%s can only be used with a single region", updateASG)
+			os.Exit(exitCodeError)
+		}
+		if flags[fromASG] != nil || flags[fromLT] != nil {
+			fmt.Printf("--%s and --%s can only be used with a single region", fromASG, fromLT)
+			os.Exit(exitCodeError)
+		}
+		if flags[createNG] != nil {
+			fmt.Printf("--%s can only be used with a single region", createNG)
+			os.Exit(exitCodeError)
+		}
+		if flags[showZones] != nil {
+			fmt.Printf("--%s can only be used with a single region", showZones)
+			os.Exit(exitCodeError)
+		}
+		if flags[ec2Endpoint] != nil {
+			fmt.Printf("--%s can only be used with a single region", ec2Endpoint)
+			os.Exit(exitCodeError)
+		}
+		filters.Region = nil
+		listInstanceTypesAcrossRegions(instanceSelector, filters, regions, flags[quiet] != nil)
+		return
+	}
+
+	if flag := serviceNameConflict(flags, filters.Service); flag != "" {
+		fmt.Printf("--%s cannot be used with --%s %s since it needs the EC2 instance type names", flag, service, *filters.Service)
+		os.Exit(exitCodeError)
+	}
+
+	if flags[dryRun] != nil {
+		plan, err := instanceSelector.DryRun(filters)
+		if err != nil {
+			fmt.Printf("An error occurred when planning the EC2 API requests: %v", err)
+			os.Exit(exitCodeError)
+		}
+		printAPICallPlan(plan)
+		os.Exit(0)
+	}
+
+	// zone names map to different physical zones in each account, so the zone id is logged to compare results across accounts
+	if zone := aws.StringValue(filters.AvailabilityZone); selector.IsZone(zone) && instanceSelector.DataProvider == nil {
+		resolvedZone, err := instanceSelector.ResolveZone(zone)
+		if err != nil {
+			fmt.Printf("An error occurred when resolving the availability zone: %v", err)
+			os.Exit(exitCodeForError(err))
+		}
+		if flags[quiet] == nil {
+			log.Printf("Filtering instance types offered in %s\n", resolvedZone)
+		}
+	}
+
+	if flags[candidates] != nil {
+		candidateInstanceTypes, err := loadCandidates(*cli.StringMe(flags[candidates]))
+		if err != nil {
+			fmt.Printf("An error occurred when loading candidate instance types: %v", err)
+			os.Exit(exitCodeError)
+		}
+		auditResults, err := instanceSelector.Audit(filters, candidateInstanceTypes)
+		if err != nil {
+			fmt.Printf("An error occurred when filtering candidate instance types: %v", err)
+			os.Exit(exitCodeForError(err))
+		}
+		if !printAuditResults(auditResults) {
+			os.Exit(exitCodeError)
+		}
+		os.Exit(0)
+	}
+
+	if flags[watch] != nil {
+		interval, _ := time.ParseDuration(*cli.StringMe(flags[watch]))
+		watchInstanceTypes(instanceSelector, filters, interval)
+	}
+
+	if flags[spotZones] != nil {
+		placements, err := instanceSelector.RecommendSpotPlacement(filters, *cli.IntMe(flags[spotZones]))
+		if err != nil {
+			fmt.Printf("An error occurred when recommending spot availability zones: %v", err)
+			os.Exit(exitCodeForError(err))
+		}
+		if len(placements) == 0 {
+			log.Println("The criteria was too narrow and returned no valid instance types. Consider broadening your criteria so that more instance types are returned.")
+			os.Exit(exitCodeNoMatches)
+		}
+		printSpotPlacements(placements)
+		os.Exit(0)
+	}
+
 	outputFlag := cli.StringMe(flags[output])
+	if outputFlag == nil && flags[outputFile] != nil {
+		if format, ok := outputFileFormats[strings.ToLower(filepath.Ext(*cli.StringMe(flags[outputFile])))]; ok {
+			outputFlag = &format
+		}
+	}
 	outputFn := getOutputFn(outputFlag, selector.InstanceTypesOutputFn(resultsOutputFn))
+	if flags[launchTmplID] != nil && aws.StringValue(outputFlag) == selector.OutputFormatEC2Fleet {
+		outputFn = selector.InstanceTypesOutputFn(outputs.EC2FleetJSONOutput(*cli.StringMe(flags[launchTmplID])))
+	}
+	if flags[validate] != nil && aws.StringValue(outputFlag) != selector.OutputFormatEC2Fleet {
+		fmt.Printf("--%s can only be used with --%s %s", validate, output, selector.OutputFormatEC2Fleet)
+		os.Exit(exitCodeError)
+	}
+	if flags[outputTmpl] != nil {
+		if flags[output] != nil {
+			fmt.Printf("--%s cannot be used with --%s", outputTmpl, output)
+			os.Exit(exitCodeError)
+		}
+		tmpl, err := outputs.ParseTemplateFile(*cli.StringMe(flags[outputTmpl]))
+		if err != nil {
+			fmt.Printf("An error occurred when parsing the output template: %v", err)
+			os.Exit(exitCodeError)
+		}
+		outputFn = selector.InstanceTypesOutputFn(outputs.TemplateOutput(tmpl))
+	}
+	if outputFlag == nil && flags[verbose] == nil && flags[outputFile] == nil && flags[outputTmpl] == nil && useColor(cli.StringMe(flags[color])) {
+		outputFn = selector.InstanceTypesOutputFn(outputs.ColorInstanceTypeOutput)
+	}
+	if flags[quiet] != nil {
+		outputFn = selector.InstanceTypesOutputFn(outputs.SimpleInstanceTypeOutput)
+		if aws.StringValue(outputFlag) == selector.OutputFormatJSON {
+			outputFn = selector.InstanceTypesOutputFn(outputs.JSONInstanceTypeNamesOutput)
+		}
+	}
 
-	instanceTypes, err := instanceSelector.FilterWithOutput(filters, outputFn)
-	if err != nil {
-		fmt.Printf("An error occurred when filtering instance types: %v", err)
-		os.Exit(1)
+	var zoneOutputFn func(map[string][]string) func([]*ec2.InstanceTypeInfo) []string
+	if flags[showZones] != nil {
+		zoneFormat := aws.StringValue(outputFlag)
+		if outputFlag == nil && flags[verbose] != nil {
+			zoneFormat = selector.OutputFormatVerbose
+		}
+		var ok bool
+		if zoneOutputFn, ok = zoneOutputFormats[zoneFormat]; !ok || flags[quiet] != nil || flags[outputTmpl] != nil {
+			fmt.Printf("--%s can only be used with --%s, or --%s %s", showZones, verbose, output, strings.Join(zoneOutputFormatNames(), ", "))
+			os.Exit(exitCodeError)
+		}
+	}
+
+	var instanceTypeInfoSlice []*ec2.InstanceTypeInfo
+	if flags[fromASG] != nil || flags[fromLT] != nil {
+		current := currentInstanceTypes(cli, flags, sess, instanceSelector)
+		diversification, err := instanceSelector.Diversify(current, filters)
+		if err != nil {
+			fmt.Printf("An error occurred when suggesting instance types: %v", err)
+			os.Exit(exitCodeForError(err))
+		}
+		if flags[quiet] == nil {
+			log.Printf("Suggesting instance types compatible with the current instance types: %s\n", strings.Join(current, ", "))
+		}
+		instanceTypeInfoSlice = diversification.InstanceTypes
+	} else if explainRejections {
+		explanation, err := instanceSelector.Explain(filters)
+		if err != nil {
+			fmt.Printf("An error occurred when filtering instance types: %v", err)
+			os.Exit(exitCodeForError(err))
+		}
+		if flags[quiet] == nil {
+			printExplanation(explanation)
+		}
+		instanceTypeInfoSlice = explanation.InstanceTypes
+	} else if flags[relax] != nil {
+		relaxedResults, err := instanceSelector.FilterRelaxed(filters)
+		if err != nil {
+			fmt.Printf("An error occurred when filtering instance types: %v", err)
+			os.Exit(exitCodeForError(err))
+		}
+		for _, relaxation := range relaxedResults.Relaxations {
+			log.Printf("No instance types matched the original criteria, relaxed filter: %s\n", relaxation)
+		}
+		instanceTypeInfoSlice = relaxedResults.InstanceTypes
+	} else {
+		var err error
+		instanceTypeInfoSlice, err = instanceSelector.FilterVerbose(filters)
+		if err != nil {
+			fmt.Printf("An error occurred when filtering instance types: %v", err)
+			os.Exit(exitCodeForError(err))
+		}
 	}
-	if len(instanceTypes) == 0 {
+	if len(instanceTypeInfoSlice) == 0 {
 		log.Println("The criteria was too narrow and returned no valid instance types. Consider broadening your criteria so that more instance types are returned.")
-		os.Exit(1)
+		os.Exit(exitCodeNoMatches)
+	}
+	if flags[minResults] != nil && len(instanceTypeInfoSlice) < *cli.IntMe(flags[minResults]) {
+		log.Printf("Only %d instance types matched the criteria but --%s requires %d. Consider broadening your criteria so that more instance types are returned.\n", len(instanceTypeInfoSlice), minResults, *cli.IntMe(flags[minResults]))
+		os.Exit(exitCodeTooFewMatches)
+	}
+	if aws.StringValue(filters.Service) == selector.ServiceECS && flags[quiet] == nil {
+		printENITrunking(instanceTypeInfoSlice)
+	}
+	if flags[publishSSM] != nil {
+		name := *cli.StringMe(flags[publishSSM])
+		format := publish.SSMFormatStringList
+		if flags[ssmFormat] != nil {
+			format = *cli.StringMe(flags[ssmFormat])
+		}
+		version, err := publish.SSMParameter(ssm.New(sess), name, outputs.SimpleInstanceTypeOutput(instanceTypeInfoSlice), format)
+		if err != nil {
+			fmt.Printf("An error occurred when publishing the results to SSM Parameter Store: %v", err)
+			os.Exit(exitCodeForError(err))
+		}
+		log.Printf("Published %d instance types to SSM parameter %s (version %d)\n", len(instanceTypeInfoSlice), name, version)
+	}
+	if flags[updateASG] != nil {
+		// stdin cannot be prompted for a confirmation once the instance types were read from it
+		assumeYes := flags[yes] != nil
+		if !assumeYes && flags[stdin] != nil {
+			fmt.Printf("--%s requires --%s when instance types are read from stdin", updateASG, yes)
+			os.Exit(exitCodeError)
+		}
+		updateAutoScalingGroup(autoscaling.New(sess), *cli.StringMe(flags[updateASG]), outputs.SimpleInstanceTypeOutput(instanceTypeInfoSlice), assumeYes)
+	}
+	if flags[createNG] != nil {
+		createNodegroup(eks.New(sess), cli, flags, instanceTypeInfoSlice)
+	}
+	if zoneOutputFn != nil {
+		zones, err := instanceSelector.RetrieveInstanceTypeZones()
+		if err != nil {
+			fmt.Printf("An error occurred when retrieving the availability zones of the instance types: %v", err)
+			os.Exit(exitCodeForError(err))
+		}
+		if zones == nil {
+			log.Printf("The availability zones of the instance types are not known, ignoring --%s\n", showZones)
+		} else {
+			outputFn = selector.InstanceTypesOutputFn(zoneOutputFn(zones))
+		}
+	}
+	instanceTypes := outputFn.Output(instanceTypeInfoSlice)
+	if flags[validate] != nil {
+		// the config is generated again since --quiet replaces the output
+		fleetConfig := outputs.EC2FleetJSONOutput(aws.StringValue(cli.StringMe(flags[launchTmplID])))(instanceTypeInfoSlice)
+		if err := instanceSelector.ValidateFleetConfig(strings.Join(fleetConfig, "\n")); err != nil {
+			fmt.Printf("An error occurred when validating the %s config: %v", selector.OutputFormatEC2Fleet, err)
+			os.Exit(exitCodeForError(err))
+		}
+		log.Printf("The %s config passed CreateFleet DryRun validation\n", selector.OutputFormatEC2Fleet)
 	}
 
+	if flags[outputFile] != nil {
+		path := *cli.StringMe(flags[outputFile])
+		if err := ioutil.WriteFile(path, []byte(strings.Join(instanceTypes, "\n")+"\n"), 0644); err != nil {
+			fmt.Printf("An error occurred when writing the results to %s: %v", path, err)
+			os.Exit(exitCodeError)
+		}
+		return
+	}
 	for _, instanceType := range instanceTypes {
 		fmt.Println(instanceType)
 	}
 }
 
-func getOutputFn(outputFlag *string, currentFn selector.InstanceTypesOutputFn) selector.InstanceTypesOutputFn {
-	outputFn := selector.InstanceTypesOutputFn(currentFn)
+// useColor returns true if output should be colorized for the --color flag value.
+// In auto mode, color is only used when stdout is a terminal and the NO_COLOR env var is not set.
+func useColor(colorFlag *string) bool {
+	switch aws.StringValue(colorFlag) {
+	case colorAlways:
+		return true
+	case colorAuto:
+		if _, ok := os.LookupEnv(noColorEnvVar); ok {
+			return false
+		}
+		stdoutInfo, err := os.Stdout.Stat()
+		return err == nil && stdoutInfo.Mode()&os.ModeCharDevice != 0
+	}
+	return false
+}
+
+// regionsFromFlag returns the regions passed to --region, which can be a comma-separated list
+func regionsFromFlag(flags map[string]interface{}) []string {
+	regionFlag, ok := flags[region].(*string)
+	if !ok || regionFlag == nil {
+		return nil
+	}
+	regions := []string{}
+	for _, r := range strings.Split(*regionFlag, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			regions = append(regions, r)
+		}
+	}
+	return regions
+}
+
+// locationValidator returns a validator for flags accepting a comma-separated list of regions or availability zones.
+// An invalid location is reported with the closest known regions and availability zones so that typos are easy to fix.
+// A valid location which is not known to the AWS SDK only logs a warning since it may be a region launched after the SDK was released.
+func locationValidator(flagName string) func(val interface{}) error {
+	return func(val interface{}) error {
+		if val == nil {
+			return nil
+		}
+		locations := knownLocations()
+		for _, location := range strings.Split(*val.(*string), ",") {
+			location = strings.TrimSpace(location)
+			if err := selector.ValidateLocation(location); err != nil {
+				return fmt.Errorf("Invalid input for --%s: %v%s", flagName, err, commandline.DidYouMean(location, locations))
+			}
+			if !isKnownLocation(locations, location) {
+				log.Printf("Warning: --%s %s is not a region or availability zone known to this version of %s%s\n", flagName, location, binName, commandline.DidYouMean(location, locations))
+			}
+		}
+		return nil
+	}
+}
+
+// knownLocations returns the regions known to the AWS SDK along with their likely availability zone names
+func knownLocations() []string {
+	locations := []string{}
+	for _, partition := range endpoints.DefaultPartitions() {
+		for regionName := range partition.Regions() {
+			locations = append(locations, regionName)
+			for _, zoneSuffix := range "abcdef" {
+				locations = append(locations, regionName+string(zoneSuffix))
+			}
+		}
+	}
+	sort.Strings(locations)
+	return locations
+}
+
+// isKnownLocation returns true if the location is in the sorted known locations or is a zone id, Local Zone, or Wavelength Zone,
+// which cannot be derived from the region
+func isKnownLocation(knownLocations []string, location string) bool {
+	if strings.Contains(location, zoneIDSeparator) || selector.IsEdgeZone(location) {
+		return true
+	}
+	i := sort.SearchStrings(knownLocations, location)
+	return i < len(knownLocations) && knownLocations[i] == location
+}
+
+// sortByFromFlag returns the sort keys passed to --sort-by as a comma-separated list
+func sortByFromFlag(flags map[string]interface{}) []string {
+	sortByFlag, ok := flags[sortBy].(*string)
+	if !ok || sortByFlag == nil {
+		return nil
+	}
+	return strings.Split(*sortByFlag, ",")
+}
+
+// listInstanceTypesAcrossRegions filters the instance types in each region and prints a labeled section of the matching
+// instance types for each region followed by the instance types which matched in every region.
+// If quiet is true, only the instance types which matched in every region are printed without a header.
+func listInstanceTypesAcrossRegions(instanceSelector *selector.Selector, filters selector.Filters, regions []string, quiet bool) {
+	results, err := instanceSelector.FilterAcrossRegions(filters, regions)
+	if err != nil {
+		fmt.Printf("An error occurred when filtering instance types across regions: %v", err)
+		os.Exit(exitCodeForError(err))
+	}
+	if quiet {
+		for _, instanceType := range results.Intersection {
+			fmt.Println(instanceType)
+		}
+		if len(results.Intersection) == 0 {
+			os.Exit(exitCodeNoMatches)
+		}
+		return
+	}
+	sortedRegions := []string{}
+	for r := range results.Regions {
+		sortedRegions = append(sortedRegions, r)
+	}
+	sort.Strings(sortedRegions)
+	matched := false
+	for _, r := range sortedRegions {
+		fmt.Printf("== %s (%d) ==\n", r, len(results.Regions[r]))
+		for _, instanceType := range results.Regions[r] {
+			fmt.Println(instanceType)
+		}
+		fmt.Println()
+		matched = matched || len(results.Regions[r]) != 0
+	}
+	fmt.Printf("== all regions (%d) ==\n", len(results.Intersection))
+	for _, instanceType := range results.Intersection {
+		fmt.Println(instanceType)
+	}
+	if !matched {
+		log.Println("The criteria was too narrow and returned no valid instance types in any region. Consider broadening your criteria so that more instance types are returned.")
+		os.Exit(exitCodeNoMatches)
+	}
+}
+
+// exitCodeForError returns exitCodeAPIError if the error came from an EC2 API request and exitCodeError otherwise
+func exitCodeForError(err error) int {
+	var awsErr awserr.Error
+	if errors.Is(err, selector.ErrThrottled) || errors.Is(err, selector.ErrUnauthorized) || errors.As(err, &awsErr) {
+		return exitCodeAPIError
+	}
+	return exitCodeError
+}
+
+// watchInstanceTypes prints the instance types matching the filters and then re-evaluates the filters at the interval,
+// printing the instance types which were added, removed, or changed lowest current spot price. It runs until the process is interrupted.
+func watchInstanceTypes(instanceSelector *selector.Selector, filters selector.Filters, interval time.Duration) {
+	var previous []selector.InstanceTypeDetails
+	for ; ; time.Sleep(interval) {
+		current, err := instanceSelector.FilterDetailed(filters)
+		if err != nil {
+			log.Printf("An error occurred when filtering instance types, retrying in %s: %v\n", interval, err)
+			continue
+		}
+		if previous == nil {
+			for _, details := range current {
+				fmt.Println(details.InstanceType)
+			}
+			log.Printf("Watching for changes every %s\n", interval)
+			previous = current
+			continue
+		}
+		change := selector.DiffInstanceTypeDetails(previous, current)
+		timestamp := time.Now().Format(time.RFC3339)
+		for _, instanceType := range change.Added {
+			fmt.Printf("%s + %s\n", timestamp, instanceType)
+		}
+		for _, instanceType := range change.Removed {
+			fmt.Printf("%s - %s\n", timestamp, instanceType)
+		}
+		for _, priceChange := range change.PriceChanges {
+			fmt.Printf("%s ~ %s $%.4f/hr -> $%.4f/hr\n", timestamp, priceChange.InstanceType, priceChange.PreviousPricePerHour, priceChange.PricePerHour)
+		}
+		previous = current
+	}
+}
+
+// printExplanation prints each rejected instance type and the filters which rejected it to stderr
+func printExplanation(explanation *selector.Explanation) {
+	rejectedInstanceTypes := []string{}
+	for instanceType := range explanation.Rejections {
+		rejectedInstanceTypes = append(rejectedInstanceTypes, instanceType)
+	}
+	sort.Strings(rejectedInstanceTypes)
+	fmt.Fprintln(os.Stderr, "Rejected Instance Types:")
+	for _, instanceType := range rejectedInstanceTypes {
+		for _, rejection := range explanation.Rejections[instanceType] {
+			fmt.Fprintf(os.Stderr, "%s: %s (filter: %s, instance type: %s)\n", instanceType, rejection.Filter, rejection.FilterValue, rejection.InstanceTypeValue)
+		}
+	}
+	fmt.Fprintln(os.Stderr)
+}
+
+// currentInstanceTypes returns the instance types of the --from-asg Auto Scaling group or the --from-launch-template launch template.
+// The instance type of the group's launch template is used when the group has no instance type overrides.
+func currentInstanceTypes(cli *commandline.CommandLineInterface, flags map[string]interface{}, sess *session.Session, instanceSelector *selector.Selector) []string {
+	if flags[fromLT] != nil {
+		instanceTypes, err := instanceSelector.InstanceTypesFromLaunchTemplate(*cli.StringMe(flags[fromLT]))
+		if err != nil {
+			fmt.Printf("An error occurred when retrieving the launch template: %v", err)
+			os.Exit(exitCodeForError(err))
+		}
+		return instanceTypes
+	}
+	instanceTypes, launchTemplate, err := publish.AutoScalingGroupInstanceTypes(autoscaling.New(sess), *cli.StringMe(flags[fromASG]))
+	if err != nil {
+		fmt.Printf("An error occurred when retrieving the Auto Scaling group: %v", err)
+		os.Exit(exitCodeForError(err))
+	}
+	if launchTemplate == nil {
+		return instanceTypes
+	}
+	template := aws.StringValue(launchTemplate.LaunchTemplateId)
+	if template == "" {
+		template = aws.StringValue(launchTemplate.LaunchTemplateName)
+	}
+	if launchTemplate.Version != nil {
+		template = fmt.Sprintf("%s:%s", template, *launchTemplate.Version)
+	}
+	instanceTypes, err = instanceSelector.InstanceTypesFromLaunchTemplate(template)
+	if err != nil {
+		fmt.Printf("An error occurred when retrieving the launch template of the Auto Scaling group: %v", err)
+		os.Exit(exitCodeForError(err))
+	}
+	return instanceTypes
+}
+
+// updateAutoScalingGroup prints how the instance type overrides of the Auto Scaling group would change and rewrites them
+// once the change is confirmed on stdin, unless assumeYes is set. Nothing is changed if the change is declined.
+func updateAutoScalingGroup(asgClient *autoscaling.AutoScaling, name string, instanceTypes []string, assumeYes bool) {
+	change, err := publish.PlanAutoScalingGroupUpdate(asgClient, name, instanceTypes)
+	if err != nil {
+		fmt.Printf("An error occurred when planning the Auto Scaling group update: %v", err)
+		os.Exit(exitCodeForError(err))
+	}
+	if !change.HasChanges() {
+		log.Printf("The overrides of Auto Scaling group %s already match the selected instance types\n", name)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Auto Scaling group %s overrides:\n", name)
+	fmt.Fprintf(os.Stderr, "  current: %s\n", strings.Join(change.Current, ", "))
+	fmt.Fprintf(os.Stderr, "  added:   %s\n", valueOrNone(strings.Join(change.Added, ", ")))
+	fmt.Fprintf(os.Stderr, "  removed: %s\n", valueOrNone(strings.Join(change.Removed, ", ")))
+	if !assumeYes {
+		fmt.Fprintf(os.Stderr, "Update Auto Scaling group %s? Running instances are not replaced. [y/N] ", name)
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			log.Printf("Auto Scaling group %s was not updated\n", name)
+			return
+		}
+	}
+	if err := publish.UpdateAutoScalingGroup(asgClient, change); err != nil {
+		fmt.Printf("An error occurred when updating the Auto Scaling group: %v", err)
+		os.Exit(exitCodeForError(err))
+	}
+	log.Printf("Updated the overrides of Auto Scaling group %s with %d instance types\n", name, len(change.Desired))
+}
+
+// createNodegroup creates the EKS managed nodegroup described by the flags with the matching instance types
+func createNodegroup(eksClient *eks.EKS, cli *commandline.CommandLineInterface, flags map[string]interface{}, instanceTypeInfoSlice []*ec2.InstanceTypeInfo) {
+	for _, required := range []string{eksCluster, nodeRole} {
+		if flags[required] == nil {
+			fmt.Printf("--%s requires --%s", createNG, required)
+			os.Exit(exitCodeError)
+		}
+	}
+	nodegroup := publish.Nodegroup{
+		ClusterName:   *cli.StringMe(flags[eksCluster]),
+		NodegroupName: *cli.StringMe(flags[createNG]),
+		CapacityType:  eks.CapacityTypesOnDemand,
+		NodeRole:      *cli.StringMe(flags[nodeRole]),
+	}
+	if flags[capacityType] != nil {
+		nodegroup.CapacityType = *cli.StringMe(flags[capacityType])
+	}
+	if flags[subnets] != nil {
+		for _, subnet := range strings.Split(*cli.StringMe(flags[subnets]), ",") {
+			if subnet = strings.TrimSpace(subnet); subnet != "" {
+				nodegroup.Subnets = append(nodegroup.Subnets, subnet)
+			}
+		}
+	}
+	status, err := publish.EKSNodegroup(eksClient, nodegroup, instanceTypeInfoSlice)
+	if err != nil {
+		fmt.Printf("An error occurred when creating the EKS nodegroup: %v", err)
+		os.Exit(exitCodeForError(err))
+	}
+	log.Printf("Created %s nodegroup %s in EKS cluster %s with %d instance types (status %s)\n", nodegroup.CapacityType, nodegroup.NodegroupName, nodegroup.ClusterName, len(instanceTypeInfoSlice), status)
+}
+
+// printENITrunking prints which of the instance types support ECS ENI trunking to stderr so that the results remain pipeable
+func printENITrunking(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) {
+	fmt.Fprintln(os.Stderr, "ENI Trunking (awsvpc network mode task density):")
+	for _, instanceTypeInfo := range instanceTypeInfoSlice {
+		trunking := "not supported"
+		if selector.SupportsENITrunking(instanceTypeInfo) {
+			trunking = "supported"
+		}
+		fmt.Fprintf(os.Stderr, "%s: %s\n", *instanceTypeInfo.InstanceType, trunking)
+	}
+	fmt.Fprintln(os.Stderr)
+}
+
+// printCacheMetadata prints a table of when each cache file in the cache dir was refreshed and which region and partition it covers
+func printCacheMetadata(dir string, cacheMetadata []selector.CacheMetadata) {
+	if len(cacheMetadata) == 0 {
+		fmt.Printf("No cached EC2 API responses were found in %s\n", dir)
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 8, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "Region\tPartition\tData\tLocation\tInstance Types\tRefreshed\tExpires\t\n")
+	for _, m := range cacheMetadata {
+		refreshed := "unknown"
+		if !m.Refreshed.IsZero() {
+			refreshed = m.Refreshed.Format(time.RFC3339)
+		}
+		expires := m.Expiry.Format(time.RFC3339)
+		if m.Expired {
+			expires += " (expired)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\t%s\t\n", m.Region, valueOrNone(m.Partition), m.Data, valueOrNone(m.Location), m.InstanceTypes, refreshed, expires)
+	}
+	w.Flush()
+}
+
+// valueOrNone returns the value or "none" if it is empty so that table columns stay aligned
+func valueOrNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
+
+// printAPICallPlan prints the input of each EC2 API request in the plan
+func printAPICallPlan(plan *selector.APICallPlan) {
+	if plan.DescribeInstanceTypeOfferings != nil {
+		fmt.Printf("DescribeInstanceTypeOfferings %s\n", plan.DescribeInstanceTypeOfferings)
+	}
+	fmt.Printf("DescribeInstanceTypes %s\n", plan.DescribeInstanceTypes)
+}
+
+// loadCandidates reads candidate instance types from the file passed in or from stdin if the path is -
+func loadCandidates(path string) ([]string, error) {
+	if path != "-" {
+		return selector.LoadCandidatesFile(path)
+	}
+	contents, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read candidates from stdin: %w", err)
+	}
+	return selector.ParseCandidates(contents), nil
+}
+
+// printAuditResults prints whether each candidate instance type passed or failed the filters along with the filters which rejected it.
+// Returns true if every candidate passed.
+func printAuditResults(auditResults *selector.AuditResults) bool {
+	for _, instanceType := range auditResults.Passed {
+		fmt.Printf("PASS %s\n", instanceType)
+	}
+	failedInstanceTypes := []string{}
+	for instanceType := range auditResults.Failed {
+		failedInstanceTypes = append(failedInstanceTypes, instanceType)
+	}
+	sort.Strings(failedInstanceTypes)
+	for _, instanceType := range failedInstanceTypes {
+		for _, rejection := range auditResults.Failed[instanceType] {
+			fmt.Printf("FAIL %s: %s (filter: %s, instance type: %s)\n", instanceType, rejection.Filter, rejection.FilterValue, rejection.InstanceTypeValue)
+		}
+	}
+	for _, instanceType := range auditResults.NotFound {
+		fmt.Printf("FAIL %s: instance type not found\n", instanceType)
+	}
+	return len(auditResults.Failed) == 0 && len(auditResults.NotFound) == 0
+}
+
+// printComparison prints a table of the specs of both compared instance types and marks the specs which differ with a *
+func printComparison(comparison *selector.Comparison) {
+	w := tabwriter.NewWriter(os.Stdout, 8, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "Spec\t%s\t%s\t\n", comparison.InstanceTypeA, comparison.InstanceTypeB)
+	fmt.Fprintf(w, "----\t%s\t%s\t\n", strings.Repeat("-", len(comparison.InstanceTypeA)), strings.Repeat("-", len(comparison.InstanceTypeB)))
+	for _, spec := range comparison.Specs {
+		marker := ""
+		if spec.Different {
+			marker = "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", spec.Spec, spec.A, spec.B, marker)
+	}
+	w.Flush()
+}
+
+// printSpotPlacements prints the recommended availability zones and the spot price in each zone of the instance types
+func printSpotPlacements(placements []selector.SpotPlacement) {
+	w := tabwriter.NewWriter(os.Stdout, 8, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "Instance Type\tRecommended AZs\tSpot Price per Hour (USD)\t\n")
+	fmt.Fprintf(w, "-------------\t---------------\t-------------------------\t\n")
+	for _, placement := range placements {
+		zonePrices := []string{}
+		for _, zonePrice := range placement.ZonePrices {
+			zonePrices = append(zonePrices, fmt.Sprintf("%s=%.4f", zonePrice.AvailabilityZone, zonePrice.PricePerHour))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t\n", placement.InstanceType, valueOrNone(strings.Join(placement.RecommendedZones, ", ")), valueOrNone(strings.Join(zonePrices, " ")))
+	}
+	w.Flush()
+}
+
+// printRegionRecommendations prints a table of the regions from most to least suitable
+func printRegionRecommendations(recommendations []selector.RegionRecommendation) {
+	w := tabwriter.NewWriter(os.Stdout, 8, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "Rank\tRegion\tSpot Placement Score\tInstance Types\tLowest Spot Price per Hour (USD)\t\n")
+	fmt.Fprintf(w, "----\t------\t--------------------\t--------------\t--------------------------------\t\n")
+	for i, recommendation := range recommendations {
+		lowestSpotPrice := "none"
+		if recommendation.LowestSpotPricePerHour != nil {
+			lowestSpotPrice = fmt.Sprintf("%.4f", *recommendation.LowestSpotPricePerHour)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%s\t\n", i+1, recommendation.Region, recommendation.SpotPlacementScore, recommendation.InstanceTypes, lowestSpotPrice)
+	}
+	w.Flush()
+}
+
+// printRightsizingRecommendations prints a table of the recommended instance types of each resource which match the filters
+// along with the first filter which rejected each of the other recommended instance types
+func printRightsizingRecommendations(recommendations []optimizer.Recommendation) {
+	if len(recommendations) == 0 {
+		log.Println("Compute Optimizer did not return any recommendations")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 8, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "Resource\tCurrent\tFinding\tAllowed Recommendations\tRejected Recommendations\t\n")
+	fmt.Fprintf(w, "--------\t-------\t-------\t-----------------------\t------------------------\t\n")
+	for _, recommendation := range recommendations {
+		rejected := []string{}
+		for _, instanceType := range recommendation.RecommendedInstanceTypes {
+			if rejections, ok := recommendation.Rejections[instanceType]; ok && len(rejections) != 0 {
+				rejected = append(rejected, fmt.Sprintf("%s (%s)", instanceType, rejections[0].Filter))
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t\n", recommendation.ResourceARN, recommendation.CurrentInstanceType, recommendation.Finding,
+			valueOrNone(strings.Join(recommendation.AllowedInstanceTypes, ", ")), valueOrNone(strings.Join(rejected, ", ")))
+	}
+	w.Flush()
+}
+
+// zoneOutputFormats maps the output formats which can include the availability zones of each instance type to the output which does
+var zoneOutputFormats = map[string]func(map[string][]string) func([]*ec2.InstanceTypeInfo) []string{
+	selector.OutputFormatVerbose:   outputs.VerboseInstanceTypeWithZonesOutput,
+	selector.OutputFormatJSON:      outputs.VerboseInstanceTypeWithZonesOutput,
+	selector.OutputFormatYAML:      outputs.YAMLInstanceTypeWithZonesOutput,
+	selector.OutputFormatTable:     outputs.TableOutputShortWithZones,
+	selector.OutputFormatTableWide: outputs.TableOutputWideWithZones,
+	selector.OutputFormatWide:      outputs.TableOutputWideWithZones,
+}
+
+// zoneOutputFormatNames returns the sorted names of the output formats which can include availability zones
+func zoneOutputFormatNames() []string {
+	names := []string{}
+	for name := range zoneOutputFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// getOutputFn returns the output registered for the output flag or currentFn if the flag is not set
+func getOutputFn(outputFlag *string, currentFn selector.InstanceTypesOutput) selector.InstanceTypesOutput {
 	if outputFlag != nil {
-		switch *outputFlag {
-		case cfnJSON:
-			return selector.InstanceTypesOutputFn(outputs.CloudFormationSpotMixedInstancesPolicyJSONOutput)
-		case cfnYAML:
-			return selector.InstanceTypesOutputFn(outputs.CloudFormationSpotMixedInstancesPolicyYAMLOutput)
-		case terraformHCL:
-			return selector.InstanceTypesOutputFn(outputs.TerraformSpotMixedInstancesPolicyHCLOutput)
-		case tableWideOutput:
-			return selector.InstanceTypesOutputFn(outputs.TableOutputWide)
-		case tableOutput:
-			return selector.InstanceTypesOutputFn(outputs.TableOutputShort)
-		}
-	}
-	return outputFn
+		if outputFn, err := selector.OutputFormat(*outputFlag); err == nil {
+			return outputFn
+		}
+	}
+	return currentFn
 }
@@ -0,0 +1,38 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func TestServiceNameConflict(t *testing.T) {
+	for _, flag := range ec2NameFlags {
+		flags := map[string]interface{}{flag: aws.String("set")}
+		h.Equals(t, flag, serviceNameConflict(flags, aws.String(selector.ServiceSageMaker)))
+		h.Equals(t, flag, serviceNameConflict(flags, aws.String(selector.ServiceRDS)))
+		h.Equals(t, "", serviceNameConflict(flags, aws.String(selector.ServiceECS)))
+		h.Equals(t, "", serviceNameConflict(flags, aws.String(selector.ServiceEC2)))
+		h.Equals(t, "", serviceNameConflict(flags, nil))
+	}
+	// the Auto Scaling group overrides must be EC2 instance types
+	h.Equals(t, updateASG, serviceNameConflict(map[string]interface{}{updateASG: aws.String("web")}, aws.String(selector.ServiceElastiCache)))
+	h.Equals(t, createNG, serviceNameConflict(map[string]interface{}{createNG: aws.String("web")}, aws.String(selector.ServiceSageMakerTraining)))
+	// the service's names are published intentionally
+	h.Equals(t, "", serviceNameConflict(map[string]interface{}{publishSSM: aws.String("/ec2/instance-types")}, aws.String(selector.ServiceSageMaker)))
+}
@@ -3,9 +3,12 @@ module github.com/aws/amazon-ec2-instance-selector
 go 1.14
 
 require (
-	github.com/aws/aws-sdk-go v1.29.33
+	github.com/aws/aws-sdk-go v1.42.0
 	github.com/ghodss/yaml v1.0.0
 	github.com/hashicorp/hcl v1.0.0
+	github.com/prometheus/client_golang v1.11.0
 	github.com/spf13/cobra v0.0.7
 	github.com/spf13/pflag v1.0.3
+	google.golang.org/grpc v1.40.0
+	google.golang.org/protobuf v1.27.1
 )
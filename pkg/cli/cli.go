@@ -35,12 +35,13 @@ func New(binaryName string, shortUsage string, longUsage, examples string) Comma
 		Run:     func(cmd *cobra.Command, args []string) {},
 	}
 	return CommandLineInterface{
-		rootCmd:       rootCmd,
-		Flags:         map[string]interface{}{},
-		nilDefaults:   map[string]bool{},
-		intRangeFlags: map[string]bool{},
-		validators:    map[string]validator{},
-		suiteFlags:    pflag.NewFlagSet("suite", pflag.ExitOnError),
+		rootCmd:           rootCmd,
+		Flags:             map[string]interface{}{},
+		nilDefaults:       map[string]bool{},
+		intRangeFlags:     map[string]bool{},
+		byteQuantityFlags: map[string]bool{},
+		validators:        map[string]validator{},
+		suiteFlags:        pflag.NewFlagSet("suite", pflag.ExitOnError),
 	}
 }
 
@@ -48,7 +49,12 @@ func New(binaryName string, shortUsage string, longUsage, examples string) Comma
 func (cl *CommandLineInterface) ParseFlags() (map[string]interface{}, error) {
 	cl.setUsageTemplate()
 	// Remove Suite Flags so that args only include Config and Filter Flags
-	cl.rootCmd.SetArgs(removeIntersectingArgs(cl.suiteFlags))
+	// The binary name is also removed so that only positional args remain after parsing
+	rootArgs := removeIntersectingArgs(cl.suiteFlags)
+	if len(rootArgs) > 0 {
+		rootArgs = rootArgs[1:]
+	}
+	cl.rootCmd.SetArgs(rootArgs)
 	// This parses Config and Filter flags only
 	err := cl.rootCmd.Execute()
 	if err != nil {
@@ -62,10 +68,22 @@ func (cl *CommandLineInterface) ParseFlags() (map[string]interface{}, error) {
 	// Add suite flags to rootCmd flagset so that other processing can occur
 	// This has to be done after usage is printed so that the flagsets can be grouped properly when printed
 	cl.rootCmd.Flags().AddFlagSet(cl.suiteFlags)
+	err = cl.applyEnvVars()
+	if err != nil {
+		return nil, err
+	}
+	err = cl.applyConfigFile()
+	if err != nil {
+		return nil, err
+	}
 	err = cl.setUntouchedFlagValuesToNil()
 	if err != nil {
 		return nil, err
 	}
+	err = cl.processByteQuantityFlags()
+	if err != nil {
+		return nil, err
+	}
 	err = cl.processRangeFilterFlags()
 	if err != nil {
 		return nil, err
@@ -73,6 +91,11 @@ func (cl *CommandLineInterface) ParseFlags() (map[string]interface{}, error) {
 	return cl.Flags, nil
 }
 
+// Args returns the positional args which are not flags, like a subcommand name and its arguments, after flags are parsed
+func (cl *CommandLineInterface) Args() []string {
+	return cl.rootCmd.Flags().Args()
+}
+
 // ParseAndValidateFlags will parse flags registered in this instance of CLI from os.Args
 // and then perform validation
 func (cl *CommandLineInterface) ParseAndValidateFlags() (map[string]interface{}, error) {
@@ -170,6 +193,10 @@ func (cl *CommandLineInterface) setUntouchedFlagValuesToNil() error {
 				if reflect.ValueOf(*v).IsZero() {
 					cl.Flags[f.Name] = nil
 				}
+			case *[]string:
+				if len(*v) == 0 {
+					cl.Flags[f.Name] = nil
+				}
 			default:
 				defaultHandlerFlags = append(defaultHandlerFlags, f.Name)
 				cl.Flags[f.Name] = nil
@@ -182,6 +209,23 @@ func (cl *CommandLineInterface) setUntouchedFlagValuesToNil() error {
 	return nil
 }
 
+// processByteQuantityFlags converts the byte quantity values of the base flag, min, and max into MiB so they can be processed as int ranges
+func (cl *CommandLineInterface) processByteQuantityFlags() error {
+	for flagName := range cl.byteQuantityFlags {
+		for _, name := range []string{flagName, flagName + "-min", flagName + "-max"} {
+			if cl.Flags[name] == nil {
+				continue
+			}
+			mib, err := selector.ParseByteQuantity(*cl.StringMe(cl.Flags[name]))
+			if err != nil {
+				return fmt.Errorf("Invalid input for --%s: %w", name, err)
+			}
+			cl.Flags[name] = cl.IntMe(mib)
+		}
+	}
+	return nil
+}
+
 // processRangeFilterFlags sets min and max to the appropriate 0 or maxInt bounds based on the 3-tuple that a user specifies for base flag, min, and/or max
 func (cl *CommandLineInterface) processRangeFilterFlags() error {
 	for flagName := range cl.intRangeFlags {
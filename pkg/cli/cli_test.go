@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github.com/aws/amazon-ec2-instance-selector/pkg/cli"
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
 	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
 )
 
@@ -72,6 +73,18 @@ func TestParseFlags(t *testing.T) {
 	h.Assert(t, *flagOutput == "test", "Flag %s should have been parsed", flagArg)
 }
 
+func TestParseFlags_Args(t *testing.T) {
+	cli := getTestCLI()
+	flagName := "test-flag"
+	flagArg := fmt.Sprintf("--%s", flagName)
+	cli.StringFlag(flagName, nil, nil, "Test String w/o validation", nil)
+	os.Args = []string{"ec2-instance-selector", "compare", flagArg, "test", "m5.xlarge", "m6i.xlarge"}
+	flags, err := cli.ParseFlags()
+	h.Ok(t, err)
+	h.Equals(t, "test", *flags[flagName].(*string))
+	h.Equals(t, []string{"compare", "m5.xlarge", "m6i.xlarge"}, cli.Args())
+}
+
 func TestParseFlags_IntRange(t *testing.T) {
 	flagName := "test-flag"
 	flagMinArg := fmt.Sprintf("%s-%s", flagName, "min")
@@ -119,6 +132,57 @@ func TestParseFlags_IntRange(t *testing.T) {
 	h.Assert(t, *flagMinOutput == 10 && *flagMaxOutput == 500, "Flag %s max should have been parsed from cmdline and min set to 0", flagArg)
 }
 
+func TestParseFlags_StringSlice(t *testing.T) {
+	cli := getTestCLI()
+	flagName := "test-flag"
+	flagArg := fmt.Sprintf("--%s", flagName)
+	cli.StringSliceFlag(flagName, nil, nil, "Test String Slice", nil)
+	os.Args = []string{"ec2-instance-selector", flagArg, "x86_64,arm64"}
+	flags, err := cli.ParseFlags()
+	h.Ok(t, err)
+	flagOutput := flags[flagName].(*[]string)
+	h.Equals(t, []string{"x86_64", "arm64"}, *flagOutput)
+
+	cli = getTestCLI()
+	cli.StringSliceFlag(flagName, nil, nil, "Test String Slice", nil)
+	os.Args = []string{"ec2-instance-selector"}
+	flags, err = cli.ParseFlags()
+	h.Ok(t, err)
+	h.Assert(t, flags[flagName] == nil, "Flag %s should be set to nil when not explicitly set", flagArg)
+}
+
+func TestParseFlags_ByteQuantityRange(t *testing.T) {
+	flagName := "test-flag"
+	flagMinArg := fmt.Sprintf("%s-%s", flagName, "min")
+	flagMaxArg := fmt.Sprintf("%s-%s", flagName, "max")
+
+	cli := getTestCLI()
+	cli.ByteQuantityMinMaxRangeFlags(flagName, nil, nil, "Test")
+	os.Args = []string{"ec2-instance-selector", "--" + flagName, "8 GiB"}
+	flags, err := cli.ParseFlags()
+	h.Ok(t, err)
+	h.Equals(t, selector.IntRangeFilter{LowerBound: 8192, UpperBound: 8192}, *flags[flagName].(*selector.IntRangeFilter))
+
+	cli = getTestCLI()
+	cli.ByteQuantityMinMaxRangeFlags(flagName, nil, nil, "Test")
+	os.Args = []string{"ec2-instance-selector", "--" + flagMinArg, "4096", "--" + flagMaxArg, "16gb"}
+	flags, err = cli.ParseFlags()
+	h.Ok(t, err)
+	h.Equals(t, selector.IntRangeFilter{LowerBound: 4096, UpperBound: 16384}, *flags[flagName].(*selector.IntRangeFilter))
+
+	cli = getTestCLI()
+	cli.ByteQuantityMinMaxRangeFlags(flagName, nil, nil, "Test")
+	os.Args = []string{"ec2-instance-selector", "--" + flagMinArg, "8 gigs"}
+	_, err = cli.ParseFlags()
+	h.Nok(t, err)
+
+	cli = getTestCLI()
+	cli.ByteQuantityMinMaxRangeFlags(flagName, nil, nil, "Test")
+	os.Args = []string{"ec2-instance-selector", "--" + flagMinArg, "16gb", "--" + flagMaxArg, "8gb"}
+	_, err = cli.ParseAndValidateFlags()
+	h.Nok(t, err)
+}
+
 func TestParseFlags_IntRangeErr(t *testing.T) {
 	cli := getTestCLI()
 	flagName := "test-flag"
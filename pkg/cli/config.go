@@ -0,0 +1,146 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/pflag"
+)
+
+// Config holds flag values loaded from a config file. Keys are flag names without the leading dashes.
+type Config struct {
+	// Defaults are applied to every invocation
+	Defaults map[string]interface{} `json:"defaults"`
+	// Profiles are named sets of flag values which are applied on top of the defaults when selected
+	Profiles map[string]map[string]interface{} `json:"profiles"`
+}
+
+// LoadConfigFile reads a YAML or JSON config file of default flag values and named profiles
+func LoadConfigFile(path string) (*Config, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read the config file %s: %w", path, err)
+	}
+	config := Config{}
+	if err := yaml.Unmarshal(contents, &config); err != nil {
+		return nil, fmt.Errorf("Unable to parse the config file %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// ProfileNames returns the sorted names of the profiles in the config
+func (c Config) ProfileNames() []string {
+	names := []string{}
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// UseConfigFile applies the defaults in the config file at path, and the profile named by the profileNameFlag flag,
+// to any flags which are not passed on the command line when the flags are parsed.
+// A missing config file is ignored unless a profile is selected.
+func (cl *CommandLineInterface) UseConfigFile(path string, profileNameFlag string) {
+	cl.configFilePath = path
+	cl.profileNameFlag = profileNameFlag
+}
+
+// applyConfigFile sets flags which were not passed on the command line to the values of the selected profile
+// and then the defaults in the config file
+func (cl *CommandLineInterface) applyConfigFile() error {
+	if cl.configFilePath == "" {
+		return nil
+	}
+	var profileName string
+	if cl.profileNameFlag != "" {
+		if f := cl.rootCmd.Flags().Lookup(cl.profileNameFlag); f != nil && f.Changed {
+			profileName = f.Value.String()
+		}
+	}
+	if _, err := os.Stat(cl.configFilePath); os.IsNotExist(err) && profileName == "" {
+		return nil
+	}
+	config, err := LoadConfigFile(cl.configFilePath)
+	if err != nil {
+		return err
+	}
+	if profileName != "" {
+		profile, ok := config.Profiles[profileName]
+		if !ok {
+			return fmt.Errorf("The profile %s does not exist in the config file %s. Valid profiles are: %s", profileName, cl.configFilePath, strings.Join(config.ProfileNames(), ", "))
+		}
+		if err := cl.setUnchangedFlags(profile); err != nil {
+			return fmt.Errorf("Invalid profile %s in the config file %s: %w", profileName, cl.configFilePath, err)
+		}
+	}
+	if err := cl.setUnchangedFlags(config.Defaults); err != nil {
+		return fmt.Errorf("Invalid defaults in the config file %s: %w", cl.configFilePath, err)
+	}
+	return nil
+}
+
+// setUnchangedFlags sets each flag which has not been set yet to its value in flagValues
+func (cl *CommandLineInterface) setUnchangedFlags(flagValues map[string]interface{}) error {
+	flagNames := []string{}
+	for flagName := range flagValues {
+		flagNames = append(flagNames, flagName)
+	}
+	sort.Strings(flagNames)
+	for _, flagName := range flagNames {
+		f := cl.rootCmd.Flags().Lookup(flagName)
+		if f == nil || flagName == cl.profileNameFlag {
+			return fmt.Errorf("%s is not a valid flag", flagName)
+		}
+		if f.Changed {
+			continue
+		}
+		if err := setFlagFromConfig(cl.rootCmd.Flags(), flagName, flagValues[flagName]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setFlagFromConfig sets the flag to the string form of a value parsed from the config file
+func setFlagFromConfig(flagSet *pflag.FlagSet, flagName string, value interface{}) error {
+	var valueStr string
+	switch v := value.(type) {
+	case string:
+		valueStr = v
+	case float64:
+		valueStr = strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		valueStr = strconv.FormatBool(v)
+	case []interface{}:
+		values := []string{}
+		for _, item := range v {
+			values = append(values, fmt.Sprintf("%v", item))
+		}
+		valueStr = strings.Join(values, ",")
+	default:
+		return fmt.Errorf("Unsupported value %v for %s", value, flagName)
+	}
+	if err := flagSet.Set(flagName, valueStr); err != nil {
+		return fmt.Errorf("Invalid value %s for %s: %w", valueStr, flagName, err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cli_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/cli"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+)
+
+const testConfig = `
+defaults:
+  region: us-east-1
+  max-results: 10
+profiles:
+  ml-training:
+    gpus-min: 1
+    cpu-architecture:
+      - x86_64
+  web-tier:
+    vcpus: 2
+    region: us-west-2
+`
+
+// Helpers
+
+func writeTestConfig(t *testing.T, contents string) string {
+	dir, err := ioutil.TempDir("", "ec2-instance-selector")
+	h.Ok(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "config.yaml")
+	h.Ok(t, ioutil.WriteFile(path, []byte(contents), 0600))
+	return path
+}
+
+func getTestConfigCLI(configPath string) cli.CommandLineInterface {
+	testCLI := getTestCLI()
+	testCLI.IntMinMaxRangeFlags("vcpus", nil, nil, "Test")
+	testCLI.IntMinMaxRangeFlags("gpus", nil, nil, "Test")
+	testCLI.StringSliceFlag("cpu-architecture", nil, nil, "Test", nil)
+	testCLI.ConfigStringFlag("region", nil, nil, "Test", nil)
+	testCLI.ConfigIntFlag("max-results", nil, nil, "Test")
+	testCLI.ConfigStringFlag("profile-name", nil, nil, "Test", nil)
+	testCLI.UseConfigFile(configPath, "profile-name")
+	return testCLI
+}
+
+// Tests
+
+func TestLoadConfigFile(t *testing.T) {
+	config, err := cli.LoadConfigFile(writeTestConfig(t, testConfig))
+	h.Ok(t, err)
+	h.Equals(t, "us-east-1", config.Defaults["region"])
+	h.Equals(t, []string{"ml-training", "web-tier"}, config.ProfileNames())
+}
+
+func TestLoadConfigFile_Invalid(t *testing.T) {
+	_, err := cli.LoadConfigFile(writeTestConfig(t, "defaults: [not a map"))
+	h.Nok(t, err)
+	_, err = cli.LoadConfigFile("does-not-exist.yaml")
+	h.Nok(t, err)
+}
+
+func TestParseFlags_ConfigDefaults(t *testing.T) {
+	testCLI := getTestConfigCLI(writeTestConfig(t, testConfig))
+	os.Args = []string{"ec2-instance-selector", "--vcpus", "4"}
+	flags, err := testCLI.ParseFlags()
+	h.Ok(t, err)
+	h.Equals(t, "us-east-1", *flags["region"].(*string))
+	h.Equals(t, 10, *flags["max-results"].(*int))
+	h.Assert(t, flags["gpus"] == nil, "Profile values should not be applied when no profile is selected")
+}
+
+func TestParseFlags_ConfigProfile(t *testing.T) {
+	testCLI := getTestConfigCLI(writeTestConfig(t, testConfig))
+	os.Args = []string{"ec2-instance-selector", "--profile-name", "ml-training", "--max-results", "5"}
+	flags, err := testCLI.ParseFlags()
+	h.Ok(t, err)
+	h.Equals(t, 1, testCLI.IntRangeMe(flags["gpus"]).LowerBound)
+	h.Equals(t, []string{"x86_64"}, *flags["cpu-architecture"].(*[]string))
+	h.Equals(t, "us-east-1", *flags["region"].(*string))
+	h.Equals(t, 5, *flags["max-results"].(*int))
+}
+
+func TestParseFlags_ConfigProfileOverridesDefaults(t *testing.T) {
+	testCLI := getTestConfigCLI(writeTestConfig(t, testConfig))
+	os.Args = []string{"ec2-instance-selector", "--profile-name", "web-tier"}
+	flags, err := testCLI.ParseFlags()
+	h.Ok(t, err)
+	h.Equals(t, "us-west-2", *flags["region"].(*string))
+	h.Equals(t, 2, testCLI.IntRangeMe(flags["vcpus"]).UpperBound)
+}
+
+func TestParseFlags_ConfigUnknownProfile(t *testing.T) {
+	testCLI := getTestConfigCLI(writeTestConfig(t, testConfig))
+	os.Args = []string{"ec2-instance-selector", "--profile-name", "does-not-exist"}
+	_, err := testCLI.ParseFlags()
+	h.Nok(t, err)
+}
+
+func TestParseFlags_ConfigUnknownFlag(t *testing.T) {
+	testCLI := getTestConfigCLI(writeTestConfig(t, "defaults:\n  not-a-flag: true\n"))
+	os.Args = []string{"ec2-instance-selector"}
+	_, err := testCLI.ParseFlags()
+	h.Nok(t, err)
+}
+
+func TestParseFlags_ConfigMissingFile(t *testing.T) {
+	testCLI := getTestConfigCLI(filepath.Join(os.TempDir(), "does-not-exist", "config.yaml"))
+	os.Args = []string{"ec2-instance-selector", "--vcpus", "4"}
+	flags, err := testCLI.ParseFlags()
+	h.Ok(t, err)
+	h.Assert(t, flags["region"] == nil, "Flags should not be set when the config file does not exist")
+
+	testCLI = getTestConfigCLI(filepath.Join(os.TempDir(), "does-not-exist", "config.yaml"))
+	os.Args = []string{"ec2-instance-selector", "--profile-name", "web-tier"}
+	_, err = testCLI.ParseFlags()
+	h.Nok(t, err)
+}
@@ -0,0 +1,57 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// UseEnvVars sets any flags which are not passed on the command line from environment variables when the flags are parsed.
+// The environment variable for a flag is the prefix followed by the flag name in upper case with dashes replaced by underscores,
+// i.e. the prefix EC2_INSTANCE_SELECTOR_ sets --vcpus-min from EC2_INSTANCE_SELECTOR_VCPUS_MIN.
+// Environment variables take precedence over the config file.
+func (cl *CommandLineInterface) UseEnvVars(prefix string) {
+	cl.envVarPrefix = prefix
+}
+
+// EnvVarName returns the environment variable which sets the flag when UseEnvVars is used
+func (cl *CommandLineInterface) EnvVarName(flagName string) string {
+	return cl.envVarPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// applyEnvVars sets flags which were not passed on the command line from their environment variables
+func (cl *CommandLineInterface) applyEnvVars() error {
+	if cl.envVarPrefix == "" {
+		return nil
+	}
+	var err error
+	cl.rootCmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if err != nil || f.Changed {
+			return
+		}
+		envVarName := cl.EnvVarName(f.Name)
+		value, ok := os.LookupEnv(envVarName)
+		if !ok {
+			return
+		}
+		if setErr := cl.rootCmd.Flags().Set(f.Name, value); setErr != nil {
+			err = fmt.Errorf("Invalid value %s for %s: %w", value, envVarName, setErr)
+		}
+	})
+	return err
+}
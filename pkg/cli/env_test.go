@@ -0,0 +1,75 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cli_test
+
+import (
+	"os"
+	"testing"
+
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+)
+
+func TestEnvVarName(t *testing.T) {
+	testCLI := getTestCLI()
+	testCLI.UseEnvVars("EC2_INSTANCE_SELECTOR_")
+	h.Equals(t, "EC2_INSTANCE_SELECTOR_VCPUS_MIN", testCLI.EnvVarName("vcpus-min"))
+}
+
+func TestParseFlags_EnvVars(t *testing.T) {
+	testCLI := getTestCLI()
+	testCLI.IntMinMaxRangeFlags("vcpus", nil, nil, "Test")
+	testCLI.StringSliceFlag("cpu-architecture", nil, nil, "Test", nil)
+	testCLI.ConfigStringFlag("region", nil, nil, "Test", nil)
+	testCLI.ConfigBoolFlag("verbose", nil, nil, "Test")
+	testCLI.UseEnvVars("TEST_SELECTOR_")
+	os.Setenv("TEST_SELECTOR_VCPUS_MIN", "4")
+	os.Setenv("TEST_SELECTOR_CPU_ARCHITECTURE", "x86_64,arm64")
+	os.Setenv("TEST_SELECTOR_REGION", "us-east-1")
+	os.Setenv("TEST_SELECTOR_VERBOSE", "true")
+	defer func() {
+		for _, envVar := range []string{"TEST_SELECTOR_VCPUS_MIN", "TEST_SELECTOR_CPU_ARCHITECTURE", "TEST_SELECTOR_REGION", "TEST_SELECTOR_VERBOSE"} {
+			os.Unsetenv(envVar)
+		}
+	}()
+	os.Args = []string{"ec2-instance-selector", "--region", "us-west-2"}
+	flags, err := testCLI.ParseFlags()
+	h.Ok(t, err)
+	h.Equals(t, 4, testCLI.IntRangeMe(flags["vcpus"]).LowerBound)
+	h.Equals(t, []string{"x86_64", "arm64"}, *flags["cpu-architecture"].(*[]string))
+	h.Equals(t, "us-west-2", *flags["region"].(*string))
+	h.Equals(t, true, *flags["verbose"].(*bool))
+}
+
+func TestParseFlags_EnvVarsOverrideConfig(t *testing.T) {
+	testCLI := getTestConfigCLI(writeTestConfig(t, testConfig))
+	testCLI.UseEnvVars("TEST_SELECTOR_")
+	os.Setenv("TEST_SELECTOR_REGION", "eu-west-1")
+	defer os.Unsetenv("TEST_SELECTOR_REGION")
+	os.Args = []string{"ec2-instance-selector"}
+	flags, err := testCLI.ParseFlags()
+	h.Ok(t, err)
+	h.Equals(t, "eu-west-1", *flags["region"].(*string))
+	h.Equals(t, 10, *flags["max-results"].(*int))
+}
+
+func TestParseFlags_EnvVarsInvalid(t *testing.T) {
+	testCLI := getTestCLI()
+	testCLI.IntFlag("gpus", nil, nil, "Test")
+	testCLI.UseEnvVars("TEST_SELECTOR_")
+	os.Setenv("TEST_SELECTOR_GPUS", "many")
+	defer os.Unsetenv("TEST_SELECTOR_GPUS")
+	os.Args = []string{"ec2-instance-selector"}
+	_, err := testCLI.ParseFlags()
+	h.Nok(t, err)
+}
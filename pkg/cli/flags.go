@@ -50,6 +50,12 @@ func (cl *CommandLineInterface) IntMinMaxRangeFlags(name string, shorthand *stri
 	cl.IntMinMaxRangeFlagOnFlagSet(cl.rootCmd.Flags(), name, shorthand, defaultValue, description)
 }
 
+// ByteQuantityMinMaxRangeFlags creates and registers a min, max, and helper flag each accepting a quantity of memory
+// with an optional unit (i.e. 4096, 4096MiB, or 4 GiB). The values are converted to MiB when parsed.
+func (cl *CommandLineInterface) ByteQuantityMinMaxRangeFlags(name string, shorthand *string, defaultValue *string, description string) {
+	cl.ByteQuantityMinMaxRangeFlagOnFlagSet(cl.rootCmd.Flags(), name, shorthand, defaultValue, description)
+}
+
 // IntFlag creates and registers a flag accepting an Integer
 func (cl *CommandLineInterface) IntFlag(name string, shorthand *string, defaultValue *int, description string) {
 	cl.IntFlagOnFlagSet(cl.rootCmd.Flags(), name, shorthand, defaultValue, description)
@@ -61,6 +67,12 @@ func (cl *CommandLineInterface) StringFlag(name string, shorthand *string, defau
 	cl.StringFlagOnFlagSet(cl.rootCmd.Flags(), name, shorthand, defaultValue, description, validationFn)
 }
 
+// StringSliceFlag creates and registers a flag accepting a comma-separated list of Strings and a validator function.
+// The validator function is provided so that more complex flags can be created from a string list input.
+func (cl *CommandLineInterface) StringSliceFlag(name string, shorthand *string, defaultValue []string, description string, validationFn validator) {
+	cl.StringSliceFlagOnFlagSet(cl.rootCmd.Flags(), name, shorthand, defaultValue, description, validationFn)
+}
+
 // BoolFlag creates and registers a flag accepting a boolean
 func (cl *CommandLineInterface) BoolFlag(name string, shorthand *string, defaultValue *bool, description string) {
 	cl.BoolFlagOnFlagSet(cl.rootCmd.Flags(), name, shorthand, defaultValue, description)
@@ -72,6 +84,12 @@ func (cl *CommandLineInterface) ConfigStringFlag(name string, shorthand *string,
 	cl.StringFlagOnFlagSet(cl.rootCmd.PersistentFlags(), name, shorthand, defaultValue, description, validationFn)
 }
 
+// ConfigStringSliceFlag creates and registers a flag accepting a comma-separated list of Strings for configuration purposes.
+// Config flags will be grouped at the bottom in the output of --help
+func (cl *CommandLineInterface) ConfigStringSliceFlag(name string, shorthand *string, defaultValue []string, description string, validationFn validator) {
+	cl.StringSliceFlagOnFlagSet(cl.rootCmd.PersistentFlags(), name, shorthand, defaultValue, description, validationFn)
+}
+
 // ConfigIntFlag creates and registers a flag accepting an Integer for configuration purposes.
 // Config flags will be grouped at the bottom in the output of --help
 func (cl *CommandLineInterface) ConfigIntFlag(name string, shorthand *string, defaultValue *int, description string) {
@@ -108,7 +126,24 @@ func (cl *CommandLineInterface) IntMinMaxRangeFlagOnFlagSet(flagSet *pflag.FlagS
 	cl.IntFlagOnFlagSet(flagSet, name, shorthand, defaultValue, fmt.Sprintf("%s (sets --%s-min and -max to the same value)", description, name))
 	cl.IntFlagOnFlagSet(flagSet, name+"-min", nil, nil, fmt.Sprintf("Minimum %s If --%s-max is not specified, the upper bound will be infinity", description, name))
 	cl.IntFlagOnFlagSet(flagSet, name+"-max", nil, nil, fmt.Sprintf("Maximum %s If --%s-min is not specified, the lower bound will be 0", description, name))
-	cl.validators[name] = func(val interface{}) error {
+	cl.validators[name] = cl.minMaxRangeValidator(name)
+	cl.intRangeFlags[name] = true
+}
+
+// ByteQuantityMinMaxRangeFlagOnFlagSet creates and registers a min, max, and helper flag each accepting a quantity of memory
+// with an optional unit (i.e. 4096, 4096MiB, or 4 GiB). The values are converted to MiB when parsed.
+func (cl *CommandLineInterface) ByteQuantityMinMaxRangeFlagOnFlagSet(flagSet *pflag.FlagSet, name string, shorthand *string, defaultValue *string, description string) {
+	cl.StringFlagOnFlagSet(flagSet, name, shorthand, defaultValue, fmt.Sprintf("%s (sets --%s-min and -max to the same value)", description, name), nil)
+	cl.StringFlagOnFlagSet(flagSet, name+"-min", nil, nil, fmt.Sprintf("Minimum %s If --%s-max is not specified, the upper bound will be infinity", description, name), nil)
+	cl.StringFlagOnFlagSet(flagSet, name+"-max", nil, nil, fmt.Sprintf("Maximum %s If --%s-min is not specified, the lower bound will be 0", description, name), nil)
+	cl.validators[name] = cl.minMaxRangeValidator(name)
+	cl.intRangeFlags[name] = true
+	cl.byteQuantityFlags[name] = true
+}
+
+// minMaxRangeValidator returns a validator which ensures the min flag of a range is less than or equal to the max flag
+func (cl *CommandLineInterface) minMaxRangeValidator(name string) validator {
+	return func(val interface{}) error {
 		if cl.Flags[name+"-min"] == nil || cl.Flags[name+"-max"] == nil {
 			return nil
 		}
@@ -121,7 +156,6 @@ func (cl *CommandLineInterface) IntMinMaxRangeFlagOnFlagSet(flagSet *pflag.FlagS
 		}
 		return nil
 	}
-	cl.intRangeFlags[name] = true
 }
 
 // IntFlagOnFlagSet creates and registers a flag accepting an Integer
@@ -152,3 +186,19 @@ func (cl *CommandLineInterface) StringFlagOnFlagSet(flagSet *pflag.FlagSet, name
 	cl.Flags[name] = flagSet.String(name, *defaultValue, description)
 	cl.validators[name] = validationFn
 }
+
+// StringSliceFlagOnFlagSet creates and registers a flag accepting a comma-separated list of Strings and a validator function.
+// The validator function is provided so that more complex flags can be created from a string list input.
+func (cl *CommandLineInterface) StringSliceFlagOnFlagSet(flagSet *pflag.FlagSet, name string, shorthand *string, defaultValue []string, description string, validationFn validator) {
+	if defaultValue == nil {
+		cl.nilDefaults[name] = true
+		defaultValue = []string{}
+	}
+	if shorthand != nil {
+		cl.Flags[name] = flagSet.StringSliceP(name, string(*shorthand), defaultValue, description)
+		cl.validators[name] = validationFn
+		return
+	}
+	cl.Flags[name] = flagSet.StringSlice(name, defaultValue, description)
+	cl.validators[name] = validationFn
+}
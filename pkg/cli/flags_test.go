@@ -77,6 +77,34 @@ func TestStringFlag(t *testing.T) {
 	}
 }
 
+func TestByteQuantityMinMaxRangeFlags(t *testing.T) {
+	cli := getTestCLI()
+	flagName := "test-byte-quantity-min-max-range"
+	cli.ByteQuantityMinMaxRangeFlags(flagName, cli.StringMe("t"), nil, "Test Min Max Range")
+	_, ok := cli.Flags[flagName]
+	_, minOk := cli.Flags[flagName+"-min"]
+	_, maxOk := cli.Flags[flagName+"-max"]
+	h.Assert(t, len(cli.Flags) == 3, "Should contain 3 flags")
+	h.Assert(t, ok, "Should contain %s flag", flagName)
+	h.Assert(t, minOk, "Should contain %s flag", flagName)
+	h.Assert(t, maxOk, "Should contain %s flag", flagName)
+}
+
+func TestStringSliceFlag(t *testing.T) {
+	cli := getTestCLI()
+	flagName := "test-string-slice"
+	cli.StringSliceFlag(flagName, cli.StringMe("t"), nil, "Test String Slice", nil)
+	_, ok := cli.Flags[flagName]
+	h.Assert(t, len(cli.Flags) == 1, "Should contain 1 flag")
+	h.Assert(t, ok, "Should contain %s flag", flagName)
+
+	cli = getTestCLI()
+	cli.StringSliceFlag(flagName, nil, nil, "Test String Slice", nil)
+	_, ok = cli.Flags[flagName]
+	h.Assert(t, len(cli.Flags) == 1, "Should contain 1 flag w/ no shorthand")
+	h.Assert(t, ok, "Should contain %s flag w/ no shorthand", flagName)
+}
+
 func TestRatioFlag(t *testing.T) {
 	cli := getTestCLI()
 	flagName := "test-ratio"
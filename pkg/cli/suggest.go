@@ -0,0 +1,116 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxSuggestions is the maximum number of closest matches suggested by DidYouMean
+const maxSuggestions = 3
+
+// ClosestMatches returns the candidates with the smallest edit distance to the value, as long as the distance is small
+// enough that the value is likely a typo of them. The candidates are returned in the order they were passed in.
+func ClosestMatches(value string, candidates []string) []string {
+	maxDistance := len(value)/3 + 1
+	matches := []string{}
+	for _, candidate := range candidates {
+		distance := editDistance(strings.ToLower(value), strings.ToLower(candidate))
+		if distance > maxDistance {
+			continue
+		}
+		if distance < maxDistance {
+			maxDistance = distance
+			matches = []string{}
+		}
+		matches = append(matches, candidate)
+	}
+	return matches
+}
+
+// DidYouMean returns a suggestion of up to 3 of the closest candidates to the value, like " (did you mean arm64?)",
+// or an empty string if none of the candidates are close to the value
+func DidYouMean(value string, candidates []string) string {
+	matches := ClosestMatches(value, candidates)
+	if len(matches) == 0 {
+		return ""
+	}
+	if len(matches) > maxSuggestions {
+		matches = matches[:maxSuggestions]
+	}
+	return fmt.Sprintf(" (did you mean %s?)", strings.Join(matches, " or "))
+}
+
+// OneOfValidator returns a validator for string and string slice flags which only accepts the valid values.
+// An invalid value is reported with the closest valid values so that typos are easy to fix.
+func OneOfValidator(flagName string, validValues []string) func(val interface{}) error {
+	return func(val interface{}) error {
+		values := []string{}
+		switch v := val.(type) {
+		case *string:
+			values = append(values, *v)
+		case *[]string:
+			values = append(values, *v...)
+		}
+		for _, value := range values {
+			if containsString(validValues, value) {
+				continue
+			}
+			return fmt.Errorf("Invalid input for --%s: %s is not one of [%s]%s", flagName, value, strings.Join(validValues, ", "), DidYouMean(value, validValues))
+		}
+		return nil
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// editDistance returns the Levenshtein distance between a and b, which is the number of single character
+// insertions, deletions, or substitutions needed to turn a into b
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			substitutionCost := 1
+			if a[i-1] == b[j-1] {
+				substitutionCost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+substitutionCost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func minInt(values ...int) int {
+	min := values[0]
+	for _, v := range values[1:] {
+		if v < min {
+			min = v
+		}
+	}
+	return min
+}
@@ -0,0 +1,50 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cli_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/cli"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+)
+
+var architectures = []string{"x86_64", "i386", "arm64"}
+
+func TestClosestMatches(t *testing.T) {
+	h.Equals(t, []string{"arm64"}, cli.ClosestMatches("arm46", architectures))
+	h.Equals(t, []string{"arm64"}, cli.ClosestMatches("ARM64", architectures))
+	h.Equals(t, []string{"x86_64"}, cli.ClosestMatches("x86-64", architectures))
+	h.Equals(t, []string{"spot"}, cli.ClosestMatches("sopt", []string{"spot", "on-demand"}))
+	h.Equals(t, []string{}, cli.ClosestMatches("power9", architectures))
+}
+
+func TestDidYouMean(t *testing.T) {
+	h.Equals(t, " (did you mean us-east-1 or us-east-2?)", cli.DidYouMean("us-east-3", []string{"us-east-1", "us-east-2", "eu-west-1"}))
+	h.Equals(t, " (did you mean a or b or c?)", cli.DidYouMean("z", []string{"a", "b", "c", "d"}))
+	h.Equals(t, "", cli.DidYouMean("power9", architectures))
+}
+
+func TestOneOfValidator(t *testing.T) {
+	validator := cli.OneOfValidator("cpu-architecture", architectures)
+	h.Ok(t, validator(nil))
+	h.Ok(t, validator(&[]string{"x86_64", "arm64"}))
+	architecture := "i386"
+	h.Ok(t, validator(&architecture))
+
+	err := validator(&[]string{"x86_64", "amr64"})
+	h.Nok(t, err)
+	h.Assert(t, strings.Contains(err.Error(), "did you mean arm64?"), "Error should suggest arm64: %v", err)
+}
@@ -55,8 +55,16 @@ type CommandLineInterface struct {
 	Flags         map[string]interface{}
 	nilDefaults   map[string]bool
 	intRangeFlags map[string]bool
-	validators    map[string]validator
-	suiteFlags    *pflag.FlagSet
+	// byteQuantityFlags are range flags whose values are converted from byte quantities to MiB before range processing
+	byteQuantityFlags map[string]bool
+	validators        map[string]validator
+	suiteFlags        *pflag.FlagSet
+	// configFilePath is the config file of default flag values and profiles set with UseConfigFile
+	configFilePath string
+	// profileNameFlag is the name of the flag which selects a profile in the config file
+	profileNameFlag string
+	// envVarPrefix is the prefix of the environment variables which set flags set with UseEnvVars
+	envVarPrefix string
 }
 
 // Float64Me takes an interface and returns a pointer to a float64 value
@@ -127,6 +135,23 @@ func (*CommandLineInterface) StringMe(i interface{}) *string {
 	}
 }
 
+// StringSliceMe takes an interface and returns a slice of string values
+// If the underlying interface kind is not []string or *[]string then nil is returned
+func (*CommandLineInterface) StringSliceMe(i interface{}) []string {
+	if i == nil {
+		return nil
+	}
+	switch v := i.(type) {
+	case *[]string:
+		return *v
+	case []string:
+		return v
+	default:
+		log.Printf("%s cannot be converted to a string slice", i)
+		return nil
+	}
+}
+
 // BoolMe takes an interface and returns a pointer to a bool value
 // If the underlying interface kind is not bool or *bool then nil is returned
 func (*CommandLineInterface) BoolMe(i interface{}) *bool {
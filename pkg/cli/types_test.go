@@ -86,3 +86,16 @@ func TestIntRangeMe(t *testing.T) {
 	val = cli.IntRangeMe(nil)
 	h.Assert(t, val == nil, "Should return nil if nil is passed in")
 }
+
+func TestStringSliceMe(t *testing.T) {
+	cli := getTestCLI()
+	stringSliceVal := []string{"test1", "test2"}
+	val := cli.StringSliceMe(stringSliceVal)
+	h.Equals(t, stringSliceVal, val)
+	val = cli.StringSliceMe(&stringSliceVal)
+	h.Equals(t, stringSliceVal, val)
+	val = cli.StringSliceMe(7)
+	h.Assert(t, val == nil, "Should return nil from other data type passed in")
+	val = cli.StringSliceMe(nil)
+	h.Assert(t, val == nil, "Should return nil if nil is passed in")
+}
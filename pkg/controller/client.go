@@ -0,0 +1,177 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package controller
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	serviceAccountDir     = "/var/run/secrets/kubernetes.io/serviceaccount"
+	managedByLabel        = "app.kubernetes.io/managed-by"
+	managedByValue        = "ec2-instance-selector"
+	serviceHostEnvVar     = "KUBERNETES_SERVICE_HOST"
+	servicePortEnvVar     = "KUBERNETES_SERVICE_PORT"
+	defaultRequestTimeout = 30 * time.Second
+)
+
+// errNotFound is returned when the Kubernetes API responds with 404 Not Found
+var errNotFound = errors.New("not found")
+
+// Client is a minimal Kubernetes API client for the resources used by the controller
+type Client struct {
+	// BaseURL is the URL of the Kubernetes API server, like https://10.0.0.1:443
+	BaseURL string
+	// Token is the bearer token used to authenticate with the API server
+	Token string
+	// TokenFile is read for the bearer token before every request when set, so that rotated tokens are picked up.
+	// It takes precedence over Token.
+	TokenFile string
+	// HTTPClient is used to make requests to the API server
+	HTTPClient *http.Client
+}
+
+// NewInClusterClient creates a Client using the service account mounted into the pod and the API server address
+// from the KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT env vars
+func NewInClusterClient() (*Client, error) {
+	host, port := os.Getenv(serviceHostEnvVar), os.Getenv(servicePortEnvVar)
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("Unable to find the Kubernetes API server, %s and %s must be set when running in a cluster", serviceHostEnvVar, servicePortEnvVar)
+	}
+	tokenFile := filepath.Join(serviceAccountDir, "token")
+	if _, err := ioutil.ReadFile(tokenFile); err != nil {
+		return nil, fmt.Errorf("Unable to read the service account token: %w", err)
+	}
+	caCert, err := ioutil.ReadFile(filepath.Join(serviceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read the service account CA certificate: %w", err)
+	}
+	caCertPool := x509.NewCertPool()
+	if !caCertPool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("Unable to parse the service account CA certificate")
+	}
+	return &Client{
+		BaseURL:   "https://" + net.JoinHostPort(host, port),
+		TokenFile: tokenFile,
+		HTTPClient: &http.Client{
+			Timeout:   defaultRequestTimeout,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: caCertPool}},
+		},
+	}, nil
+}
+
+// ListInstanceTypeSelections returns the InstanceTypeSelections in every namespace
+func (c *Client) ListInstanceTypeSelections() ([]InstanceTypeSelection, error) {
+	list := instanceTypeSelectionList{}
+	if err := c.do(http.MethodGet, fmt.Sprintf("/apis/%s/%s/%s", Group, Version, resource), nil, &list); err != nil {
+		return nil, fmt.Errorf("Unable to list %s: %w", resource, err)
+	}
+	return list.Items, nil
+}
+
+// UpdateInstanceTypeSelectionStatus replaces the status of the InstanceTypeSelection
+func (c *Client) UpdateInstanceTypeSelectionStatus(selection InstanceTypeSelection) error {
+	path := fmt.Sprintf("/apis/%s/%s/namespaces/%s/%s/%s/status", Group, Version, selection.Metadata.Namespace, resource, selection.Metadata.Name)
+	if err := c.do(http.MethodPut, path, selection, nil); err != nil {
+		return fmt.Errorf("Unable to update the status of %s %s/%s: %w", Kind, selection.Metadata.Namespace, selection.Metadata.Name, err)
+	}
+	return nil
+}
+
+// ApplyConfigMap creates the ConfigMap labeled as managed by the controller, or replaces its data if it already exists.
+// An existing ConfigMap without the label was not created by the controller and is not replaced.
+func (c *Client) ApplyConfigMap(configMap ConfigMap) error {
+	collectionPath := fmt.Sprintf("/api/v1/namespaces/%s/configmaps", configMap.Metadata.Namespace)
+	if configMap.Metadata.Labels == nil {
+		configMap.Metadata.Labels = map[string]string{}
+	}
+	configMap.Metadata.Labels[managedByLabel] = managedByValue
+	existing := ConfigMap{}
+	err := c.do(http.MethodGet, collectionPath+"/"+configMap.Metadata.Name, nil, &existing)
+	if errors.Is(err, errNotFound) {
+		err = c.do(http.MethodPost, collectionPath, configMap, nil)
+	} else if err == nil && existing.Metadata.Labels[managedByLabel] != managedByValue {
+		err = fmt.Errorf("the ConfigMap already exists and is not labeled %s=%s", managedByLabel, managedByValue)
+	} else if err == nil {
+		configMap.Metadata.ResourceVersion = existing.Metadata.ResourceVersion
+		err = c.do(http.MethodPut, collectionPath+"/"+configMap.Metadata.Name, configMap, nil)
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to apply ConfigMap %s/%s: %w", configMap.Metadata.Namespace, configMap.Metadata.Name, err)
+	}
+	return nil
+}
+
+// do makes a request to the API server with the body encoded as JSON and decodes the JSON response into out if it is not nil
+func (c *Client) do(method string, path string, body interface{}, out interface{}) error {
+	var reqBody []byte
+	if body != nil {
+		var err error
+		if reqBody, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, c.BaseURL+path, bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	token := c.Token
+	if c.TokenFile != "" {
+		tokenBytes, err := ioutil.ReadFile(c.TokenFile)
+		if err != nil {
+			return fmt.Errorf("Unable to read the token: %w", err)
+		}
+		token = strings.TrimSpace(string(tokenBytes))
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s returned %s: %s", method, path, resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(respBody, out)
+}
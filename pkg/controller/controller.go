@@ -0,0 +1,136 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package controller provides a Kubernetes controller which evaluates the filters declared in InstanceTypeSelection
+// custom resources periodically and publishes the matching instance types to a ConfigMap and the resource's status,
+// so that cluster tooling always has a fresh list of instance types.
+package controller
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+)
+
+// InstanceTypeSelection custom resource definition
+const (
+	Group    = "ec2instanceselector.aws"
+	Version  = "v1alpha1"
+	Kind     = "InstanceTypeSelection"
+	resource = "instancetypeselections"
+)
+
+const (
+	// InstanceTypesConfigMapKey is the ConfigMap key holding the matching instance types separated by newlines
+	InstanceTypesConfigMapKey = "instance-types"
+	configMapAPIVersion       = "v1"
+	configMapKind             = "ConfigMap"
+)
+
+// Controller reconciles InstanceTypeSelections
+type Controller struct {
+	client           *Client
+	instanceSelector selector.InstanceSelector
+	interval         time.Duration
+}
+
+// New creates a Controller which evaluates every InstanceTypeSelection's filters with the instanceSelector at the interval
+func New(client *Client, instanceSelector selector.InstanceSelector, interval time.Duration) *Controller {
+	return &Controller{
+		client:           client,
+		instanceSelector: instanceSelector,
+		interval:         interval,
+	}
+}
+
+// Run reconciles every InstanceTypeSelection at the interval until stop is closed
+func (c *Controller) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		if err := c.Reconcile(); err != nil {
+			log.Printf("An error occurred when reconciling %s resources, retrying in %s: %v\n", Kind, c.interval, err)
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reconcile evaluates the filters of every InstanceTypeSelection once and publishes the results.
+// Errors evaluating a single InstanceTypeSelection are recorded in its status instead of being returned.
+func (c *Controller) Reconcile() error {
+	selections, err := c.client.ListInstanceTypeSelections()
+	if err != nil {
+		return err
+	}
+	failed := []string{}
+	for _, selection := range selections {
+		if err := c.reconcileSelection(selection); err != nil {
+			log.Printf("An error occurred when reconciling %s %s/%s: %v\n", Kind, selection.Metadata.Namespace, selection.Metadata.Name, err)
+			failed = append(failed, selection.Metadata.Namespace+"/"+selection.Metadata.Name)
+		}
+	}
+	if len(failed) != 0 {
+		return fmt.Errorf("Unable to publish the results of %s [%s]", resource, strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// reconcileSelection evaluates the filters of the InstanceTypeSelection and publishes the results to its ConfigMap and status
+func (c *Controller) reconcileSelection(selection InstanceTypeSelection) error {
+	now := time.Now().UTC()
+	selection.Status = InstanceTypeSelectionStatus{LastEvaluated: &now}
+	instanceTypes, err := c.instanceSelector.Filter(selection.Spec.Filters)
+	if err != nil {
+		// the previously published ConfigMap is left as is so consumers keep the last good list
+		selection.Status.Error = err.Error()
+		return c.client.UpdateInstanceTypeSelectionStatus(selection)
+	}
+	selection.Status.InstanceTypes = instanceTypes
+	if err := c.client.ApplyConfigMap(newConfigMap(selection, instanceTypes)); err != nil {
+		return err
+	}
+	return c.client.UpdateInstanceTypeSelectionStatus(selection)
+}
+
+// newConfigMap creates the ConfigMap publishing the instance types matched by the InstanceTypeSelection
+func newConfigMap(selection InstanceTypeSelection, instanceTypes []string) ConfigMap {
+	name := selection.Spec.ConfigMapName
+	if name == "" {
+		name = selection.Metadata.Name
+	}
+	return ConfigMap{
+		APIVersion: configMapAPIVersion,
+		Kind:       configMapKind,
+		Metadata: ObjectMeta{
+			Name:      name,
+			Namespace: selection.Metadata.Namespace,
+			OwnerReferences: []OwnerReference{{
+				APIVersion: Group + "/" + Version,
+				Kind:       Kind,
+				Name:       selection.Metadata.Name,
+				UID:        selection.Metadata.UID,
+				Controller: true,
+			}},
+		},
+		Data: map[string]string{
+			InstanceTypesConfigMapKey: strings.Join(instanceTypes, "\n"),
+		},
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package controller_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/controller"
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+)
+
+// Helpers
+
+type mockedInstanceSelector struct {
+	selector.InstanceSelector
+	instanceTypes []string
+	err           error
+}
+
+func (m mockedInstanceSelector) Filter(filters selector.Filters) ([]string, error) {
+	return m.instanceTypes, m.err
+}
+
+// fakeAPIServer serves the Kubernetes API requests made by the controller and records the objects written to it
+type fakeAPIServer struct {
+	selections []controller.InstanceTypeSelection
+	configMaps map[string]controller.ConfigMap
+	statuses   map[string]controller.InstanceTypeSelectionStatus
+	requests   []string
+}
+
+func (s *fakeAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	body, _ := ioutil.ReadAll(r.Body)
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/apis/ec2instanceselector.aws/v1alpha1/instancetypeselections":
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": s.selections})
+	case r.Method == http.MethodPut && r.URL.Path == "/apis/ec2instanceselector.aws/v1alpha1/namespaces/default/instancetypeselections/web/status":
+		selection := controller.InstanceTypeSelection{}
+		json.Unmarshal(body, &selection)
+		s.statuses[selection.Metadata.Name] = selection.Status
+	case r.Method == http.MethodGet && r.URL.Path == "/api/v1/namespaces/default/configmaps/web-instance-types":
+		configMap, ok := s.configMaps["web-instance-types"]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		configMap.Metadata.ResourceVersion = "1"
+		json.NewEncoder(w).Encode(configMap)
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v1/namespaces/default/configmaps",
+		r.Method == http.MethodPut && r.URL.Path == "/api/v1/namespaces/default/configmaps/web-instance-types":
+		configMap := controller.ConfigMap{}
+		json.Unmarshal(body, &configMap)
+		s.configMaps[configMap.Metadata.Name] = configMap
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func setupAPIServer(t *testing.T) (*fakeAPIServer, *controller.Client, func()) {
+	apiServer := &fakeAPIServer{
+		selections: []controller.InstanceTypeSelection{{
+			Metadata: controller.ObjectMeta{Name: "web", Namespace: "default", UID: "1234"},
+			Spec:     controller.InstanceTypeSelectionSpec{ConfigMapName: "web-instance-types"},
+		}},
+		configMaps: map[string]controller.ConfigMap{},
+		statuses:   map[string]controller.InstanceTypeSelectionStatus{},
+	}
+	server := httptest.NewServer(apiServer)
+	return apiServer, &controller.Client{BaseURL: server.URL}, server.Close
+}
+
+// Tests
+
+func TestReconcile(t *testing.T) {
+	apiServer, client, closeServer := setupAPIServer(t)
+	defer closeServer()
+	c := controller.New(client, mockedInstanceSelector{instanceTypes: []string{"m5.large", "m5a.large"}}, time.Minute)
+	h.Ok(t, c.Reconcile())
+	configMap := apiServer.configMaps["web-instance-types"]
+	h.Equals(t, "m5.large\nm5a.large", configMap.Data[controller.InstanceTypesConfigMapKey])
+	h.Equals(t, "1234", configMap.Metadata.OwnerReferences[0].UID)
+	h.Equals(t, "ec2-instance-selector", configMap.Metadata.Labels["app.kubernetes.io/managed-by"])
+	h.Equals(t, []string{"m5.large", "m5a.large"}, apiServer.statuses["web"].InstanceTypes)
+	h.Assert(t, apiServer.statuses["web"].LastEvaluated != nil, "LastEvaluated should be set")
+
+	// the second reconcile should replace the existing ConfigMap
+	h.Ok(t, controller.New(client, mockedInstanceSelector{instanceTypes: []string{"m6i.large"}}, time.Minute).Reconcile())
+	h.Equals(t, "m6i.large", apiServer.configMaps["web-instance-types"].Data[controller.InstanceTypesConfigMapKey])
+	h.Equals(t, "PUT /api/v1/namespaces/default/configmaps/web-instance-types", apiServer.requests[len(apiServer.requests)-2])
+}
+
+func TestReconcile_ConfigMapNotManaged(t *testing.T) {
+	apiServer, client, closeServer := setupAPIServer(t)
+	defer closeServer()
+	apiServer.configMaps["web-instance-types"] = controller.ConfigMap{
+		Metadata: controller.ObjectMeta{Name: "web-instance-types", Namespace: "default"},
+		Data:     map[string]string{"app.properties": "debug=true"},
+	}
+	c := controller.New(client, mockedInstanceSelector{instanceTypes: []string{"m5.large"}}, time.Minute)
+	h.Nok(t, c.Reconcile())
+	h.Equals(t, map[string]string{"app.properties": "debug=true"}, apiServer.configMaps["web-instance-types"].Data)
+	h.Equals(t, 0, len(apiServer.statuses))
+}
+
+func TestReconcile_FilterError(t *testing.T) {
+	apiServer, client, closeServer := setupAPIServer(t)
+	defer closeServer()
+	c := controller.New(client, mockedInstanceSelector{err: errors.New("throttled")}, time.Minute)
+	h.Ok(t, c.Reconcile())
+	h.Equals(t, 0, len(apiServer.configMaps))
+	h.Equals(t, "throttled", apiServer.statuses["web"].Error)
+}
+
+func TestReconcile_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+	c := controller.New(&controller.Client{BaseURL: server.URL}, mockedInstanceSelector{}, time.Minute)
+	h.Nok(t, c.Reconcile())
+}
+
+func TestClient_TokenFile(t *testing.T) {
+	authorizations := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": []controller.InstanceTypeSelection{}})
+	}))
+	defer server.Close()
+	tempDir, err := ioutil.TempDir("", "token")
+	h.Ok(t, err)
+	defer os.RemoveAll(tempDir)
+	tokenFile := filepath.Join(tempDir, "token")
+	h.Ok(t, ioutil.WriteFile(tokenFile, []byte("first\n"), 0600))
+	client := &controller.Client{BaseURL: server.URL, Token: "ignored", TokenFile: tokenFile}
+	_, err = client.ListInstanceTypeSelections()
+	h.Ok(t, err)
+
+	// a rotated token is used by the next request
+	h.Ok(t, ioutil.WriteFile(tokenFile, []byte("second\n"), 0600))
+	_, err = client.ListInstanceTypeSelections()
+	h.Ok(t, err)
+	h.Equals(t, []string{"Bearer first", "Bearer second"}, authorizations)
+
+	h.Ok(t, os.Remove(tokenFile))
+	_, err = client.ListInstanceTypeSelections()
+	h.Nok(t, err)
+	h.Equals(t, 2, len(authorizations))
+}
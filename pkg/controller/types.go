@@ -0,0 +1,81 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package controller
+
+import (
+	"time"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+)
+
+// InstanceTypeSelection declares filters which the controller evaluates periodically,
+// publishing the matching instance types to a ConfigMap and the status
+type InstanceTypeSelection struct {
+	APIVersion string                      `json:"apiVersion"`
+	Kind       string                      `json:"kind"`
+	Metadata   ObjectMeta                  `json:"metadata"`
+	Spec       InstanceTypeSelectionSpec   `json:"spec"`
+	Status     InstanceTypeSelectionStatus `json:"status,omitempty"`
+}
+
+// InstanceTypeSelectionSpec is the desired state of an InstanceTypeSelection
+type InstanceTypeSelectionSpec struct {
+	// Filters are evaluated in the region the controller is configured with
+	Filters selector.Filters `json:"filters"`
+	// ConfigMapName is the ConfigMap in the same namespace the matching instance types are published to.
+	// Defaults to the name of the InstanceTypeSelection.
+	ConfigMapName string `json:"configMapName,omitempty"`
+}
+
+// InstanceTypeSelectionStatus is the observed state of an InstanceTypeSelection
+type InstanceTypeSelectionStatus struct {
+	// InstanceTypes are the instance types which matched the filters when they were last evaluated
+	InstanceTypes []string `json:"instanceTypes,omitempty"`
+	// LastEvaluated is when the filters were last evaluated
+	LastEvaluated *time.Time `json:"lastEvaluated,omitempty"`
+	// Error is the error which occurred when the filters were last evaluated, if any
+	Error string `json:"error,omitempty"`
+}
+
+// instanceTypeSelectionList is the response of listing InstanceTypeSelections
+type instanceTypeSelectionList struct {
+	Items []InstanceTypeSelection `json:"items"`
+}
+
+// ObjectMeta holds the Kubernetes object metadata used by the controller
+type ObjectMeta struct {
+	Name            string            `json:"name"`
+	Namespace       string            `json:"namespace,omitempty"`
+	UID             string            `json:"uid,omitempty"`
+	ResourceVersion string            `json:"resourceVersion,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	OwnerReferences []OwnerReference  `json:"ownerReferences,omitempty"`
+}
+
+// OwnerReference links a ConfigMap to the InstanceTypeSelection which owns it so that it is garbage collected with it
+type OwnerReference struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	UID        string `json:"uid"`
+	Controller bool   `json:"controller"`
+}
+
+// ConfigMap is a Kubernetes ConfigMap
+type ConfigMap struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Metadata   ObjectMeta        `json:"metadata"`
+	Data       map[string]string `json:"data"`
+}
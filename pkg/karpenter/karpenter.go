@@ -0,0 +1,283 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package karpenter converts between instance selector filters and Karpenter node selector requirements
+// so that tools working with Karpenter can reuse the selector's semantics without parsing YAML output.
+package karpenter
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+)
+
+// Well-known labels Karpenter sets on the nodes it launches
+const (
+	LabelInstanceType      = "node.kubernetes.io/instance-type"
+	LabelArch              = "kubernetes.io/arch"
+	LabelCapacityType      = "karpenter.sh/capacity-type"
+	LabelZone              = "topology.kubernetes.io/zone"
+	LabelZoneID            = "topology.k8s.aws/zone-id"
+	LabelRegion            = "topology.kubernetes.io/region"
+	LabelInstanceCPU       = "karpenter.k8s.aws/instance-cpu"
+	LabelInstanceMemory    = "karpenter.k8s.aws/instance-memory"
+	LabelInstanceGPUCount  = "karpenter.k8s.aws/instance-gpu-count"
+	LabelInstanceGPUMemory = "karpenter.k8s.aws/instance-gpu-memory"
+	LabelHypervisor        = "karpenter.k8s.aws/instance-hypervisor"
+)
+
+// Node selector operators used in Karpenter requirements
+const (
+	OperatorIn = "In"
+	OperatorGt = "Gt"
+	OperatorLt = "Lt"
+)
+
+const (
+	maxInt = int(^uint(0) >> 1)
+	// zoneIDSeparator separates the region and zone number of a zone id, like use1-az1
+	zoneIDSeparator = "-az"
+)
+
+// NodeSelectorRequirement mirrors the Kubernetes core/v1 NodeSelectorRequirement used in Karpenter requirements
+type NodeSelectorRequirement struct {
+	Key      string   `json:"key"`
+	Operator string   `json:"operator"`
+	Values   []string `json:"values,omitempty"`
+}
+
+// archToKubernetes maps EC2 cpu architectures to the GOARCH values of the kubernetes.io/arch label
+var archToKubernetes = map[string]string{
+	"x86_64": "amd64",
+	"arm64":  "arm64",
+}
+
+// supportedFilters are the json names of the filters which FromFilters converts to requirements,
+// or which only shape the results (like maxResults) and do not restrict the instance types Karpenter may launch
+var supportedFilters = map[string]bool{
+	"availabilityZone":    true,
+	"cpuArchitecture":     true,
+	"gpuMemoryRange":      true,
+	"gpusRange":           true,
+	"hypervisor":          true,
+	"instanceTypes":       true,
+	"maxResults":          true,
+	"maxResultsPerFamily": true,
+	"memoryRange":         true,
+	"region":              true,
+	"sortBy":              true,
+	"usageClass":          true,
+	"vcpusRange":          true,
+}
+
+// FromInstanceTypes returns a requirement restricting nodes to the instance types, like the results of selector.Filter
+func FromInstanceTypes(instanceTypes []string) []NodeSelectorRequirement {
+	return []NodeSelectorRequirement{{Key: LabelInstanceType, Operator: OperatorIn, Values: instanceTypes}}
+}
+
+// FromFilters returns the requirements which express the filters with Karpenter's well-known labels.
+// Filters which have no equivalent label, or values which have no equivalent label value like the i386 cpu architecture,
+// are not converted. Their json names are returned so that callers can fall back to FromInstanceTypes with the results of selector.Filter.
+func FromFilters(filters selector.Filters) ([]NodeSelectorRequirement, []string) {
+	requirements := []NodeSelectorRequirement{}
+	unsupported := unsupportedFilters(filters)
+	if len(filters.InstanceTypes) > 0 {
+		requirements = append(requirements, FromInstanceTypes(filters.InstanceTypes)...)
+	}
+	if len(filters.CPUArchitecture) > 0 {
+		archs := []string{}
+		for _, arch := range filters.CPUArchitecture {
+			if kubernetesArch, ok := archToKubernetes[arch]; ok {
+				archs = append(archs, kubernetesArch)
+			}
+		}
+		// a requirement on only the mapped architectures would exclude the instance types of the others
+		if len(archs) == len(filters.CPUArchitecture) {
+			requirements = append(requirements, NodeSelectorRequirement{Key: LabelArch, Operator: OperatorIn, Values: archs})
+		} else {
+			unsupported = append(unsupported, "cpuArchitecture")
+			sort.Strings(unsupported)
+		}
+	}
+	if len(filters.UsageClass) > 0 {
+		requirements = append(requirements, NodeSelectorRequirement{Key: LabelCapacityType, Operator: OperatorIn, Values: filters.UsageClass})
+	}
+	if len(filters.Hypervisor) > 0 {
+		requirements = append(requirements, NodeSelectorRequirement{Key: LabelHypervisor, Operator: OperatorIn, Values: filters.Hypervisor})
+	}
+	if filters.AvailabilityZone != nil {
+		requirements = append(requirements, NodeSelectorRequirement{Key: zoneLabel(*filters.AvailabilityZone), Operator: OperatorIn, Values: []string{*filters.AvailabilityZone}})
+	}
+	if filters.Region != nil {
+		requirements = append(requirements, NodeSelectorRequirement{Key: LabelRegion, Operator: OperatorIn, Values: []string{*filters.Region}})
+	}
+	for _, label := range []string{LabelInstanceCPU, LabelInstanceMemory, LabelInstanceGPUCount, LabelInstanceGPUMemory} {
+		if intRange := *intRangeFilter(&filters, label); intRange != nil {
+			requirements = append(requirements, intRangeRequirements(label, *intRange)...)
+		}
+	}
+	return requirements, unsupported
+}
+
+// ToFilters returns the filters which select the instance types allowed by the requirements.
+// An error is returned for requirements on labels or with operators which have no equivalent filter.
+func ToFilters(requirements []NodeSelectorRequirement) (selector.Filters, error) {
+	filters := selector.Filters{}
+	for _, requirement := range requirements {
+		if intRange := intRangeFilter(&filters, requirement.Key); intRange != nil {
+			narrowed, err := narrowIntRange(*intRange, requirement)
+			if err != nil {
+				return selector.Filters{}, err
+			}
+			*intRange = narrowed
+			continue
+		}
+		if requirement.Operator != OperatorIn {
+			return selector.Filters{}, fmt.Errorf("Unable to convert the requirement on %s: only the %s operator is supported for this label", requirement.Key, OperatorIn)
+		}
+		switch requirement.Key {
+		case LabelInstanceType:
+			filters.InstanceTypes = requirement.Values
+		case LabelArch:
+			filters.CPUArchitecture = []string{}
+			for _, value := range requirement.Values {
+				filters.CPUArchitecture = append(filters.CPUArchitecture, ec2Arch(value))
+			}
+		case LabelCapacityType:
+			filters.UsageClass = requirement.Values
+		case LabelHypervisor:
+			filters.Hypervisor = requirement.Values
+		case LabelZone, LabelZoneID, LabelRegion:
+			if len(requirement.Values) != 1 {
+				return selector.Filters{}, fmt.Errorf("Unable to convert the requirement on %s: exactly one value is supported but found %d", requirement.Key, len(requirement.Values))
+			}
+			location := requirement.Values[0]
+			if requirement.Key == LabelRegion {
+				filters.Region = &location
+			} else {
+				filters.AvailabilityZone = &location
+			}
+		default:
+			return selector.Filters{}, fmt.Errorf("Unable to convert the requirement on %s: the label has no equivalent filter", requirement.Key)
+		}
+	}
+	return filters, nil
+}
+
+// intRangeFilter returns a pointer to the range filter of a numeric label, or nil if the label is not numeric
+func intRangeFilter(filters *selector.Filters, label string) **selector.IntRangeFilter {
+	switch label {
+	case LabelInstanceCPU:
+		return &filters.VCpusRange
+	case LabelInstanceMemory:
+		return &filters.MemoryRange
+	case LabelInstanceGPUCount:
+		return &filters.GpusRange
+	case LabelInstanceGPUMemory:
+		return &filters.GpuMemoryRange
+	}
+	return nil
+}
+
+// intRangeRequirements returns Gt and Lt requirements for the bounds of the range which are set.
+// Karpenter's Gt and Lt operators are exclusive so the inclusive bounds are widened by one.
+func intRangeRequirements(label string, intRange selector.IntRangeFilter) []NodeSelectorRequirement {
+	requirements := []NodeSelectorRequirement{}
+	if intRange.LowerBound > 0 {
+		requirements = append(requirements, NodeSelectorRequirement{Key: label, Operator: OperatorGt, Values: []string{strconv.Itoa(intRange.LowerBound - 1)}})
+	}
+	if intRange.UpperBound < maxInt {
+		requirements = append(requirements, NodeSelectorRequirement{Key: label, Operator: OperatorLt, Values: []string{strconv.Itoa(intRange.UpperBound + 1)}})
+	}
+	return requirements
+}
+
+// narrowIntRange returns the range narrowed by a Gt, Lt, or single valued In requirement
+func narrowIntRange(intRange *selector.IntRangeFilter, requirement NodeSelectorRequirement) (*selector.IntRangeFilter, error) {
+	narrowed := selector.IntRangeFilter{LowerBound: 0, UpperBound: maxInt}
+	if intRange != nil {
+		narrowed = *intRange
+	}
+	if len(requirement.Values) != 1 {
+		return nil, fmt.Errorf("Unable to convert the requirement on %s: exactly one value is supported but found %d", requirement.Key, len(requirement.Values))
+	}
+	value, err := strconv.Atoi(requirement.Values[0])
+	if err != nil {
+		return nil, fmt.Errorf("Unable to convert the requirement on %s: %s is not an integer", requirement.Key, requirement.Values[0])
+	}
+	switch requirement.Operator {
+	case OperatorGt:
+		narrowed.LowerBound = maxOf(narrowed.LowerBound, value+1)
+	case OperatorLt:
+		narrowed.UpperBound = minOf(narrowed.UpperBound, value-1)
+	case OperatorIn:
+		narrowed.LowerBound = maxOf(narrowed.LowerBound, value)
+		narrowed.UpperBound = minOf(narrowed.UpperBound, value)
+	default:
+		return nil, fmt.Errorf("Unable to convert the requirement on %s: only the %s, %s, and %s operators are supported for this label", requirement.Key, OperatorGt, OperatorLt, OperatorIn)
+	}
+	return &narrowed, nil
+}
+
+// unsupportedFilters returns the sorted json names of the filters which are set but have no equivalent requirement
+func unsupportedFilters(filters selector.Filters) []string {
+	unsupported := []string{}
+	filtersValue := reflect.ValueOf(filters)
+	for i := 0; i < filtersValue.NumField(); i++ {
+		if filtersValue.Field(i).IsNil() {
+			continue
+		}
+		name := strings.Split(filtersValue.Type().Field(i).Tag.Get("json"), ",")[0]
+		if !supportedFilters[name] {
+			unsupported = append(unsupported, name)
+		}
+	}
+	sort.Strings(unsupported)
+	return unsupported
+}
+
+// zoneLabel returns the topology label for an availability zone name or id
+func zoneLabel(zone string) string {
+	if strings.Contains(zone, zoneIDSeparator) {
+		return LabelZoneID
+	}
+	return LabelZone
+}
+
+// ec2Arch returns the EC2 cpu architecture of a kubernetes.io/arch value
+func ec2Arch(kubernetesArch string) string {
+	for arch, k8sArch := range archToKubernetes {
+		if k8sArch == kubernetesArch {
+			return arch
+		}
+	}
+	return kubernetesArch
+}
+
+func maxOf(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minOf(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
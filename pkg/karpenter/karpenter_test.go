@@ -0,0 +1,101 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package karpenter_test
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/karpenter"
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+const maxInt = int(^uint(0) >> 1)
+
+func TestFromInstanceTypes(t *testing.T) {
+	requirements := karpenter.FromInstanceTypes([]string{"m5.large", "c5.large"})
+	h.Equals(t, []karpenter.NodeSelectorRequirement{{Key: karpenter.LabelInstanceType, Operator: karpenter.OperatorIn, Values: []string{"m5.large", "c5.large"}}}, requirements)
+}
+
+func TestFromFilters(t *testing.T) {
+	filters := selector.Filters{
+		CPUArchitecture:  []string{"x86_64"},
+		UsageClass:       []string{"spot"},
+		AvailabilityZone: aws.String("use1-az1"),
+		VCpusRange:       &selector.IntRangeFilter{LowerBound: 2, UpperBound: 4},
+		MemoryRange:      &selector.IntRangeFilter{LowerBound: 4096, UpperBound: maxInt},
+		MaxResults:       aws.Int(10),
+		Burstable:        aws.Bool(false),
+	}
+	requirements, unsupported := karpenter.FromFilters(filters)
+	h.Equals(t, []karpenter.NodeSelectorRequirement{
+		{Key: karpenter.LabelArch, Operator: karpenter.OperatorIn, Values: []string{"amd64"}},
+		{Key: karpenter.LabelCapacityType, Operator: karpenter.OperatorIn, Values: []string{"spot"}},
+		{Key: karpenter.LabelZoneID, Operator: karpenter.OperatorIn, Values: []string{"use1-az1"}},
+		{Key: karpenter.LabelInstanceCPU, Operator: karpenter.OperatorGt, Values: []string{"1"}},
+		{Key: karpenter.LabelInstanceCPU, Operator: karpenter.OperatorLt, Values: []string{"5"}},
+		{Key: karpenter.LabelInstanceMemory, Operator: karpenter.OperatorGt, Values: []string{"4095"}},
+	}, requirements)
+	h.Equals(t, []string{"burstable"}, unsupported)
+}
+
+func TestFromFilters_UnmappedArchitecture(t *testing.T) {
+	for _, archs := range [][]string{{"i386"}, {"x86_64", "x86_64_mac"}} {
+		requirements, unsupported := karpenter.FromFilters(selector.Filters{CPUArchitecture: archs, Burstable: aws.Bool(true)})
+		h.Equals(t, []karpenter.NodeSelectorRequirement{}, requirements)
+		h.Equals(t, []string{"burstable", "cpuArchitecture"}, unsupported)
+	}
+}
+
+func TestToFilters(t *testing.T) {
+	filters, err := karpenter.ToFilters([]karpenter.NodeSelectorRequirement{
+		{Key: karpenter.LabelArch, Operator: karpenter.OperatorIn, Values: []string{"amd64", "arm64"}},
+		{Key: karpenter.LabelZone, Operator: karpenter.OperatorIn, Values: []string{"us-east-1a"}},
+		{Key: karpenter.LabelInstanceCPU, Operator: karpenter.OperatorGt, Values: []string{"1"}},
+		{Key: karpenter.LabelInstanceCPU, Operator: karpenter.OperatorLt, Values: []string{"5"}},
+		{Key: karpenter.LabelInstanceGPUCount, Operator: karpenter.OperatorIn, Values: []string{"1"}},
+	})
+	h.Ok(t, err)
+	h.Equals(t, []string{"x86_64", "arm64"}, filters.CPUArchitecture)
+	h.Equals(t, "us-east-1a", *filters.AvailabilityZone)
+	h.Equals(t, selector.IntRangeFilter{LowerBound: 2, UpperBound: 4}, *filters.VCpusRange)
+	h.Equals(t, selector.IntRangeFilter{LowerBound: 1, UpperBound: 1}, *filters.GpusRange)
+	h.Assert(t, filters.MemoryRange == nil, "MemoryRange should not be set")
+}
+
+func TestToFilters_RoundTrip(t *testing.T) {
+	filters := selector.Filters{
+		InstanceTypes: []string{"m5.large"},
+		UsageClass:    []string{"on-demand"},
+		Region:        aws.String("us-east-2"),
+		MemoryRange:   &selector.IntRangeFilter{LowerBound: 0, UpperBound: 8192},
+	}
+	requirements, unsupported := karpenter.FromFilters(filters)
+	h.Equals(t, 0, len(unsupported))
+	roundTripped, err := karpenter.ToFilters(requirements)
+	h.Ok(t, err)
+	h.Equals(t, filters, roundTripped)
+}
+
+func TestToFilters_Unsupported(t *testing.T) {
+	_, err := karpenter.ToFilters([]karpenter.NodeSelectorRequirement{{Key: "karpenter.k8s.aws/instance-family", Operator: karpenter.OperatorIn, Values: []string{"m5"}}})
+	h.Nok(t, err)
+	_, err = karpenter.ToFilters([]karpenter.NodeSelectorRequirement{{Key: karpenter.LabelArch, Operator: "NotIn", Values: []string{"arm64"}}})
+	h.Nok(t, err)
+	_, err = karpenter.ToFilters([]karpenter.NodeSelectorRequirement{{Key: karpenter.LabelInstanceCPU, Operator: karpenter.OperatorGt, Values: []string{"two"}}})
+	h.Nok(t, err)
+	_, err = karpenter.ToFilters([]karpenter.NodeSelectorRequirement{{Key: karpenter.LabelRegion, Operator: karpenter.OperatorIn, Values: []string{"us-east-1", "us-west-2"}}})
+	h.Nok(t, err)
+}
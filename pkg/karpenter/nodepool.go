@@ -0,0 +1,107 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package karpenter
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	"github.com/ghodss/yaml"
+)
+
+// Kinds of the Karpenter resources which hold requirements
+const (
+	KindNodePool    = "NodePool"
+	KindProvisioner = "Provisioner"
+)
+
+// documentSeparator splits a multi-document YAML file
+var documentSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+
+// nodePool holds the fields of a NodePool (karpenter.sh/v1beta1 and v1) or Provisioner (karpenter.sh/v1alpha5)
+// which carry requirements. A NodePool nests them in its node template while a Provisioner has them in its spec.
+type nodePool struct {
+	Kind string `json:"kind"`
+	Spec struct {
+		Requirements []NodeSelectorRequirement `json:"requirements"`
+		Template     struct {
+			Spec struct {
+				Requirements []NodeSelectorRequirement `json:"requirements"`
+			} `json:"spec"`
+		} `json:"template"`
+	} `json:"spec"`
+}
+
+// LoadNodePoolFile reads a Karpenter NodePool or Provisioner manifest and returns the filters equivalent to its requirements
+// along with the requirements which have no equivalent filter. See FiltersFromNodePool.
+func LoadNodePoolFile(path string) (selector.Filters, []NodeSelectorRequirement, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return selector.Filters{}, nil, fmt.Errorf("Unable to read Karpenter manifest %s: %w", path, err)
+	}
+	filters, unsupported, err := FiltersFromNodePool(contents)
+	if err != nil {
+		return selector.Filters{}, nil, fmt.Errorf("Unable to convert Karpenter manifest %s: %w", path, err)
+	}
+	return filters, unsupported, nil
+}
+
+// FiltersFromNodePool accepts a YAML or JSON Karpenter NodePool or Provisioner manifest and returns the filters which select
+// the instance types its requirements allow. Requirements which ToFilters cannot convert, like instance-family or NotIn,
+// are returned instead of failing so that the filters can still be applied. They select a superset of the instance types
+// Karpenter would consider when any requirement is unsupported.
+func FiltersFromNodePool(contents []byte) (selector.Filters, []NodeSelectorRequirement, error) {
+	requirements, err := ParseNodePool(contents)
+	if err != nil {
+		return selector.Filters{}, nil, err
+	}
+	supported := []NodeSelectorRequirement{}
+	unsupported := []NodeSelectorRequirement{}
+	for _, requirement := range requirements {
+		if _, err := ToFilters(append(supported, requirement)); err != nil {
+			unsupported = append(unsupported, requirement)
+			continue
+		}
+		supported = append(supported, requirement)
+	}
+	filters, err := ToFilters(supported)
+	if err != nil {
+		return selector.Filters{}, nil, err
+	}
+	return filters, unsupported, nil
+}
+
+// ParseNodePool returns the requirements of the first NodePool or Provisioner in a YAML or JSON manifest.
+// Other documents of a multi-document manifest, like an EC2NodeClass, are skipped.
+func ParseNodePool(contents []byte) ([]NodeSelectorRequirement, error) {
+	for _, document := range documentSeparator.Split(string(contents), -1) {
+		if len(bytes.TrimSpace([]byte(document))) == 0 {
+			continue
+		}
+		manifest := nodePool{}
+		if err := yaml.Unmarshal([]byte(document), &manifest); err != nil {
+			return nil, err
+		}
+		switch manifest.Kind {
+		case KindNodePool:
+			return manifest.Spec.Template.Spec.Requirements, nil
+		case KindProvisioner:
+			return manifest.Spec.Requirements, nil
+		}
+	}
+	return nil, fmt.Errorf("no %s or %s was found", KindNodePool, KindProvisioner)
+}
@@ -0,0 +1,116 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package karpenter_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/karpenter"
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+)
+
+const nodePool = `apiVersion: karpenter.k8s.aws/v1
+kind: EC2NodeClass
+metadata:
+  name: default
+spec:
+  amiSelectorTerms:
+    - alias: al2023@latest
+---
+apiVersion: karpenter.sh/v1
+kind: NodePool
+metadata:
+  name: default
+spec:
+  template:
+    spec:
+      requirements:
+        - key: kubernetes.io/arch
+          operator: In
+          values: ["amd64"]
+        - key: karpenter.sh/capacity-type
+          operator: In
+          values: ["spot", "on-demand"]
+        - key: karpenter.k8s.aws/instance-cpu
+          operator: Gt
+          values: ["3"]
+        - key: karpenter.k8s.aws/instance-family
+          operator: In
+          values: ["m5", "c5"]
+          minValues: 2
+        - key: karpenter.k8s.aws/instance-gpu-count
+          operator: Lt
+          values: ["1"]
+      nodeClassRef:
+        group: karpenter.k8s.aws
+        kind: EC2NodeClass
+        name: default
+`
+
+const provisioner = `apiVersion: karpenter.sh/v1alpha5
+kind: Provisioner
+metadata:
+  name: default
+spec:
+  requirements:
+    - key: node.kubernetes.io/instance-type
+      operator: In
+      values: ["m5.large", "m5.xlarge"]
+    - key: topology.kubernetes.io/zone
+      operator: NotIn
+      values: ["us-east-1e"]
+`
+
+func TestFiltersFromNodePool(t *testing.T) {
+	filters, unsupported, err := karpenter.FiltersFromNodePool([]byte(nodePool))
+	h.Ok(t, err)
+	h.Equals(t, []string{"x86_64"}, filters.CPUArchitecture)
+	h.Equals(t, []string{"spot", "on-demand"}, filters.UsageClass)
+	h.Equals(t, &selector.IntRangeFilter{LowerBound: 4, UpperBound: maxInt}, filters.VCpusRange)
+	h.Equals(t, &selector.IntRangeFilter{LowerBound: 0, UpperBound: 0}, filters.GpusRange)
+	h.Equals(t, []karpenter.NodeSelectorRequirement{{Key: "karpenter.k8s.aws/instance-family", Operator: karpenter.OperatorIn, Values: []string{"m5", "c5"}}}, unsupported)
+}
+
+func TestFiltersFromNodePool_Provisioner(t *testing.T) {
+	filters, unsupported, err := karpenter.FiltersFromNodePool([]byte(provisioner))
+	h.Ok(t, err)
+	h.Equals(t, []string{"m5.large", "m5.xlarge"}, filters.InstanceTypes)
+	h.Assert(t, filters.AvailabilityZone == nil, "NotIn zone requirement should not set a zone filter")
+	h.Equals(t, 1, len(unsupported))
+	h.Equals(t, "NotIn", unsupported[0].Operator)
+}
+
+func TestParseNodePool_NotFound(t *testing.T) {
+	_, err := karpenter.ParseNodePool([]byte("apiVersion: v1\nkind: ConfigMap\n"))
+	h.Nok(t, err)
+	_, err = karpenter.ParseNodePool([]byte("kind: [NodePool"))
+	h.Nok(t, err)
+}
+
+func TestLoadNodePoolFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nodepool")
+	h.Ok(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "nodepool.yaml")
+	h.Ok(t, ioutil.WriteFile(path, []byte(nodePool), 0644))
+	filters, _, err := karpenter.LoadNodePoolFile(path)
+	h.Ok(t, err)
+	h.Equals(t, []string{"x86_64"}, filters.CPUArchitecture)
+	_, _, err = karpenter.LoadNodePoolFile(filepath.Join(dir, "missing.yaml"))
+	h.Nok(t, err)
+}
@@ -0,0 +1,214 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package optimizer intersects AWS Compute Optimizer right-sizing recommendations with instance type filters
+// so that the suggested instance types respect organizational constraints like architecture, families, or spot support.
+package optimizer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/computeoptimizer"
+	"github.com/aws/aws-sdk-go/service/computeoptimizer/computeoptimizeriface"
+)
+
+// autoScalingGroupARNResource identifies Auto Scaling group ARNs, like arn:aws:autoscaling:us-east-1:123456789012:autoScalingGroup:...
+const autoScalingGroupARNResource = ":autoScalingGroup:"
+
+// Recommendation is the Compute Optimizer recommendation for an EC2 instance or Auto Scaling group
+type Recommendation struct {
+	// ResourceARN is the ARN of the instance or Auto Scaling group
+	ResourceARN string
+	// ResourceType is Ec2Instance or AutoScalingGroup
+	ResourceType string
+	// CurrentInstanceType is the instance type the resource runs
+	CurrentInstanceType string
+	// Finding is how Compute Optimizer classified the resource, like Overprovisioned
+	Finding string
+	// RecommendedInstanceTypes are the instance types recommended by Compute Optimizer, best first
+	RecommendedInstanceTypes []string
+	// AllowedInstanceTypes are the recommended instance types which match the filters, best first. Only set by Intersect.
+	AllowedInstanceTypes []string
+	// Rejections maps each recommended instance type which does not match the filters to the filters which rejected it.
+	// Only set by Intersect.
+	Rejections map[string][]selector.FilterRejection
+}
+
+// InstanceSelector is the subset of selector.Selector used to intersect recommendations with filters
+type InstanceSelector interface {
+	Audit(filters selector.Filters, candidates []string) (*selector.AuditResults, error)
+}
+
+// Recommendations retrieves the Compute Optimizer recommendations of the resources, which can be instance or Auto Scaling group ARNs.
+// The recommendations of every instance and Auto Scaling group in the account are retrieved when no ARNs are passed.
+func Recommendations(client computeoptimizeriface.ComputeOptimizerAPI, arns []string) ([]Recommendation, error) {
+	instanceARNs := []string{}
+	asgARNs := []string{}
+	for _, arn := range arns {
+		if strings.Contains(arn, autoScalingGroupARNResource) {
+			asgARNs = append(asgARNs, arn)
+		} else {
+			instanceARNs = append(instanceARNs, arn)
+		}
+	}
+	recommendations := []Recommendation{}
+	if len(arns) == 0 || len(instanceARNs) != 0 {
+		instanceRecommendations, err := instanceRecommendations(client, instanceARNs)
+		if err != nil {
+			return nil, err
+		}
+		recommendations = append(recommendations, instanceRecommendations...)
+	}
+	if len(arns) == 0 || len(asgARNs) != 0 {
+		asgRecommendations, err := autoScalingGroupRecommendations(client, asgARNs)
+		if err != nil {
+			return nil, err
+		}
+		recommendations = append(recommendations, asgRecommendations...)
+	}
+	return recommendations, nil
+}
+
+// Intersect applies the filters to the recommended instance types of each recommendation
+// and sets the allowed instance types in the order Compute Optimizer ranked them
+func Intersect(instanceSelector InstanceSelector, filters selector.Filters, recommendations []Recommendation) ([]Recommendation, error) {
+	intersected := []Recommendation{}
+	for _, recommendation := range recommendations {
+		recommendation.AllowedInstanceTypes = []string{}
+		recommendation.Rejections = map[string][]selector.FilterRejection{}
+		if len(recommendation.RecommendedInstanceTypes) != 0 {
+			auditResults, err := instanceSelector.Audit(filters, recommendation.RecommendedInstanceTypes)
+			if err != nil {
+				return nil, fmt.Errorf("Unable to filter the recommended instance types of %s: %w", recommendation.ResourceARN, err)
+			}
+			passed := map[string]bool{}
+			for _, instanceType := range auditResults.Passed {
+				passed[instanceType] = true
+			}
+			for _, instanceType := range recommendation.RecommendedInstanceTypes {
+				if passed[instanceType] {
+					recommendation.AllowedInstanceTypes = append(recommendation.AllowedInstanceTypes, instanceType)
+				}
+			}
+			recommendation.Rejections = auditResults.Failed
+		}
+		intersected = append(intersected, recommendation)
+	}
+	return intersected, nil
+}
+
+// instanceRecommendations pages through GetEC2InstanceRecommendations for the instance ARNs, or every instance when there are none
+func instanceRecommendations(client computeoptimizeriface.ComputeOptimizerAPI, instanceARNs []string) ([]Recommendation, error) {
+	recommendations := []Recommendation{}
+	input := &computeoptimizer.GetEC2InstanceRecommendationsInput{}
+	if len(instanceARNs) != 0 {
+		input.InstanceArns = aws.StringSlice(instanceARNs)
+	}
+	for {
+		output, err := client.GetEC2InstanceRecommendations(input)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to retrieve EC2 instance recommendations from Compute Optimizer: %w", err)
+		}
+		if err := recommendationErrors(output.Errors); err != nil {
+			return nil, err
+		}
+		for _, instanceRecommendation := range output.InstanceRecommendations {
+			ranked := map[int64]string{}
+			for _, option := range instanceRecommendation.RecommendationOptions {
+				ranked[aws.Int64Value(option.Rank)] = aws.StringValue(option.InstanceType)
+			}
+			recommendations = append(recommendations, Recommendation{
+				ResourceARN:              aws.StringValue(instanceRecommendation.InstanceArn),
+				ResourceType:             computeoptimizer.ResourceTypeEc2instance,
+				CurrentInstanceType:      aws.StringValue(instanceRecommendation.CurrentInstanceType),
+				Finding:                  aws.StringValue(instanceRecommendation.Finding),
+				RecommendedInstanceTypes: byRank(ranked),
+			})
+		}
+		if output.NextToken == nil {
+			return recommendations, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
+
+// autoScalingGroupRecommendations pages through GetAutoScalingGroupRecommendations for the Auto Scaling group ARNs,
+// or every Auto Scaling group when there are none
+func autoScalingGroupRecommendations(client computeoptimizeriface.ComputeOptimizerAPI, asgARNs []string) ([]Recommendation, error) {
+	recommendations := []Recommendation{}
+	input := &computeoptimizer.GetAutoScalingGroupRecommendationsInput{}
+	if len(asgARNs) != 0 {
+		input.AutoScalingGroupArns = aws.StringSlice(asgARNs)
+	}
+	for {
+		output, err := client.GetAutoScalingGroupRecommendations(input)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to retrieve Auto Scaling group recommendations from Compute Optimizer: %w", err)
+		}
+		if err := recommendationErrors(output.Errors); err != nil {
+			return nil, err
+		}
+		for _, asgRecommendation := range output.AutoScalingGroupRecommendations {
+			ranked := map[int64]string{}
+			for _, option := range asgRecommendation.RecommendationOptions {
+				if option.Configuration != nil {
+					ranked[aws.Int64Value(option.Rank)] = aws.StringValue(option.Configuration.InstanceType)
+				}
+			}
+			recommendation := Recommendation{
+				ResourceARN:              aws.StringValue(asgRecommendation.AutoScalingGroupArn),
+				ResourceType:             computeoptimizer.ResourceTypeAutoScalingGroup,
+				Finding:                  aws.StringValue(asgRecommendation.Finding),
+				RecommendedInstanceTypes: byRank(ranked),
+			}
+			if asgRecommendation.CurrentConfiguration != nil {
+				recommendation.CurrentInstanceType = aws.StringValue(asgRecommendation.CurrentConfiguration.InstanceType)
+			}
+			recommendations = append(recommendations, recommendation)
+		}
+		if output.NextToken == nil {
+			return recommendations, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
+
+// recommendationErrors returns an error describing the resources Compute Optimizer could not return recommendations for
+func recommendationErrors(recommendationErrors []*computeoptimizer.GetRecommendationError) error {
+	if len(recommendationErrors) == 0 {
+		return nil
+	}
+	messages := []string{}
+	for _, recommendationError := range recommendationErrors {
+		messages = append(messages, fmt.Sprintf("%s: %s", aws.StringValue(recommendationError.Identifier), aws.StringValue(recommendationError.Message)))
+	}
+	return fmt.Errorf("Compute Optimizer could not return recommendations for %s", strings.Join(messages, ", "))
+}
+
+// byRank returns the instance types ordered by their rank, where rank 1 is the best option
+func byRank(ranked map[int64]string) []string {
+	ranks := []int64{}
+	for rank := range ranked {
+		ranks = append(ranks, rank)
+	}
+	sort.Slice(ranks, func(i, j int) bool { return ranks[i] < ranks[j] })
+	instanceTypes := []string{}
+	for _, rank := range ranks {
+		instanceTypes = append(instanceTypes, ranked[rank])
+	}
+	return instanceTypes
+}
@@ -0,0 +1,159 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package optimizer_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/optimizer"
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/computeoptimizer"
+	"github.com/aws/aws-sdk-go/service/computeoptimizer/computeoptimizeriface"
+)
+
+const (
+	instanceARN = "arn:aws:ec2:us-east-1:123456789012:instance/i-0123456789abcdef0"
+	asgARN      = "arn:aws:autoscaling:us-east-1:123456789012:autoScalingGroup:1234:autoScalingGroupName/web"
+)
+
+// Mocking helpers
+
+type mockedComputeOptimizer struct {
+	computeoptimizeriface.ComputeOptimizerAPI
+	InstancePages     []computeoptimizer.GetEC2InstanceRecommendationsOutput
+	InstanceInputs    []*computeoptimizer.GetEC2InstanceRecommendationsInput
+	AutoScalingGroup  computeoptimizer.GetAutoScalingGroupRecommendationsOutput
+	AutoScalingInputs []*computeoptimizer.GetAutoScalingGroupRecommendationsInput
+	InstanceErr       error
+}
+
+func (m *mockedComputeOptimizer) GetEC2InstanceRecommendations(input *computeoptimizer.GetEC2InstanceRecommendationsInput) (*computeoptimizer.GetEC2InstanceRecommendationsOutput, error) {
+	inputCopy := *input
+	m.InstanceInputs = append(m.InstanceInputs, &inputCopy)
+	if m.InstanceErr != nil {
+		return nil, m.InstanceErr
+	}
+	page := m.InstancePages[len(m.InstanceInputs)-1]
+	return &page, nil
+}
+
+func (m *mockedComputeOptimizer) GetAutoScalingGroupRecommendations(input *computeoptimizer.GetAutoScalingGroupRecommendationsInput) (*computeoptimizer.GetAutoScalingGroupRecommendationsOutput, error) {
+	m.AutoScalingInputs = append(m.AutoScalingInputs, input)
+	return &m.AutoScalingGroup, nil
+}
+
+type mockedInstanceSelector struct {
+	auditResults *selector.AuditResults
+}
+
+func (m mockedInstanceSelector) Audit(filters selector.Filters, candidates []string) (*selector.AuditResults, error) {
+	return m.auditResults, nil
+}
+
+func newComputeOptimizerMock() *mockedComputeOptimizer {
+	return &mockedComputeOptimizer{
+		InstancePages: []computeoptimizer.GetEC2InstanceRecommendationsOutput{
+			{
+				InstanceRecommendations: []*computeoptimizer.InstanceRecommendation{{
+					InstanceArn:         aws.String(instanceARN),
+					CurrentInstanceType: aws.String("m5.2xlarge"),
+					Finding:             aws.String(computeoptimizer.FindingOverprovisioned),
+					RecommendationOptions: []*computeoptimizer.InstanceRecommendationOption{
+						{InstanceType: aws.String("m6g.xlarge"), Rank: aws.Int64(2)},
+						{InstanceType: aws.String("t3.xlarge"), Rank: aws.Int64(3)},
+						{InstanceType: aws.String("r6g.large"), Rank: aws.Int64(1)},
+					},
+				}},
+				NextToken: aws.String("page-2"),
+			},
+			{},
+		},
+		AutoScalingGroup: computeoptimizer.GetAutoScalingGroupRecommendationsOutput{
+			AutoScalingGroupRecommendations: []*computeoptimizer.AutoScalingGroupRecommendation{{
+				AutoScalingGroupArn:  aws.String(asgARN),
+				CurrentConfiguration: &computeoptimizer.AutoScalingGroupConfiguration{InstanceType: aws.String("c5.xlarge")},
+				Finding:              aws.String(computeoptimizer.FindingOptimized),
+				RecommendationOptions: []*computeoptimizer.AutoScalingGroupRecommendationOption{
+					{Configuration: &computeoptimizer.AutoScalingGroupConfiguration{InstanceType: aws.String("c5.xlarge")}, Rank: aws.Int64(1)},
+				},
+			}},
+		},
+	}
+}
+
+// Tests
+
+func TestRecommendations(t *testing.T) {
+	computeOptimizerMock := newComputeOptimizerMock()
+	recommendations, err := optimizer.Recommendations(computeOptimizerMock, nil)
+	h.Ok(t, err)
+	h.Equals(t, 2, len(recommendations))
+	h.Equals(t, []string{"r6g.large", "m6g.xlarge", "t3.xlarge"}, recommendations[0].RecommendedInstanceTypes)
+	h.Equals(t, computeoptimizer.ResourceTypeEc2instance, recommendations[0].ResourceType)
+	h.Equals(t, "m5.2xlarge", recommendations[0].CurrentInstanceType)
+	h.Equals(t, computeoptimizer.ResourceTypeAutoScalingGroup, recommendations[1].ResourceType)
+	h.Equals(t, "c5.xlarge", recommendations[1].CurrentInstanceType)
+	h.Equals(t, []string{"c5.xlarge"}, recommendations[1].RecommendedInstanceTypes)
+	h.Equals(t, 2, len(computeOptimizerMock.InstanceInputs))
+	h.Equals(t, "page-2", aws.StringValue(computeOptimizerMock.InstanceInputs[1].NextToken))
+}
+
+func TestRecommendations_ARNs(t *testing.T) {
+	computeOptimizerMock := newComputeOptimizerMock()
+	_, err := optimizer.Recommendations(computeOptimizerMock, []string{asgARN})
+	h.Ok(t, err)
+	h.Equals(t, 0, len(computeOptimizerMock.InstanceInputs))
+	h.Equals(t, []string{asgARN}, aws.StringValueSlice(computeOptimizerMock.AutoScalingInputs[0].AutoScalingGroupArns))
+
+	computeOptimizerMock = newComputeOptimizerMock()
+	_, err = optimizer.Recommendations(computeOptimizerMock, []string{instanceARN})
+	h.Ok(t, err)
+	h.Equals(t, 0, len(computeOptimizerMock.AutoScalingInputs))
+	h.Equals(t, []string{instanceARN}, aws.StringValueSlice(computeOptimizerMock.InstanceInputs[0].InstanceArns))
+}
+
+func TestRecommendations_Failure(t *testing.T) {
+	computeOptimizerMock := newComputeOptimizerMock()
+	computeOptimizerMock.InstanceErr = errors.New("OptInRequiredException")
+	_, err := optimizer.Recommendations(computeOptimizerMock, nil)
+	h.Nok(t, err)
+
+	computeOptimizerMock = newComputeOptimizerMock()
+	computeOptimizerMock.InstancePages[0].Errors = []*computeoptimizer.GetRecommendationError{{
+		Identifier: aws.String(instanceARN),
+		Message:    aws.String("not found"),
+	}}
+	_, err = optimizer.Recommendations(computeOptimizerMock, []string{instanceARN})
+	h.Nok(t, err)
+}
+
+func TestIntersect(t *testing.T) {
+	instanceSelector := mockedInstanceSelector{auditResults: &selector.AuditResults{
+		Passed: []string{"m6g.xlarge", "r6g.large"},
+		Failed: map[string][]selector.FilterRejection{
+			"t3.xlarge": {{Filter: "burstable", FilterValue: "false", InstanceTypeValue: "true"}},
+		},
+	}}
+	recommendations, err := optimizer.Intersect(instanceSelector, selector.Filters{Burstable: aws.Bool(false)}, []optimizer.Recommendation{{
+		ResourceARN:              instanceARN,
+		RecommendedInstanceTypes: []string{"r6g.large", "m6g.xlarge", "t3.xlarge"},
+	}})
+	h.Ok(t, err)
+	// the allowed instance types keep the order Compute Optimizer ranked them in
+	h.Equals(t, []string{"r6g.large", "m6g.xlarge"}, recommendations[0].AllowedInstanceTypes)
+	h.Equals(t, "burstable", recommendations[0].Rejections["t3.xlarge"][0].Filter)
+}
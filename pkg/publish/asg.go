@@ -0,0 +1,172 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package publish
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
+)
+
+// AutoScalingGroupChange is a planned rewrite of the instance type overrides of an Auto Scaling group's MixedInstancesPolicy
+type AutoScalingGroupChange struct {
+	// Name is the name of the Auto Scaling group
+	Name string
+	// Current are the instance types in the current overrides
+	Current []string
+	// Desired are the instance types the overrides will be rewritten with
+	Desired []string
+	// Added are the desired instance types which are not in the current overrides
+	Added []string
+	// Removed are the current instance types which are not desired
+	Removed []string
+
+	launchTemplate *autoscaling.LaunchTemplateSpecification
+}
+
+// HasChanges returns true if applying the change would add or remove instance types
+func (c AutoScalingGroupChange) HasChanges() bool {
+	return len(c.Added) != 0 || len(c.Removed) != 0
+}
+
+// PlanAutoScalingGroupUpdate compares the instance type overrides of the Auto Scaling group's MixedInstancesPolicy to the instance types
+// without changing the group. Groups without a MixedInstancesPolicy and groups whose overrides use weighted capacity, attribute-based
+// instance requirements, or per instance type launch templates are rejected since their overrides cannot be rewritten from a list
+// of instance types alone.
+func PlanAutoScalingGroupUpdate(asgClient autoscalingiface.AutoScalingAPI, name string, instanceTypes []string) (*AutoScalingGroupChange, error) {
+	if len(instanceTypes) == 0 {
+		return nil, fmt.Errorf("Unable to rewrite the overrides of Auto Scaling group %s without any instance types", name)
+	}
+	asg, err := describeAutoScalingGroup(asgClient, name)
+	if err != nil {
+		return nil, err
+	}
+	if asg.MixedInstancesPolicy == nil || asg.MixedInstancesPolicy.LaunchTemplate == nil {
+		return nil, fmt.Errorf("The Auto Scaling group %s does not use a MixedInstancesPolicy", name)
+	}
+	current := []string{}
+	for _, override := range asg.MixedInstancesPolicy.LaunchTemplate.Overrides {
+		if override.WeightedCapacity != nil {
+			return nil, fmt.Errorf("The Auto Scaling group %s uses weighted capacity which cannot be rewritten from a list of instance types", name)
+		}
+		if override.InstanceRequirements != nil {
+			return nil, fmt.Errorf("The Auto Scaling group %s uses instance requirements which cannot be rewritten from a list of instance types", name)
+		}
+		if override.LaunchTemplateSpecification != nil {
+			return nil, fmt.Errorf("The Auto Scaling group %s uses a launch template per instance type which cannot be rewritten from a list of instance types", name)
+		}
+		current = append(current, aws.StringValue(override.InstanceType))
+	}
+	return &AutoScalingGroupChange{
+		Name:           name,
+		Current:        current,
+		Desired:        instanceTypes,
+		Added:          difference(instanceTypes, current),
+		Removed:        difference(current, instanceTypes),
+		launchTemplate: asg.MixedInstancesPolicy.LaunchTemplate.LaunchTemplateSpecification,
+	}, nil
+}
+
+// AutoScalingGroupInstanceTypes returns the instance types an Auto Scaling group launches: the overrides of its MixedInstancesPolicy
+// or the instance type of its launch configuration. Groups which launch the instance type of a launch template without overrides
+// return the launch template instead so that the caller can resolve its instance type.
+func AutoScalingGroupInstanceTypes(asgClient autoscalingiface.AutoScalingAPI, name string) ([]string, *autoscaling.LaunchTemplateSpecification, error) {
+	asg, err := describeAutoScalingGroup(asgClient, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if asg.MixedInstancesPolicy != nil && asg.MixedInstancesPolicy.LaunchTemplate != nil {
+		instanceTypes := []string{}
+		for _, override := range asg.MixedInstancesPolicy.LaunchTemplate.Overrides {
+			if override.InstanceType != nil {
+				instanceTypes = append(instanceTypes, *override.InstanceType)
+			}
+		}
+		if len(instanceTypes) != 0 {
+			return instanceTypes, nil, nil
+		}
+		return nil, asg.MixedInstancesPolicy.LaunchTemplate.LaunchTemplateSpecification, nil
+	}
+	if asg.LaunchTemplate != nil {
+		return nil, asg.LaunchTemplate, nil
+	}
+	if asg.LaunchConfigurationName == nil {
+		return nil, nil, fmt.Errorf("The Auto Scaling group %s has no launch template or launch configuration", name)
+	}
+	output, err := asgClient.DescribeLaunchConfigurations(&autoscaling.DescribeLaunchConfigurationsInput{
+		LaunchConfigurationNames: []*string{asg.LaunchConfigurationName},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to describe launch configuration %s: %w", *asg.LaunchConfigurationName, err)
+	}
+	if len(output.LaunchConfigurations) == 0 {
+		return nil, nil, fmt.Errorf("The launch configuration %s does not exist", *asg.LaunchConfigurationName)
+	}
+	return []string{aws.StringValue(output.LaunchConfigurations[0].InstanceType)}, nil, nil
+}
+
+// UpdateAutoScalingGroup rewrites the instance type overrides of the Auto Scaling group planned by PlanAutoScalingGroupUpdate.
+// The launch template and instances distribution of the group are not changed. Running instances are not replaced.
+func UpdateAutoScalingGroup(asgClient autoscalingiface.AutoScalingAPI, change *AutoScalingGroupChange) error {
+	overrides := []*autoscaling.LaunchTemplateOverrides{}
+	for _, instanceType := range change.Desired {
+		overrides = append(overrides, &autoscaling.LaunchTemplateOverrides{InstanceType: aws.String(instanceType)})
+	}
+	_, err := asgClient.UpdateAutoScalingGroup(&autoscaling.UpdateAutoScalingGroupInput{
+		AutoScalingGroupName: aws.String(change.Name),
+		MixedInstancesPolicy: &autoscaling.MixedInstancesPolicy{
+			LaunchTemplate: &autoscaling.LaunchTemplate{
+				LaunchTemplateSpecification: change.launchTemplate,
+				Overrides:                   overrides,
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("Unable to update Auto Scaling group %s: %w", change.Name, err)
+	}
+	return nil
+}
+
+// describeAutoScalingGroup returns the Auto Scaling group with the name or an error if it does not exist
+func describeAutoScalingGroup(asgClient autoscalingiface.AutoScalingAPI, name string) (*autoscaling.Group, error) {
+	output, err := asgClient.DescribeAutoScalingGroups(&autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: aws.StringSlice([]string{name}),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Unable to describe Auto Scaling group %s: %w", name, err)
+	}
+	if len(output.AutoScalingGroups) == 0 {
+		return nil, fmt.Errorf("The Auto Scaling group %s does not exist", name)
+	}
+	return output.AutoScalingGroups[0], nil
+}
+
+// difference returns the sorted values of a which are not in b
+func difference(a []string, b []string) []string {
+	inB := map[string]bool{}
+	for _, value := range b {
+		inB[value] = true
+	}
+	diff := []string{}
+	for _, value := range a {
+		if !inB[value] {
+			diff = append(diff, value)
+		}
+	}
+	sort.Strings(diff)
+	return diff
+}
@@ -0,0 +1,210 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package publish_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/publish"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
+)
+
+// Mocking helpers
+
+type mockedAutoScaling struct {
+	autoscalingiface.AutoScalingAPI
+	DescribeAutoScalingGroupsResp    autoscaling.DescribeAutoScalingGroupsOutput
+	DescribeAutoScalingGroupsErr     error
+	UpdateAutoScalingGroupInput      *autoscaling.UpdateAutoScalingGroupInput
+	UpdateAutoScalingGroupErr        error
+	DescribeLaunchConfigurationsResp autoscaling.DescribeLaunchConfigurationsOutput
+	DescribeLaunchConfigurationsErr  error
+}
+
+func (m *mockedAutoScaling) DescribeAutoScalingGroups(input *autoscaling.DescribeAutoScalingGroupsInput) (*autoscaling.DescribeAutoScalingGroupsOutput, error) {
+	return &m.DescribeAutoScalingGroupsResp, m.DescribeAutoScalingGroupsErr
+}
+
+func (m *mockedAutoScaling) UpdateAutoScalingGroup(input *autoscaling.UpdateAutoScalingGroupInput) (*autoscaling.UpdateAutoScalingGroupOutput, error) {
+	m.UpdateAutoScalingGroupInput = input
+	return &autoscaling.UpdateAutoScalingGroupOutput{}, m.UpdateAutoScalingGroupErr
+}
+
+func (m *mockedAutoScaling) DescribeLaunchConfigurations(input *autoscaling.DescribeLaunchConfigurationsInput) (*autoscaling.DescribeLaunchConfigurationsOutput, error) {
+	return &m.DescribeLaunchConfigurationsResp, m.DescribeLaunchConfigurationsErr
+}
+
+func newMixedInstancesGroup(name string, overrides ...*autoscaling.LaunchTemplateOverrides) autoscaling.DescribeAutoScalingGroupsOutput {
+	return autoscaling.DescribeAutoScalingGroupsOutput{
+		AutoScalingGroups: []*autoscaling.Group{{
+			AutoScalingGroupName: aws.String(name),
+			MixedInstancesPolicy: &autoscaling.MixedInstancesPolicy{
+				LaunchTemplate: &autoscaling.LaunchTemplate{
+					LaunchTemplateSpecification: &autoscaling.LaunchTemplateSpecification{
+						LaunchTemplateId: aws.String("lt-0123456789abcdef0"),
+						Version:          aws.String("$Latest"),
+					},
+					Overrides: overrides,
+				},
+			},
+		}},
+	}
+}
+
+// Tests
+
+func TestPlanAutoScalingGroupUpdate(t *testing.T) {
+	asgMock := &mockedAutoScaling{
+		DescribeAutoScalingGroupsResp: newMixedInstancesGroup("web",
+			&autoscaling.LaunchTemplateOverrides{InstanceType: aws.String("m4.large")},
+			&autoscaling.LaunchTemplateOverrides{InstanceType: aws.String("m5.large")},
+		),
+	}
+	change, err := publish.PlanAutoScalingGroupUpdate(asgMock, "web", []string{"m5.large", "m5a.large"})
+	h.Ok(t, err)
+	h.Equals(t, []string{"m4.large", "m5.large"}, change.Current)
+	h.Equals(t, []string{"m5a.large"}, change.Added)
+	h.Equals(t, []string{"m4.large"}, change.Removed)
+	h.Assert(t, change.HasChanges(), "The change should add and remove instance types")
+	h.Assert(t, asgMock.UpdateAutoScalingGroupInput == nil, "Planning should not update the Auto Scaling group")
+
+	h.Ok(t, publish.UpdateAutoScalingGroup(asgMock, change))
+	update := asgMock.UpdateAutoScalingGroupInput
+	h.Equals(t, "web", aws.StringValue(update.AutoScalingGroupName))
+	h.Equals(t, "lt-0123456789abcdef0", aws.StringValue(update.MixedInstancesPolicy.LaunchTemplate.LaunchTemplateSpecification.LaunchTemplateId))
+	h.Equals(t, 2, len(update.MixedInstancesPolicy.LaunchTemplate.Overrides))
+	h.Equals(t, "m5.large", aws.StringValue(update.MixedInstancesPolicy.LaunchTemplate.Overrides[0].InstanceType))
+	h.Equals(t, "m5a.large", aws.StringValue(update.MixedInstancesPolicy.LaunchTemplate.Overrides[1].InstanceType))
+}
+
+func TestPlanAutoScalingGroupUpdate_NoChanges(t *testing.T) {
+	asgMock := &mockedAutoScaling{
+		DescribeAutoScalingGroupsResp: newMixedInstancesGroup("web", &autoscaling.LaunchTemplateOverrides{InstanceType: aws.String("m5.large")}),
+	}
+	change, err := publish.PlanAutoScalingGroupUpdate(asgMock, "web", []string{"m5.large"})
+	h.Ok(t, err)
+	h.Assert(t, !change.HasChanges(), "The change should not add or remove instance types")
+}
+
+func TestPlanAutoScalingGroupUpdate_NotFound(t *testing.T) {
+	asgMock := &mockedAutoScaling{}
+	_, err := publish.PlanAutoScalingGroupUpdate(asgMock, "web", []string{"m5.large"})
+	h.Nok(t, err)
+}
+
+func TestPlanAutoScalingGroupUpdate_NoMixedInstancesPolicy(t *testing.T) {
+	asgMock := &mockedAutoScaling{
+		DescribeAutoScalingGroupsResp: autoscaling.DescribeAutoScalingGroupsOutput{
+			AutoScalingGroups: []*autoscaling.Group{{AutoScalingGroupName: aws.String("web")}},
+		},
+	}
+	_, err := publish.PlanAutoScalingGroupUpdate(asgMock, "web", []string{"m5.large"})
+	h.Nok(t, err)
+}
+
+func TestPlanAutoScalingGroupUpdate_WeightedCapacity(t *testing.T) {
+	asgMock := &mockedAutoScaling{
+		DescribeAutoScalingGroupsResp: newMixedInstancesGroup("web", &autoscaling.LaunchTemplateOverrides{
+			InstanceType:     aws.String("m5.large"),
+			WeightedCapacity: aws.String("2"),
+		}),
+	}
+	_, err := publish.PlanAutoScalingGroupUpdate(asgMock, "web", []string{"m5.large"})
+	h.Nok(t, err)
+}
+
+func TestPlanAutoScalingGroupUpdate_InstanceRequirements(t *testing.T) {
+	asgMock := &mockedAutoScaling{
+		DescribeAutoScalingGroupsResp: newMixedInstancesGroup("web", &autoscaling.LaunchTemplateOverrides{
+			InstanceRequirements: &autoscaling.InstanceRequirements{
+				VCpuCount: &autoscaling.VCpuCountRequest{Min: aws.Int64(2)},
+				MemoryMiB: &autoscaling.MemoryMiBRequest{Min: aws.Int64(4096)},
+			},
+		}),
+	}
+	_, err := publish.PlanAutoScalingGroupUpdate(asgMock, "web", []string{"m5.large"})
+	h.Nok(t, err)
+}
+
+func TestPlanAutoScalingGroupUpdate_LaunchTemplateOverride(t *testing.T) {
+	asgMock := &mockedAutoScaling{
+		DescribeAutoScalingGroupsResp: newMixedInstancesGroup("web",
+			&autoscaling.LaunchTemplateOverrides{InstanceType: aws.String("m5.large")},
+			&autoscaling.LaunchTemplateOverrides{
+				InstanceType:                aws.String("m6g.large"),
+				LaunchTemplateSpecification: &autoscaling.LaunchTemplateSpecification{LaunchTemplateName: aws.String("web-arm64")},
+			},
+		),
+	}
+	_, err := publish.PlanAutoScalingGroupUpdate(asgMock, "web", []string{"m5.large"})
+	h.Nok(t, err)
+}
+
+func TestUpdateAutoScalingGroup_Failure(t *testing.T) {
+	asgMock := &mockedAutoScaling{
+		DescribeAutoScalingGroupsResp: newMixedInstancesGroup("web", &autoscaling.LaunchTemplateOverrides{InstanceType: aws.String("m5.large")}),
+		UpdateAutoScalingGroupErr:     errors.New("AccessDenied"),
+	}
+	change, err := publish.PlanAutoScalingGroupUpdate(asgMock, "web", []string{"m5a.large"})
+	h.Ok(t, err)
+	h.Nok(t, publish.UpdateAutoScalingGroup(asgMock, change))
+}
+
+func TestAutoScalingGroupInstanceTypes(t *testing.T) {
+	asgMock := &mockedAutoScaling{
+		DescribeAutoScalingGroupsResp: newMixedInstancesGroup("web",
+			&autoscaling.LaunchTemplateOverrides{InstanceType: aws.String("m4.large")},
+			&autoscaling.LaunchTemplateOverrides{InstanceType: aws.String("m5.large")},
+		),
+	}
+	instanceTypes, launchTemplate, err := publish.AutoScalingGroupInstanceTypes(asgMock, "web")
+	h.Ok(t, err)
+	h.Equals(t, []string{"m4.large", "m5.large"}, instanceTypes)
+	h.Assert(t, launchTemplate == nil, "The launch template should not be returned when the group has overrides")
+}
+
+func TestAutoScalingGroupInstanceTypes_LaunchTemplate(t *testing.T) {
+	asgMock := &mockedAutoScaling{DescribeAutoScalingGroupsResp: newMixedInstancesGroup("web")}
+	instanceTypes, launchTemplate, err := publish.AutoScalingGroupInstanceTypes(asgMock, "web")
+	h.Ok(t, err)
+	h.Assert(t, instanceTypes == nil, "No instance types should be returned when the group has no overrides")
+	h.Equals(t, "lt-0123456789abcdef0", *launchTemplate.LaunchTemplateId)
+}
+
+func TestAutoScalingGroupInstanceTypes_LaunchConfiguration(t *testing.T) {
+	asgMock := &mockedAutoScaling{
+		DescribeAutoScalingGroupsResp: autoscaling.DescribeAutoScalingGroupsOutput{
+			AutoScalingGroups: []*autoscaling.Group{{AutoScalingGroupName: aws.String("web"), LaunchConfigurationName: aws.String("web-lc")}},
+		},
+		DescribeLaunchConfigurationsResp: autoscaling.DescribeLaunchConfigurationsOutput{
+			LaunchConfigurations: []*autoscaling.LaunchConfiguration{{InstanceType: aws.String("c5.xlarge")}},
+		},
+	}
+	instanceTypes, _, err := publish.AutoScalingGroupInstanceTypes(asgMock, "web")
+	h.Ok(t, err)
+	h.Equals(t, []string{"c5.xlarge"}, instanceTypes)
+
+	asgMock.DescribeLaunchConfigurationsErr = errors.New("error")
+	_, _, err = publish.AutoScalingGroupInstanceTypes(asgMock, "web")
+	h.Nok(t, err)
+}
+
+func TestAutoScalingGroupInstanceTypes_NotFound(t *testing.T) {
+	_, _, err := publish.AutoScalingGroupInstanceTypes(&mockedAutoScaling{}, "web")
+	h.Nok(t, err)
+}
@@ -0,0 +1,114 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package publish
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/eks/eksiface"
+)
+
+// Nodegroup is the EKS managed nodegroup created by EKSNodegroup
+type Nodegroup struct {
+	// ClusterName is the name of the EKS cluster the nodegroup joins
+	ClusterName string
+	// NodegroupName is the name of the nodegroup
+	NodegroupName string
+	// CapacityType is ON_DEMAND or SPOT
+	CapacityType string
+	// NodeRole is the ARN of the IAM role the nodes use
+	NodeRole string
+	// Subnets are the subnets the nodes are launched in. The subnets of the cluster are used when none are set.
+	Subnets []string
+}
+
+// EKSCapacityTypes returns the capacity types supported by EKSNodegroup
+func EKSCapacityTypes() []string {
+	return eks.CapacityTypes_Values()
+}
+
+// EKSNodegroup creates the EKS managed nodegroup with the instance types and returns the status of the new nodegroup.
+// The AMI type is chosen from the architecture and GPUs of the instance types, which must all run the same AMI type.
+func EKSNodegroup(eksClient eksiface.EKSAPI, nodegroup Nodegroup, instanceTypeInfoSlice []*ec2.InstanceTypeInfo) (string, error) {
+	if len(instanceTypeInfoSlice) == 0 {
+		return "", fmt.Errorf("Unable to create nodegroup %s without any instance types", nodegroup.NodegroupName)
+	}
+	amiType, err := nodegroupAMIType(instanceTypeInfoSlice)
+	if err != nil {
+		return "", err
+	}
+	subnets := nodegroup.Subnets
+	if len(subnets) == 0 {
+		cluster, err := eksClient.DescribeCluster(&eks.DescribeClusterInput{Name: aws.String(nodegroup.ClusterName)})
+		if err != nil {
+			return "", fmt.Errorf("Unable to describe EKS cluster %s: %w", nodegroup.ClusterName, err)
+		}
+		if cluster.Cluster.ResourcesVpcConfig != nil {
+			subnets = aws.StringValueSlice(cluster.Cluster.ResourcesVpcConfig.SubnetIds)
+		}
+	}
+	instanceTypes := []string{}
+	for _, instanceTypeInfo := range instanceTypeInfoSlice {
+		instanceTypes = append(instanceTypes, aws.StringValue(instanceTypeInfo.InstanceType))
+	}
+	output, err := eksClient.CreateNodegroup(&eks.CreateNodegroupInput{
+		ClusterName:   aws.String(nodegroup.ClusterName),
+		NodegroupName: aws.String(nodegroup.NodegroupName),
+		CapacityType:  aws.String(nodegroup.CapacityType),
+		NodeRole:      aws.String(nodegroup.NodeRole),
+		Subnets:       aws.StringSlice(subnets),
+		InstanceTypes: aws.StringSlice(instanceTypes),
+		AmiType:       aws.String(amiType),
+	})
+	if err != nil {
+		return "", fmt.Errorf("Unable to create nodegroup %s in EKS cluster %s: %w", nodegroup.NodegroupName, nodegroup.ClusterName, err)
+	}
+	return aws.StringValue(output.Nodegroup.Status), nil
+}
+
+// nodegroupAMIType returns the EKS optimized Amazon Linux 2 AMI type which runs on all of the instance types
+func nodegroupAMIType(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) (string, error) {
+	amiTypes := map[string][]string{}
+	for _, instanceTypeInfo := range instanceTypeInfoSlice {
+		amiType := eks.AMITypesAl2X8664
+		if instanceTypeInfo.GpuInfo != nil && len(instanceTypeInfo.GpuInfo.Gpus) > 0 {
+			amiType = eks.AMITypesAl2X8664Gpu
+		}
+		if instanceTypeInfo.ProcessorInfo != nil {
+			for _, architecture := range instanceTypeInfo.ProcessorInfo.SupportedArchitectures {
+				if aws.StringValue(architecture) == ec2.ArchitectureTypeArm64 {
+					amiType = eks.AMITypesAl2Arm64
+				}
+			}
+		}
+		amiTypes[amiType] = append(amiTypes[amiType], aws.StringValue(instanceTypeInfo.InstanceType))
+	}
+	if len(amiTypes) > 1 {
+		mixed := []string{}
+		for _, amiType := range eks.AMITypes_Values() {
+			if instanceTypes, ok := amiTypes[amiType]; ok {
+				mixed = append(mixed, fmt.Sprintf("%s (%s)", amiType, strings.Join(instanceTypes, ", ")))
+			}
+		}
+		return "", fmt.Errorf("A nodegroup can only use one AMI type but the instance types need: %s", strings.Join(mixed, ", "))
+	}
+	for amiType := range amiTypes {
+		return amiType, nil
+	}
+	return "", nil
+}
@@ -0,0 +1,118 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package publish_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/publish"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/eks/eksiface"
+)
+
+// Mocking helpers
+
+type mockedEKS struct {
+	eksiface.EKSAPI
+	DescribeClusterResp  eks.DescribeClusterOutput
+	CreateNodegroupInput *eks.CreateNodegroupInput
+	CreateNodegroupErr   error
+}
+
+func (m *mockedEKS) DescribeCluster(input *eks.DescribeClusterInput) (*eks.DescribeClusterOutput, error) {
+	return &m.DescribeClusterResp, nil
+}
+
+func (m *mockedEKS) CreateNodegroup(input *eks.CreateNodegroupInput) (*eks.CreateNodegroupOutput, error) {
+	m.CreateNodegroupInput = input
+	return &eks.CreateNodegroupOutput{Nodegroup: &eks.Nodegroup{Status: aws.String(eks.NodegroupStatusCreating)}}, m.CreateNodegroupErr
+}
+
+func newNodegroupInstanceTypeInfo(instanceType string, architecture string, gpus int64) *ec2.InstanceTypeInfo {
+	instanceTypeInfo := &ec2.InstanceTypeInfo{
+		InstanceType:  aws.String(instanceType),
+		ProcessorInfo: &ec2.ProcessorInfo{SupportedArchitectures: aws.StringSlice([]string{architecture})},
+	}
+	if gpus > 0 {
+		instanceTypeInfo.GpuInfo = &ec2.GpuInfo{Gpus: []*ec2.GpuDeviceInfo{{Count: aws.Int64(gpus)}}}
+	}
+	return instanceTypeInfo
+}
+
+var testNodegroup = publish.Nodegroup{
+	ClusterName:   "prod",
+	NodegroupName: "spot-workers",
+	CapacityType:  eks.CapacityTypesSpot,
+	NodeRole:      "arn:aws:iam::123456789012:role/eks-node",
+}
+
+// Tests
+
+func TestEKSNodegroup(t *testing.T) {
+	eksMock := &mockedEKS{
+		DescribeClusterResp: eks.DescribeClusterOutput{Cluster: &eks.Cluster{
+			ResourcesVpcConfig: &eks.VpcConfigResponse{SubnetIds: aws.StringSlice([]string{"subnet-1", "subnet-2"})},
+		}},
+	}
+	status, err := publish.EKSNodegroup(eksMock, testNodegroup, []*ec2.InstanceTypeInfo{
+		newNodegroupInstanceTypeInfo("m5.large", ec2.ArchitectureTypeX8664, 0),
+		newNodegroupInstanceTypeInfo("m5a.large", ec2.ArchitectureTypeX8664, 0),
+	})
+	h.Ok(t, err)
+	h.Equals(t, eks.NodegroupStatusCreating, status)
+	input := eksMock.CreateNodegroupInput
+	h.Equals(t, "prod", aws.StringValue(input.ClusterName))
+	h.Equals(t, "spot-workers", aws.StringValue(input.NodegroupName))
+	h.Equals(t, eks.CapacityTypesSpot, aws.StringValue(input.CapacityType))
+	h.Equals(t, eks.AMITypesAl2X8664, aws.StringValue(input.AmiType))
+	h.Equals(t, []string{"m5.large", "m5a.large"}, aws.StringValueSlice(input.InstanceTypes))
+	h.Equals(t, []string{"subnet-1", "subnet-2"}, aws.StringValueSlice(input.Subnets))
+}
+
+func TestEKSNodegroup_AMIType(t *testing.T) {
+	eksMock := &mockedEKS{}
+	nodegroup := testNodegroup
+	nodegroup.Subnets = []string{"subnet-3"}
+
+	_, err := publish.EKSNodegroup(eksMock, nodegroup, []*ec2.InstanceTypeInfo{newNodegroupInstanceTypeInfo("m6g.large", ec2.ArchitectureTypeArm64, 0)})
+	h.Ok(t, err)
+	h.Equals(t, eks.AMITypesAl2Arm64, aws.StringValue(eksMock.CreateNodegroupInput.AmiType))
+	h.Equals(t, []string{"subnet-3"}, aws.StringValueSlice(eksMock.CreateNodegroupInput.Subnets))
+
+	_, err = publish.EKSNodegroup(eksMock, nodegroup, []*ec2.InstanceTypeInfo{newNodegroupInstanceTypeInfo("g4dn.xlarge", ec2.ArchitectureTypeX8664, 1)})
+	h.Ok(t, err)
+	h.Equals(t, eks.AMITypesAl2X8664Gpu, aws.StringValue(eksMock.CreateNodegroupInput.AmiType))
+}
+
+func TestEKSNodegroup_MixedAMITypes(t *testing.T) {
+	eksMock := &mockedEKS{}
+	_, err := publish.EKSNodegroup(eksMock, testNodegroup, []*ec2.InstanceTypeInfo{
+		newNodegroupInstanceTypeInfo("m5.large", ec2.ArchitectureTypeX8664, 0),
+		newNodegroupInstanceTypeInfo("m6g.large", ec2.ArchitectureTypeArm64, 0),
+	})
+	h.Nok(t, err)
+	h.Assert(t, eksMock.CreateNodegroupInput == nil, "The nodegroup should not be created")
+}
+
+func TestEKSNodegroup_Failure(t *testing.T) {
+	eksMock := &mockedEKS{CreateNodegroupErr: errors.New("ResourceInUseException")}
+	nodegroup := testNodegroup
+	nodegroup.Subnets = []string{"subnet-3"}
+	_, err := publish.EKSNodegroup(eksMock, nodegroup, []*ec2.InstanceTypeInfo{newNodegroupInstanceTypeInfo("m5.large", ec2.ArchitectureTypeX8664, 0)})
+	h.Nok(t, err)
+}
@@ -0,0 +1,75 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package publish writes instance type selections to AWS services so that downstream automation can consume them
+package publish
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// SSM parameter formats which can be passed to SSMParameter
+const (
+	// SSMFormatStringList writes a StringList parameter of comma-separated instance types
+	// which CloudFormation can consume as an AWS::SSM::Parameter::Value<List<String>> parameter
+	SSMFormatStringList = "string-list"
+	// SSMFormatJSON writes a String parameter holding a JSON array of instance types
+	SSMFormatJSON = "json"
+)
+
+// ssmDescription is the description of the parameters written by SSMParameter
+const ssmDescription = "Instance types selected by ec2-instance-selector"
+
+// SSMFormats returns the SSM parameter formats supported by SSMParameter
+func SSMFormats() []string {
+	return []string{SSMFormatStringList, SSMFormatJSON}
+}
+
+// SSMParameter creates or overwrites the SSM parameter with the instance types in the format and returns the new parameter version.
+// The Intelligent-Tiering tier is used so that long lists which exceed the size of a standard parameter are still written.
+func SSMParameter(ssmClient ssmiface.SSMAPI, name string, instanceTypes []string, format string) (int64, error) {
+	input := &ssm.PutParameterInput{
+		Name:        aws.String(name),
+		Description: aws.String(ssmDescription),
+		Overwrite:   aws.Bool(true),
+		Tier:        aws.String(ssm.ParameterTierIntelligentTiering),
+	}
+	switch format {
+	case SSMFormatStringList:
+		if len(instanceTypes) == 0 {
+			return 0, fmt.Errorf("Unable to write an empty %s parameter %s", ssm.ParameterTypeStringList, name)
+		}
+		input.Type = aws.String(ssm.ParameterTypeStringList)
+		input.Value = aws.String(strings.Join(instanceTypes, ","))
+	case SSMFormatJSON:
+		instanceTypesJSON, err := json.Marshal(instanceTypes)
+		if err != nil {
+			return 0, err
+		}
+		input.Type = aws.String(ssm.ParameterTypeString)
+		input.Value = aws.String(string(instanceTypesJSON))
+	default:
+		return 0, fmt.Errorf("The SSM parameter format %s does not exist. Valid formats are: %s", format, strings.Join(SSMFormats(), ", "))
+	}
+	output, err := ssmClient.PutParameter(input)
+	if err != nil {
+		return 0, fmt.Errorf("Unable to write SSM parameter %s: %w", name, err)
+	}
+	return aws.Int64Value(output.Version), nil
+}
@@ -0,0 +1,68 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package publish_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/publish"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// Mocking helpers
+
+type mockedSSM struct {
+	ssmiface.SSMAPI
+	PutParameterInput *ssm.PutParameterInput
+	PutParameterErr   error
+}
+
+func (m *mockedSSM) PutParameter(input *ssm.PutParameterInput) (*ssm.PutParameterOutput, error) {
+	m.PutParameterInput = input
+	return &ssm.PutParameterOutput{Version: aws.Int64(3)}, m.PutParameterErr
+}
+
+// Tests
+
+func TestSSMParameter_StringList(t *testing.T) {
+	ssmMock := &mockedSSM{}
+	version, err := publish.SSMParameter(ssmMock, "/ec2/instance-types", []string{"m5.large", "m5a.large"}, publish.SSMFormatStringList)
+	h.Ok(t, err)
+	h.Equals(t, int64(3), version)
+	h.Equals(t, "/ec2/instance-types", aws.StringValue(ssmMock.PutParameterInput.Name))
+	h.Equals(t, ssm.ParameterTypeStringList, aws.StringValue(ssmMock.PutParameterInput.Type))
+	h.Equals(t, "m5.large,m5a.large", aws.StringValue(ssmMock.PutParameterInput.Value))
+	h.Assert(t, aws.BoolValue(ssmMock.PutParameterInput.Overwrite), "The parameter should be overwritten")
+}
+
+func TestSSMParameter_JSON(t *testing.T) {
+	ssmMock := &mockedSSM{}
+	_, err := publish.SSMParameter(ssmMock, "/ec2/instance-types", []string{"m5.large", "m5a.large"}, publish.SSMFormatJSON)
+	h.Ok(t, err)
+	h.Equals(t, ssm.ParameterTypeString, aws.StringValue(ssmMock.PutParameterInput.Type))
+	h.Equals(t, `["m5.large","m5a.large"]`, aws.StringValue(ssmMock.PutParameterInput.Value))
+}
+
+func TestSSMParameter_Errors(t *testing.T) {
+	_, err := publish.SSMParameter(&mockedSSM{}, "/ec2/instance-types", []string{}, publish.SSMFormatStringList)
+	h.Nok(t, err)
+	_, err = publish.SSMParameter(&mockedSSM{}, "/ec2/instance-types", []string{"m5.large"}, "xml")
+	h.Nok(t, err)
+	_, err = publish.SSMParameter(&mockedSSM{PutParameterErr: errors.New("AccessDenied")}, "/ec2/instance-types", []string{"m5.large"}, publish.SSMFormatJSON)
+	h.Nok(t, err)
+}
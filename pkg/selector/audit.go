@@ -0,0 +1,92 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// Audit accepts a Filters struct and an explicit list of candidate instance types and applies the filters only to
+// the candidates. This is useful for checking an existing list of instance types, like an ASG override list, against new criteria.
+// MaxResults is ignored so that every candidate is reported.
+func (itf Selector) Audit(filters Filters, candidates []string) (*AuditResults, error) {
+	filters, instanceTypeInfoSlice, locationInstanceOfferings, err := itf.prepareFilters(filters)
+	if err != nil {
+		return nil, err
+	}
+	candidateSet := map[string]bool{}
+	for _, candidate := range candidates {
+		candidateSet[candidate] = true
+	}
+	candidateInstanceTypes := []*ec2.InstanceTypeInfo{}
+	for _, instanceTypeInfo := range instanceTypeInfoSlice {
+		if candidateSet[*instanceTypeInfo.InstanceType] {
+			candidateInstanceTypes = append(candidateInstanceTypes, instanceTypeInfo)
+			delete(candidateSet, *instanceTypeInfo.InstanceType)
+		}
+	}
+	matchingInstanceTypes, rejections, err := itf.filterInstanceTypes(filters, candidateInstanceTypes, locationInstanceOfferings)
+	if err != nil {
+		return nil, err
+	}
+	auditResults := &AuditResults{
+		Passed:   []string{},
+		Failed:   rejections,
+		NotFound: []string{},
+	}
+	for _, instanceTypeInfo := range matchingInstanceTypes {
+		auditResults.Passed = append(auditResults.Passed, *instanceTypeInfo.InstanceType)
+	}
+	for candidate := range candidateSet {
+		auditResults.NotFound = append(auditResults.NotFound, candidate)
+	}
+	sort.Strings(auditResults.NotFound)
+	return auditResults, nil
+}
+
+// LoadCandidatesFile reads a file of candidate instance types for Audit
+func LoadCandidatesFile(path string) ([]string, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read candidates file %s: %w", path, err)
+	}
+	return ParseCandidates(contents), nil
+}
+
+// ParseCandidates accepts a list of instance types separated by newlines, whitespace, or commas and returns the unique instance types.
+// Lines starting with # are ignored.
+func ParseCandidates(contents []byte) []string {
+	candidates := []string{}
+	seen := map[string]bool{}
+	for _, line := range strings.Split(string(contents), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		for _, candidate := range strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t' || r == '\r'
+		}) {
+			if seen[candidate] {
+				continue
+			}
+			seen[candidate] = true
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates
+}
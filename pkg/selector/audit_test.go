@@ -0,0 +1,70 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func TestAudit(t *testing.T) {
+	itf := selector.Selector{
+		EC2: setupMock(t, describeInstanceTypes, "25_instances.json"),
+	}
+	filters := selector.Filters{
+		VCpusRange: &selector.IntRangeFilter{LowerBound: 2, UpperBound: 2},
+		MaxResults: aws.Int(1),
+	}
+	auditResults, err := itf.Audit(filters, []string{"c5.large", "c4.2xlarge", "a1.large", "bogus.type"})
+	h.Ok(t, err)
+	h.Equals(t, []string{"a1.large", "c5.large"}, auditResults.Passed)
+	h.Equals(t, []string{"bogus.type"}, auditResults.NotFound)
+	h.Assert(t, len(auditResults.Failed) == 1, "Should only report rejections for candidates")
+	h.Equals(t, []selector.FilterRejection{
+		{Filter: "vcpusRange", FilterValue: "2-2", InstanceTypeValue: "8"},
+	}, auditResults.Failed["c4.2xlarge"])
+}
+
+func TestAudit_NoCandidates(t *testing.T) {
+	itf := selector.Selector{
+		EC2: setupMock(t, describeInstanceTypes, "25_instances.json"),
+	}
+	auditResults, err := itf.Audit(selector.Filters{}, []string{})
+	h.Ok(t, err)
+	h.Assert(t, len(auditResults.Passed) == 0, "Should not pass any instance types when there are no candidates")
+	h.Assert(t, len(auditResults.Failed) == 0, "Should not fail any instance types when there are no candidates")
+}
+
+func TestAudit_Failure(t *testing.T) {
+	itf := selector.Selector{
+		EC2: mockedEC2{DescribeInstanceTypesErr: errors.New("error")},
+	}
+	auditResults, err := itf.Audit(selector.Filters{}, []string{"c5.large"})
+	h.Nok(t, err)
+	h.Assert(t, auditResults == nil, "Audit results should be nil on error")
+}
+
+func TestParseCandidates(t *testing.T) {
+	candidates := selector.ParseCandidates([]byte("# ASG overrides\nc5.large, m5.large\r\nc5.large\n\n  r5.large\tm5.xlarge\n"))
+	h.Equals(t, []string{"c5.large", "m5.large", "r5.large", "m5.xlarge"}, candidates)
+}
+
+func TestLoadCandidatesFile_NotFound(t *testing.T) {
+	_, err := selector.LoadCandidatesFile("does-not-exist.txt")
+	h.Nok(t, err)
+}
@@ -0,0 +1,97 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+const (
+	// baseInstanceTypeVariancePercent is how much the vcpus and memory of similar instance types may differ from a base instance type
+	baseInstanceTypeVariancePercent = 20
+)
+
+// resolveBaseInstanceType fills in any unset filters with ranges derived from the specs of the BaseInstanceType.
+// Filters which are already set take precedence over the derived filters.
+func (itf Selector) resolveBaseInstanceType(filters Filters, instanceTypeInfoSlice []*ec2.InstanceTypeInfo) (Filters, error) {
+	if filters.BaseInstanceType == nil {
+		return filters, nil
+	}
+	for _, instanceTypeInfo := range instanceTypeInfoSlice {
+		if *instanceTypeInfo.InstanceType == *filters.BaseInstanceType {
+			baseFilters := filtersFromBaseInstanceType(instanceTypeInfo)
+			itf.debugf("derived filters from base instance type %s: %+v", *filters.BaseInstanceType, baseFilters)
+			return baseFilters.Merge(filters), nil
+		}
+	}
+	return filters, newClassifiedError(ErrNotFound, "The base instance type %s was not found", *filters.BaseInstanceType)
+}
+
+// filtersFromBaseInstanceType converts the vcpus, memory, cpu architecture, gpus, and network performance
+// of an instance type into filters which will match similarly spec'd instance types
+func filtersFromBaseInstanceType(baseInstanceType *ec2.InstanceTypeInfo) Filters {
+	baseFilters := Filters{}
+	if baseInstanceType.VCpuInfo != nil && baseInstanceType.VCpuInfo.DefaultVCpus != nil {
+		vcpus := int(*baseInstanceType.VCpuInfo.DefaultVCpus)
+		vcpusRange := widenIntRange(IntRangeFilter{LowerBound: vcpus, UpperBound: vcpus}, baseInstanceTypeVariancePercent)
+		baseFilters.VCpusRange = &vcpusRange
+	}
+	if baseInstanceType.MemoryInfo != nil && baseInstanceType.MemoryInfo.SizeInMiB != nil {
+		memory := int(*baseInstanceType.MemoryInfo.SizeInMiB)
+		memoryRange := widenIntRange(IntRangeFilter{LowerBound: memory, UpperBound: memory}, baseInstanceTypeVariancePercent)
+		baseFilters.MemoryRange = &memoryRange
+	}
+	if baseInstanceType.ProcessorInfo != nil && len(baseInstanceType.ProcessorInfo.SupportedArchitectures) > 0 {
+		baseFilters.CPUArchitecture = aws.StringValueSlice(baseInstanceType.ProcessorInfo.SupportedArchitectures)
+	}
+	if gpus := getTotalGpusCount(baseInstanceType.GpuInfo); gpus != nil && *gpus > 0 {
+		baseFilters.GpusRange = &IntRangeFilter{LowerBound: int(*gpus), UpperBound: int(*gpus)}
+	}
+	if baseInstanceType.NetworkInfo != nil {
+		if networkPerformance := getNetworkPerformance(baseInstanceType.NetworkInfo.NetworkPerformance); networkPerformance != nil && *networkPerformance > 0 {
+			baseFilters.NetworkPerformance = &IntRangeFilter{LowerBound: *networkPerformance, UpperBound: maxInt}
+		}
+	}
+	return baseFilters
+}
+
+// FiltersFromInstanceID describes a running instance and returns filters which match instance types
+// similar to the instance's type that are offered in the same availability zone
+func (itf Selector) FiltersFromInstanceID(instanceID string) (Filters, error) {
+	itf.debugf("calling DescribeInstances for %s", instanceID)
+	instancesOutput, err := itf.EC2.DescribeInstances(&ec2.DescribeInstancesInput{
+		InstanceIds: []*string{&instanceID},
+	})
+	if err != nil {
+		return Filters{}, fmt.Errorf("Encountered an error when describing instance %s: %w", instanceID, classifyAPIError(err))
+	}
+	for _, reservation := range instancesOutput.Reservations {
+		for _, instance := range reservation.Instances {
+			if instance.InstanceId == nil || *instance.InstanceId != instanceID {
+				continue
+			}
+			filters := Filters{
+				BaseInstanceType: instance.InstanceType,
+			}
+			if instance.Placement != nil {
+				filters.AvailabilityZone = instance.Placement.AvailabilityZone
+			}
+			return filters, nil
+		}
+	}
+	return Filters{}, newClassifiedError(ErrNotFound, "The instance %s was not found", instanceID)
+}
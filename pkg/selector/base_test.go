@@ -0,0 +1,94 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func TestFilter_BaseInstanceType(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypes, "25_instances.json")
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	filters := selector.Filters{
+		BaseInstanceType: aws.String("c4.2xlarge"),
+	}
+	results, err := itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"c3.2xlarge", "c4.2xlarge", "c5.2xlarge"}, results)
+}
+
+func TestFilter_BaseInstanceTypeWithOverride(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypes, "25_instances.json")
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	filters := selector.Filters{
+		BaseInstanceType: aws.String("c4.2xlarge"),
+		CPUArchitecture:  []string{"arm64"},
+	}
+	results, err := itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"a1.2xlarge"}, results)
+}
+
+func TestFilter_BaseInstanceTypeNotFound(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypes, "25_instances.json")
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	filters := selector.Filters{
+		BaseInstanceType: aws.String("m5.xlarge"),
+	}
+	results, err := itf.Filter(filters)
+	h.Nok(t, err)
+	h.Assert(t, results == nil, "Results should be nil")
+}
+
+func TestFiltersFromInstanceID(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstances, "m4_xlarge_us-east-2a.json")
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	filters, err := itf.FiltersFromInstanceID("i-0123456789abcdef0")
+	h.Ok(t, err)
+	h.Equals(t, "m4.xlarge", *filters.BaseInstanceType)
+	h.Equals(t, "us-east-2a", *filters.AvailabilityZone)
+}
+
+func TestFiltersFromInstanceID_NotFound(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstances, "m4_xlarge_us-east-2a.json")
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	_, err := itf.FiltersFromInstanceID("i-00000000000000000")
+	h.Nok(t, err)
+}
+
+func TestFiltersFromInstanceID_Failure(t *testing.T) {
+	ec2Mock := mockedEC2{
+		DescribeInstancesErr: errors.New("error"),
+	}
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	_, err := itf.FiltersFromInstanceID("i-0123456789abcdef0")
+	h.Nok(t, err)
+}
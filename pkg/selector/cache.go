@@ -0,0 +1,240 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+const (
+	cacheFileExtension     = ".json"
+	instanceTypesCacheFile = "instance-types"
+	offeringsCacheFile     = "offerings"
+)
+
+// instanceTypesCache holds EC2 API responses in memory so that repeated filtering does not call the API again until the TTL expires.
+// If a dir is set, the responses are also persisted to files in the dir so that they can be reused across processes.
+type instanceTypesCache struct {
+	ttl time.Duration
+	// dir is the optional directory the responses are persisted to
+	dir string
+	// key distinguishes the cache files of different regions in the dir
+	key                 string
+	mu                  sync.Mutex
+	instanceTypes       []*ec2.InstanceTypeInfo
+	instanceTypesExpiry time.Time
+	offerings           map[string]cachedOfferings
+	// hits and misses count the lookups which were and were not served from the cache
+	hits   uint64
+	misses uint64
+}
+
+// cachedOfferings holds the instance type offerings for a single location
+type cachedOfferings struct {
+	offerings map[string]string
+	expiry    time.Time
+}
+
+// cacheFile is the contents of a file persisted to the cache dir
+type cacheFile struct {
+	Refreshed     time.Time               `json:"refreshed"`
+	Expiry        time.Time               `json:"expiry"`
+	InstanceTypes []*ec2.InstanceTypeInfo `json:"instanceTypes,omitempty"`
+	Offerings     map[string]string       `json:"offerings,omitempty"`
+}
+
+func newInstanceTypesCache(ttl time.Duration, dir string, key string) *instanceTypesCache {
+	return &instanceTypesCache{
+		ttl:       ttl,
+		dir:       dir,
+		key:       key,
+		offerings: map[string]cachedOfferings{},
+	}
+}
+
+// getInstanceTypes returns the cached instance types and true if they have not expired
+func (c *instanceTypesCache) getInstanceTypes() ([]*ec2.InstanceTypeInfo, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.instanceTypes == nil || time.Now().After(c.instanceTypesExpiry) {
+		cached, ok := c.readFile(instanceTypesCacheFile)
+		if !ok || cached.InstanceTypes == nil {
+			c.misses++
+			return nil, false
+		}
+		c.instanceTypes = cached.InstanceTypes
+		c.instanceTypesExpiry = cached.Expiry
+	}
+	c.hits++
+	return c.instanceTypes, true
+}
+
+func (c *instanceTypesCache) setInstanceTypes(instanceTypes []*ec2.InstanceTypeInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.instanceTypes = instanceTypes
+	c.instanceTypesExpiry = time.Now().Add(c.ttl)
+	c.writeFile(instanceTypesCacheFile, cacheFile{Refreshed: time.Now(), Expiry: c.instanceTypesExpiry, InstanceTypes: instanceTypes})
+}
+
+// getOfferings returns the cached instance type offerings for the location and true if they have not expired
+func (c *instanceTypesCache) getOfferings(location string) (map[string]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.offerings[location]
+	if !ok || time.Now().After(cached.expiry) {
+		cachedFile, ok := c.readFile(offeringsCacheFile + "-" + location)
+		if !ok || cachedFile.Offerings == nil {
+			c.misses++
+			return nil, false
+		}
+		cached = cachedOfferings{offerings: cachedFile.Offerings, expiry: cachedFile.Expiry}
+		c.offerings[location] = cached
+	}
+	c.hits++
+	return cached.offerings, true
+}
+
+// stats returns the number of lookups which were and were not served from the cache
+func (c *instanceTypesCache) stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{Hits: c.hits, Misses: c.misses}
+}
+
+func (c *instanceTypesCache) setOfferings(location string, offerings map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.offerings[location] = cachedOfferings{
+		offerings: offerings,
+		expiry:    time.Now().Add(c.ttl),
+	}
+	c.writeFile(offeringsCacheFile+"-"+location, cacheFile{Refreshed: time.Now(), Expiry: c.offerings[location].expiry, Offerings: offerings})
+}
+
+// cacheFilePath returns the path of the named cache file in the dir
+func (c *instanceTypesCache) cacheFilePath(name string) string {
+	return filepath.Join(c.dir, fmt.Sprintf("%s-%s%s", c.key, name, cacheFileExtension))
+}
+
+// readFile returns the contents of the named cache file and true if the dir is set and the file exists and has not expired
+func (c *instanceTypesCache) readFile(name string) (cacheFile, bool) {
+	cached := cacheFile{}
+	if c.dir == "" {
+		return cached, false
+	}
+	contents, err := ioutil.ReadFile(c.cacheFilePath(name))
+	if err != nil {
+		return cached, false
+	}
+	if err := json.Unmarshal(contents, &cached); err != nil || time.Now().After(cached.Expiry) {
+		return cached, false
+	}
+	return cached, true
+}
+
+// writeFile persists the named cache file to the dir if it is set.
+// Errors are ignored since the responses are still cached in memory and can be retrieved from the EC2 API again.
+func (c *instanceTypesCache) writeFile(name string, cached cacheFile) {
+	if c.dir == "" {
+		return
+	}
+	contents, err := json.Marshal(cached)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return
+	}
+	ioutil.WriteFile(c.cacheFilePath(name), contents, 0600)
+}
+
+// CacheStats returns the number of EC2 API responses which were and were not served from the cache since the Selector was created.
+// Both are zero if the Selector was not created with WithCache.
+func (itf Selector) CacheStats() CacheStats {
+	if itf.cache == nil {
+		return CacheStats{}
+	}
+	return itf.cache.stats()
+}
+
+// CacheDirMetadata returns when each cache file persisted to the dir by a Selector created with WithCacheDir was refreshed
+// and which region and partition it covers, sorted by file name. Expired cache files are included so that stale data can be found.
+func CacheDirMetadata(dir string) ([]CacheMetadata, error) {
+	cacheFiles, err := filepath.Glob(filepath.Join(dir, "*"+cacheFileExtension))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to list the cache files in %s: %w", dir, err)
+	}
+	metadata := []CacheMetadata{}
+	for _, cacheFilePath := range cacheFiles {
+		name := strings.TrimSuffix(filepath.Base(cacheFilePath), cacheFileExtension)
+		cacheMetadata := CacheMetadata{Path: cacheFilePath}
+		if i := strings.Index(name, "-"+offeringsCacheFile+"-"); i != -1 {
+			cacheMetadata.Region = name[:i]
+			cacheMetadata.Data = offeringsCacheFile
+			cacheMetadata.Location = name[i+len(offeringsCacheFile)+2:]
+		} else if strings.HasSuffix(name, "-"+instanceTypesCacheFile) {
+			cacheMetadata.Region = strings.TrimSuffix(name, "-"+instanceTypesCacheFile)
+			cacheMetadata.Data = instanceTypesCacheFile
+		} else {
+			continue
+		}
+		contents, err := ioutil.ReadFile(cacheFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read the cache file %s: %w", cacheFilePath, err)
+		}
+		cached := cacheFile{}
+		if err := json.Unmarshal(contents, &cached); err != nil {
+			return nil, fmt.Errorf("Unable to parse the cache file %s: %w", cacheFilePath, err)
+		}
+		if partition, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), cacheMetadata.Region); ok {
+			cacheMetadata.Partition = partition.ID()
+		}
+		cacheMetadata.Refreshed = cached.Refreshed
+		cacheMetadata.Expiry = cached.Expiry
+		cacheMetadata.Expired = time.Now().After(cached.Expiry)
+		cacheMetadata.InstanceTypes = len(cached.InstanceTypes) + len(cached.Offerings)
+		metadata = append(metadata, cacheMetadata)
+	}
+	return metadata, nil
+}
+
+// ClearCacheDir removes the cache files persisted to the dir by a Selector created with WithCacheDir.
+// Other files in the dir are not removed.
+func ClearCacheDir(dir string) error {
+	cacheFiles, err := filepath.Glob(filepath.Join(dir, "*"+cacheFileExtension))
+	if err != nil {
+		return fmt.Errorf("Unable to list the cache files in %s: %w", dir, err)
+	}
+	for _, cacheFilePath := range cacheFiles {
+		name := strings.TrimSuffix(filepath.Base(cacheFilePath), cacheFileExtension)
+		if !strings.Contains(name, "-"+instanceTypesCacheFile) && !strings.Contains(name, "-"+offeringsCacheFile+"-") {
+			continue
+		}
+		if err := os.Remove(cacheFilePath); err != nil {
+			return fmt.Errorf("Unable to remove the cache file %s: %w", cacheFilePath, err)
+		}
+	}
+	return nil
+}
@@ -14,74 +14,18 @@
 package selector
 
 import (
-	"log"
-	"math"
-	"regexp"
-	"strconv"
-	"strings"
-
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector/comparators"
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector/specs"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 )
 
-const (
-	supported = "supported"
-	required  = "required"
-)
-
-func isSupportedFromString(instanceTypeValue *string, target *string) bool {
-	if target == nil {
-		return true
-	}
-	if instanceTypeValue == nil {
-		return false
-	}
-	return *instanceTypeValue == *target
-}
-
-func isSupportedFromStrings(instanceTypeValues []*string, target *string) bool {
-	if target == nil {
-		return true
-	}
-	return contains(instanceTypeValues, *target)
-}
-
-func isSupportedWithRangeInt(instanceTypeValue *int, target *IntRangeFilter) bool {
-	var instanceTypeValueInt64 *int64
-	if instanceTypeValue != nil {
-		nonPtr := int64(*instanceTypeValue)
-		instanceTypeValueInt64 = &nonPtr
-	}
-	return isSupportedWithRangeInt64(instanceTypeValueInt64, target)
-}
-
-func isSupportedWithRangeInt64(instanceTypeValue *int64, target *IntRangeFilter) bool {
-	if target == nil {
-		return true
-	} else if instanceTypeValue == nil && target.LowerBound == 0 && target.UpperBound == 0 {
-		return true
-	} else if instanceTypeValue == nil {
-		return false
-	}
-	return int(*instanceTypeValue) >= target.LowerBound && int(*instanceTypeValue) <= target.UpperBound
-}
-
-func isSupportedWithFloat64(instanceTypeValue *float64, target *float64) bool {
-	if target == nil {
-		return true
-	}
-	if instanceTypeValue == nil {
-		return false
-	}
-	// compare up to values' two decimal floor
-	return math.Floor(*instanceTypeValue*100)/100 == math.Floor(*target*100)/100
-}
-
-func isSupportedWithBool(instanceTypeValue *bool, target *bool) bool {
-	if target == nil {
-		return true
+// toIntRange converts an IntRangeFilter to the range type used by the comparators package
+func toIntRange(intRangeFilter *IntRangeFilter) *comparators.IntRange {
+	if intRangeFilter == nil {
+		return nil
 	}
-	return *target == *instanceTypeValue
+	return &comparators.IntRange{LowerBound: intRangeFilter.LowerBound, UpperBound: intRangeFilter.UpperBound}
 }
 
 // Helper functions for aggregating data parsed from AWS API calls
@@ -108,24 +52,11 @@ func getNetworkPerformance(networkPerformance *string) *int {
 	if networkPerformance == nil {
 		return aws.Int(-1)
 	}
-	re, err := regexp.Compile(`[0-9]+ Gigabit`)
-	if err != nil {
-		log.Printf("Unable to compile regexp to parse network performance: %s\n", *networkPerformance)
-		return nil
-	}
-	networkBandwidth := re.FindString(*networkPerformance)
-	if networkBandwidth == "" {
-		return aws.Int(-1)
-	}
-	bandwidthAndUnit := strings.Split(networkBandwidth, " ")
-	if len(bandwidthAndUnit) != 2 {
-		return aws.Int(-1)
-	}
-	bandwidthNumber, err := strconv.Atoi(bandwidthAndUnit[0])
+	perf, err := specs.ParseNetworkPerformance(*networkPerformance)
 	if err != nil {
 		return aws.Int(-1)
 	}
-	return aws.Int(bandwidthNumber)
+	return aws.Int(int(perf.Gbps))
 }
 
 // supportSyntaxToBool takes an instance spec field that uses ["unsupported", "supported", or "required"]
@@ -134,10 +65,7 @@ func supportSyntaxToBool(instanceTypeSupport *string) *bool {
 	if instanceTypeSupport == nil {
 		return nil
 	}
-	if strings.ToLower(*instanceTypeSupport) == required || strings.ToLower(*instanceTypeSupport) == supported {
-		return aws.Bool(true)
-	}
-	return aws.Bool(false)
+	return aws.Bool(specs.IsSupported(*instanceTypeSupport))
 }
 
 func calculateVCpusToMemoryRatio(vcpusVal *int64, memoryVal *int64) *float64 {
@@ -147,14 +75,3 @@ func calculateVCpusToMemoryRatio(vcpusVal *int64, memoryVal *int64) *float64 {
 	// normalize vcpus to a mebivcpu value
 	return aws.Float64(float64(*memoryVal) / float64(*vcpusVal*1024))
 }
-
-// Slice helper function
-
-func contains(slice []*string, target string) bool {
-	for _, it := range slice {
-		if it != nil && *it == target {
-			return true
-		}
-	}
-	return false
-}
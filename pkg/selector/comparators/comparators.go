@@ -0,0 +1,111 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package comparators provides the functions the selector uses to compare instance type values to filter values.
+// Tools that replicate parts of the selector's matching logic can use them to stay consistent with the selector.
+package comparators
+
+import (
+	"math"
+)
+
+// IntRange is an inclusive range of ints to compare an instance type value to
+type IntRange struct {
+	LowerBound int
+	UpperBound int
+}
+
+// IsSupportedFromString returns true if the instance type value matches any of the targets
+func IsSupportedFromString(instanceTypeValue *string, targets []string) bool {
+	if len(targets) == 0 {
+		return true
+	}
+	if instanceTypeValue == nil {
+		return false
+	}
+	for _, target := range targets {
+		if *instanceTypeValue == target {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSupportedFromStrings returns true if any of the instance type values match any of the targets
+func IsSupportedFromStrings(instanceTypeValues []*string, targets []string) bool {
+	if len(targets) == 0 {
+		return true
+	}
+	for _, target := range targets {
+		if Contains(instanceTypeValues, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSupportedWithRangeInt returns true if the instance type value is within the target range
+func IsSupportedWithRangeInt(instanceTypeValue *int, target *IntRange) bool {
+	var instanceTypeValueInt64 *int64
+	if instanceTypeValue != nil {
+		nonPtr := int64(*instanceTypeValue)
+		instanceTypeValueInt64 = &nonPtr
+	}
+	return IsSupportedWithRangeInt64(instanceTypeValueInt64, target)
+}
+
+// IsSupportedWithRangeInt64 returns true if the instance type value is within the target range.
+// A nil instance type value only matches a target range of 0-0.
+func IsSupportedWithRangeInt64(instanceTypeValue *int64, target *IntRange) bool {
+	if target == nil {
+		return true
+	} else if instanceTypeValue == nil && target.LowerBound == 0 && target.UpperBound == 0 {
+		return true
+	} else if instanceTypeValue == nil {
+		return false
+	}
+	return int(*instanceTypeValue) >= target.LowerBound && int(*instanceTypeValue) <= target.UpperBound
+}
+
+// IsSupportedWithFloat64 returns true if the instance type value equals the target when both are truncated to two decimal places
+func IsSupportedWithFloat64(instanceTypeValue *float64, target *float64) bool {
+	if target == nil {
+		return true
+	}
+	if instanceTypeValue == nil {
+		return false
+	}
+	// compare up to values' two decimal floor
+	return math.Floor(*instanceTypeValue*100)/100 == math.Floor(*target*100)/100
+}
+
+// IsSupportedWithBool returns true if the instance type value equals the target
+func IsSupportedWithBool(instanceTypeValue *bool, target *bool) bool {
+	if target == nil {
+		return true
+	}
+	if instanceTypeValue == nil {
+		return false
+	}
+	return *target == *instanceTypeValue
+}
+
+// Contains returns true if any of the values in the slice equal the target
+func Contains(slice []*string, target string) bool {
+	for _, it := range slice {
+		if it != nil && *it == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,210 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package comparators_test
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector/comparators"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func TestIsSupportedFromStrings_Supported(t *testing.T) {
+	arm64 := aws.String("arm64")
+	instanceTypeArchitectures := []*string{arm64}
+	isSupported := comparators.IsSupportedFromStrings(instanceTypeArchitectures, []string{"arm64"})
+	h.Assert(t, isSupported == true, "arm64 should be a supported cpu architecture")
+}
+
+func TestIsSupportedFromStrings_AnyTarget(t *testing.T) {
+	instanceTypeArchitectures := []*string{aws.String("arm64")}
+	isSupported := comparators.IsSupportedFromStrings(instanceTypeArchitectures, []string{"x86_64", "arm64"})
+	h.Assert(t, isSupported == true, "arm64 should be supported when any target matches")
+	isSupported = comparators.IsSupportedFromStrings(instanceTypeArchitectures, []string{"x86_64", "i386"})
+	h.Assert(t, isSupported == false, "arm64 should NOT be supported when no targets match")
+}
+
+func TestIsSupportedFromStrings_Nil(t *testing.T) {
+	isSupported := comparators.IsSupportedFromStrings(nil, []string{"arm64"})
+	h.Assert(t, isSupported == false, "arm64 should NOT be a supported cpu architecture")
+}
+
+func TestIsSupportedFromStrings_NilTarget(t *testing.T) {
+	instanceTypeArchitectures := []*string{aws.String("arm64")}
+	isSupported := comparators.IsSupportedFromStrings(instanceTypeArchitectures, nil)
+	h.Assert(t, isSupported == true, "arm64 should be a supported cpu architecture")
+}
+
+func TestIsSupportedFromString_Supported(t *testing.T) {
+	nitro := aws.String("nitro")
+	isSupported := comparators.IsSupportedFromString(nitro, []string{"nitro"})
+	h.Assert(t, isSupported == true, "nitro should be the supported hypervisor")
+}
+
+func TestIsSupportedFromString_AnyTarget(t *testing.T) {
+	nitro := aws.String("nitro")
+	isSupported := comparators.IsSupportedFromString(nitro, []string{"xen", "nitro"})
+	h.Assert(t, isSupported == true, "nitro should be supported when any target matches")
+	isSupported = comparators.IsSupportedFromString(nitro, []string{"xen"})
+	h.Assert(t, isSupported == false, "nitro should NOT be supported when no targets match")
+}
+
+func TestIsSupportedFromString_Nil(t *testing.T) {
+	isSupported := comparators.IsSupportedFromString(nil, []string{"nitro"})
+	h.Assert(t, isSupported == false, "nil source should NOT be supported for specified target string")
+}
+
+func TestIsSupportedFromString_NilTarget(t *testing.T) {
+	nitro := aws.String("nitro")
+	isSupported := comparators.IsSupportedFromString(nitro, nil)
+	h.Assert(t, isSupported == true, "nil target should be supported for specified source string")
+}
+
+func TestIsSupportedWithBool(t *testing.T) {
+	hibernationSupported := aws.Bool(true)
+	userFilter := aws.Bool(true)
+	isSupported := comparators.IsSupportedWithBool(hibernationSupported, userFilter)
+	h.Assert(t, isSupported == true, "Hibernation should be supported")
+}
+
+func TestIsSupportedWithBool_Nil(t *testing.T) {
+	hibernationSupported := aws.Bool(false)
+	isSupported := comparators.IsSupportedWithBool(hibernationSupported, nil)
+	h.Assert(t, isSupported == true, "Hibernation should be supported")
+}
+
+func TestIsSupportedWithBool_Unsupported(t *testing.T) {
+	hibernationSupported := aws.Bool(false)
+	userFilter := aws.Bool(true)
+	isSupported := comparators.IsSupportedWithBool(hibernationSupported, userFilter)
+	h.Assert(t, isSupported == false, "Hibernation should NOT be supported")
+}
+
+func TestIsSupportedWithRangeInt_SupportedExact(t *testing.T) {
+	target := comparators.IntRange{LowerBound: 4, UpperBound: 4}
+	isSupported := comparators.IsSupportedWithRangeInt(aws.Int(4), &target)
+	h.Assert(t, isSupported == true, "IntRange should match exactly")
+}
+
+func TestIsSupportedWithRangeInt_SupportedAround(t *testing.T) {
+	target := comparators.IntRange{LowerBound: 2, UpperBound: 6}
+	isSupported := comparators.IsSupportedWithRangeInt(aws.Int(4), &target)
+	h.Assert(t, isSupported == true, "IntRange should match with lower and upper bound around the desired source")
+}
+
+func TestIsSupportedWithRangeInt_Nil(t *testing.T) {
+	target := comparators.IntRange{LowerBound: 2, UpperBound: 6}
+	isSupported := comparators.IsSupportedWithRangeInt(nil, &target)
+	h.Assert(t, isSupported == false, "IntRange should NOT match with nil source")
+}
+
+func TestIsSupportedWithRangeInt_NilTarget(t *testing.T) {
+	isSupported := comparators.IsSupportedWithRangeInt(aws.Int(4), nil)
+	h.Assert(t, isSupported == true, "IntRange should match with nil target")
+}
+
+func TestIsSupportedWithRangeInt_BothNil(t *testing.T) {
+	isSupported := comparators.IsSupportedWithRangeInt(nil, nil)
+	h.Assert(t, isSupported == true, "IntRange should match with nil target and nil source")
+}
+
+func TestIsSupportedWithRangeInt_SourceNilTarget0(t *testing.T) {
+	target := comparators.IntRange{LowerBound: 0, UpperBound: 0}
+	isSupported := comparators.IsSupportedWithRangeInt(nil, &target)
+	h.Assert(t, isSupported == true, "IntRange should match with 0 target and nil source")
+}
+
+// ==================
+
+func TestIsSupportedWithRangeInt64_SupportedExact(t *testing.T) {
+	target := comparators.IntRange{LowerBound: 4, UpperBound: 4}
+	isSupported := comparators.IsSupportedWithRangeInt64(aws.Int64(4), &target)
+	h.Assert(t, isSupported == true, "IntRange should match exactly")
+}
+
+func TestIsSupportedWithRangeInt64_SupportedAround(t *testing.T) {
+	target := comparators.IntRange{LowerBound: 2, UpperBound: 6}
+	isSupported := comparators.IsSupportedWithRangeInt64(aws.Int64(4), &target)
+	h.Assert(t, isSupported == true, "IntRange should match with lower and upper bound around the desired source")
+}
+
+func TestIsSupportedWithRangeInt64_Nil(t *testing.T) {
+	target := comparators.IntRange{LowerBound: 2, UpperBound: 6}
+	isSupported := comparators.IsSupportedWithRangeInt64(nil, &target)
+	h.Assert(t, isSupported == false, "IntRange should NOT match with nil source")
+}
+
+func TestIsSupportedWithRangeInt64_NilTarget(t *testing.T) {
+	isSupported := comparators.IsSupportedWithRangeInt64(aws.Int64(4), nil)
+	h.Assert(t, isSupported == true, "IntRange should match with nil target")
+}
+
+func TestIsSupportedWithRangeInt64_BothNil(t *testing.T) {
+	isSupported := comparators.IsSupportedWithRangeInt64(nil, nil)
+	h.Assert(t, isSupported == true, "IntRange should match with nil target and nil source")
+}
+
+func TestIsSupportedWithRangeInt64_SourceNilTarget0(t *testing.T) {
+	target := comparators.IntRange{LowerBound: 0, UpperBound: 0}
+	isSupported := comparators.IsSupportedWithRangeInt64(nil, &target)
+	h.Assert(t, isSupported == true, "IntRange should match with 0 target and nil source")
+}
+
+func TestIsSupportedWithFloat64_Supported(t *testing.T) {
+	isSupported := comparators.IsSupportedWithFloat64(aws.Float64(0.33), aws.Float64(0.33))
+	h.Assert(t, isSupported == true, "Float64 comparison should match exactly with 2 decimal places")
+}
+
+func TestIsSupportedWithFloat64_SupportedTruncatedDecPlacesExact(t *testing.T) {
+	isSupported := comparators.IsSupportedWithFloat64(aws.Float64(0.3322), aws.Float64(0.3322))
+	h.Assert(t, isSupported == true, "Float64 comparison should match exactly with 4 decimal places")
+}
+
+func TestIsSupportedWithFloat64_SupportedTruncatedDecPlaces(t *testing.T) {
+	isSupported := comparators.IsSupportedWithFloat64(aws.Float64(0.3399), aws.Float64(0.3311))
+	h.Assert(t, isSupported == true, "Float64 comparison should match when truncating to 2 decimal places")
+}
+
+func TestIsSupportedWithFloat64_Unsupported(t *testing.T) {
+	isSupported := comparators.IsSupportedWithFloat64(aws.Float64(0.4), aws.Float64(0.3399))
+	h.Assert(t, isSupported == false, "Float64 comparison should NOT match")
+}
+
+func TestIsSupportedWithFloat64_SourceNil(t *testing.T) {
+	isSupported := comparators.IsSupportedWithFloat64(nil, aws.Float64(0.3399))
+	h.Assert(t, isSupported == false, "Float64 comparison should NOT match with nil source")
+}
+
+func TestIsSupportedWithFloat64_TargetNil(t *testing.T) {
+	isSupported := comparators.IsSupportedWithFloat64(aws.Float64(0.3399), nil)
+	h.Assert(t, isSupported == true, "Float64 comparison should match with nil target")
+}
+
+func TestIsSupportedWithFloat64_BothNil(t *testing.T) {
+	isSupported := comparators.IsSupportedWithFloat64(nil, nil)
+	h.Assert(t, isSupported == true, "Float64 comparison should match with nil target and source")
+}
+
+func TestIsSupportedWithBool_NilSource(t *testing.T) {
+	isSupported := comparators.IsSupportedWithBool(nil, aws.Bool(true))
+	h.Assert(t, isSupported == false, "nil source should NOT be supported for specified target bool")
+}
+
+func TestContains(t *testing.T) {
+	values := []*string{nil, aws.String("x86_64"), aws.String("arm64")}
+	h.Assert(t, comparators.Contains(values, "arm64"), "arm64 should be contained in the slice")
+	h.Assert(t, !comparators.Contains(values, "i386"), "i386 should NOT be contained in the slice")
+	h.Assert(t, !comparators.Contains(nil, "arm64"), "arm64 should NOT be contained in a nil slice")
+}
@@ -20,169 +20,6 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 )
 
-func TestIsSupportedFromStrings_Supported(t *testing.T) {
-	arm64 := aws.String("arm64")
-	instanceTypeArchitectures := []*string{arm64}
-	isSupported := isSupportedFromStrings(instanceTypeArchitectures, arm64)
-	h.Assert(t, isSupported == true, "arm64 should be a supported cpu architecture")
-}
-
-func TestIsSupportedFromStrings_Nil(t *testing.T) {
-	arm64 := aws.String("arm64")
-	isSupported := isSupportedFromStrings(nil, arm64)
-	h.Assert(t, isSupported == false, "arm64 should NOT be a supported cpu architecture")
-}
-
-func TestIsSupportedFromStrings_NilTarget(t *testing.T) {
-	instanceTypeArchitectures := []*string{aws.String("arm64")}
-	isSupported := isSupportedFromStrings(instanceTypeArchitectures, nil)
-	h.Assert(t, isSupported == true, "arm64 should be a supported cpu architecture")
-}
-
-func TestIsSupportedFromString_Supported(t *testing.T) {
-	hypervisor := aws.String("nitro")
-	nitro := aws.String("nitro")
-	isSupported := isSupportedFromString(nitro, hypervisor)
-	h.Assert(t, isSupported == true, "nitro should be the supported hypervisor")
-}
-
-func TestIsSupportedFromString_Nil(t *testing.T) {
-	hypervisor := aws.String("nitro")
-	isSupported := isSupportedFromString(nil, hypervisor)
-	h.Assert(t, isSupported == false, "nil source should NOT be supported for specified target string")
-}
-
-func TestIsSupportedFromString_NilTarget(t *testing.T) {
-	nitro := aws.String("nitro")
-	isSupported := isSupportedFromString(nitro, nil)
-	h.Assert(t, isSupported == true, "nil target should be supported for specified source string")
-}
-
-func TestIsSupportedWithBool(t *testing.T) {
-	hibernationSupported := aws.Bool(true)
-	userFilter := aws.Bool(true)
-	isSupported := isSupportedWithBool(hibernationSupported, userFilter)
-	h.Assert(t, isSupported == true, "Hibernation should be supported")
-}
-
-func TestIsSupportedWithBool_Nil(t *testing.T) {
-	hibernationSupported := aws.Bool(false)
-	isSupported := isSupportedWithBool(hibernationSupported, nil)
-	h.Assert(t, isSupported == true, "Hibernation should be supported")
-}
-
-func TestIsSupportedWithBool_Unsupported(t *testing.T) {
-	hibernationSupported := aws.Bool(false)
-	userFilter := aws.Bool(true)
-	isSupported := isSupportedWithBool(hibernationSupported, userFilter)
-	h.Assert(t, isSupported == false, "Hibernation should NOT be supported")
-}
-
-func TestIsSupportedWithRangeInt_SupportedExact(t *testing.T) {
-	target := IntRangeFilter{LowerBound: 4, UpperBound: 4}
-	isSupported := isSupportedWithRangeInt(aws.Int(4), &target)
-	h.Assert(t, isSupported == true, "IntRangeFilter should match exactly")
-}
-
-func TestIsSupportedWithRangeInt_SupportedAround(t *testing.T) {
-	target := IntRangeFilter{LowerBound: 2, UpperBound: 6}
-	isSupported := isSupportedWithRangeInt(aws.Int(4), &target)
-	h.Assert(t, isSupported == true, "IntRangeFilter should match with lower and upper bound around the desired source")
-}
-
-func TestIsSupportedWithRangeInt_Nil(t *testing.T) {
-	target := IntRangeFilter{LowerBound: 2, UpperBound: 6}
-	isSupported := isSupportedWithRangeInt(nil, &target)
-	h.Assert(t, isSupported == false, "IntRangeFilter should NOT match with nil source")
-}
-
-func TestIsSupportedWithRangeInt_NilTarget(t *testing.T) {
-	isSupported := isSupportedWithRangeInt(aws.Int(4), nil)
-	h.Assert(t, isSupported == true, "IntRangeFilter should match with nil target")
-}
-
-func TestIsSupportedWithRangeInt_BothNil(t *testing.T) {
-	isSupported := isSupportedWithRangeInt(nil, nil)
-	h.Assert(t, isSupported == true, "IntRangeFilter should match with nil target and nil source")
-}
-
-func TestIsSupportedWithRangeInt_SourceNilTarget0(t *testing.T) {
-	target := IntRangeFilter{LowerBound: 0, UpperBound: 0}
-	isSupported := isSupportedWithRangeInt(nil, &target)
-	h.Assert(t, isSupported == true, "IntRangeFilter should match with 0 target and nil source")
-}
-
-// ==================
-
-func TestIsSupportedWithRangeInt64_SupportedExact(t *testing.T) {
-	target := IntRangeFilter{LowerBound: 4, UpperBound: 4}
-	isSupported := isSupportedWithRangeInt64(aws.Int64(4), &target)
-	h.Assert(t, isSupported == true, "IntRangeFilter should match exactly")
-}
-
-func TestIsSupportedWithRangeInt64_SupportedAround(t *testing.T) {
-	target := IntRangeFilter{LowerBound: 2, UpperBound: 6}
-	isSupported := isSupportedWithRangeInt64(aws.Int64(4), &target)
-	h.Assert(t, isSupported == true, "IntRangeFilter should match with lower and upper bound around the desired source")
-}
-
-func TestIsSupportedWithRangeInt64_Nil(t *testing.T) {
-	target := IntRangeFilter{LowerBound: 2, UpperBound: 6}
-	isSupported := isSupportedWithRangeInt64(nil, &target)
-	h.Assert(t, isSupported == false, "IntRangeFilter should NOT match with nil source")
-}
-
-func TestIsSupportedWithRangeInt64_NilTarget(t *testing.T) {
-	isSupported := isSupportedWithRangeInt64(aws.Int64(4), nil)
-	h.Assert(t, isSupported == true, "IntRangeFilter should match with nil target")
-}
-
-func TestIsSupportedWithRangeInt64_BothNil(t *testing.T) {
-	isSupported := isSupportedWithRangeInt64(nil, nil)
-	h.Assert(t, isSupported == true, "IntRangeFilter should match with nil target and nil source")
-}
-
-func TestIsSupportedWithRangeInt64_SourceNilTarget0(t *testing.T) {
-	target := IntRangeFilter{LowerBound: 0, UpperBound: 0}
-	isSupported := isSupportedWithRangeInt64(nil, &target)
-	h.Assert(t, isSupported == true, "IntRangeFilter should match with 0 target and nil source")
-}
-
-func TestIsSupportedWithFloat64_Supported(t *testing.T) {
-	isSupported := isSupportedWithFloat64(aws.Float64(0.33), aws.Float64(0.33))
-	h.Assert(t, isSupported == true, "Float64 comparison should match exactly with 2 decimal places")
-}
-
-func TestIsSupportedWithFloat64_SupportedTruncatedDecPlacesExact(t *testing.T) {
-	isSupported := isSupportedWithFloat64(aws.Float64(0.3322), aws.Float64(0.3322))
-	h.Assert(t, isSupported == true, "Float64 comparison should match exactly with 4 decimal places")
-}
-
-func TestIsSupportedWithFloat64_SupportedTruncatedDecPlaces(t *testing.T) {
-	isSupported := isSupportedWithFloat64(aws.Float64(0.3399), aws.Float64(0.3311))
-	h.Assert(t, isSupported == true, "Float64 comparison should match when truncating to 2 decimal places")
-}
-
-func TestIsSupportedWithFloat64_Unsupported(t *testing.T) {
-	isSupported := isSupportedWithFloat64(aws.Float64(0.4), aws.Float64(0.3399))
-	h.Assert(t, isSupported == false, "Float64 comparison should NOT match")
-}
-
-func TestIsSupportedWithFloat64_SourceNil(t *testing.T) {
-	isSupported := isSupportedWithFloat64(nil, aws.Float64(0.3399))
-	h.Assert(t, isSupported == false, "Float64 comparison should NOT match with nil source")
-}
-
-func TestIsSupportedWithFloat64_TargetNil(t *testing.T) {
-	isSupported := isSupportedWithFloat64(aws.Float64(0.3399), nil)
-	h.Assert(t, isSupported == true, "Float64 comparison should match with nil target")
-}
-
-func TestIsSupportedWithFloat64_BothNil(t *testing.T) {
-	isSupported := isSupportedWithFloat64(nil, nil)
-	h.Assert(t, isSupported == true, "Float64 comparison should match with nil target and source")
-}
-
 func TestSupportSyntaxToBool_Supported(t *testing.T) {
 	isSupported := supportSyntaxToBool(aws.String("supported"))
 	h.Assert(t, *isSupported == true, "Supported should evaluate to true")
@@ -256,6 +93,9 @@ func TestGetNetworkPerformance(t *testing.T) {
 	netPerformance = getNetworkPerformance(aws.String("100 Gigabit"))
 	h.Assert(t, *netPerformance == 100, "Networking performance should parse properly")
 
+	netPerformance = getNetworkPerformance(aws.String("12.5 Gigabit"))
+	h.Assert(t, *netPerformance == 12, "Fractional networking performance should be truncated to the whole Gigabit")
+
 	netPerformance = getNetworkPerformance(aws.String("10 Gigabit abcd"))
 	h.Assert(t, *netPerformance == 10, "Networking performance should parse properly when an arbitrary string is passed after quantity-unit syntax")
 
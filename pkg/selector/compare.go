@@ -0,0 +1,120 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// comparedSpec extracts a human-readable value of a spec from an instance type for Compare
+type comparedSpec struct {
+	name  string
+	value func(instanceTypeInfo *ec2.InstanceTypeInfo, details InstanceTypeDetails) string
+}
+
+// comparedSpecs are the specs compared by Compare in the order they are returned
+var comparedSpecs = []comparedSpec{
+	{"vcpus", func(_ *ec2.InstanceTypeInfo, d InstanceTypeDetails) string { return strconv.Itoa(d.VCpus) }},
+	{"memory (MiB)", func(_ *ec2.InstanceTypeInfo, d InstanceTypeDetails) string { return strconv.Itoa(d.MemoryMiB) }},
+	{"cpu architecture", func(_ *ec2.InstanceTypeInfo, d InstanceTypeDetails) string {
+		return strings.Join(d.CPUArchitectures, ", ")
+	}},
+	{"clock speed (GHz)", func(i *ec2.InstanceTypeInfo, _ InstanceTypeDetails) string {
+		if i.ProcessorInfo == nil || i.ProcessorInfo.SustainedClockSpeedInGhz == nil {
+			return "none"
+		}
+		return strconv.FormatFloat(*i.ProcessorInfo.SustainedClockSpeedInGhz, 'f', -1, 64)
+	}},
+	{"gpus", func(_ *ec2.InstanceTypeInfo, d InstanceTypeDetails) string { return strconv.Itoa(d.Gpus) }},
+	{"gpu memory (MiB)", func(_ *ec2.InstanceTypeInfo, d InstanceTypeDetails) string { return strconv.Itoa(d.GpuMemoryMiB) }},
+	{"network performance", func(_ *ec2.InstanceTypeInfo, d InstanceTypeDetails) string { return d.NetworkPerformance }},
+	{"network interfaces", func(_ *ec2.InstanceTypeInfo, d InstanceTypeDetails) string { return strconv.Itoa(d.NetworkInterfaces) }},
+	{"ena support", func(i *ec2.InstanceTypeInfo, _ InstanceTypeDetails) string {
+		if i.NetworkInfo == nil {
+			return "none"
+		}
+		return formatSpecValue(i.NetworkInfo.EnaSupport)
+	}},
+	{"ebs optimized", func(i *ec2.InstanceTypeInfo, _ InstanceTypeDetails) string {
+		if i.EbsInfo == nil {
+			return "none"
+		}
+		return formatSpecValue(i.EbsInfo.EbsOptimizedSupport)
+	}},
+	{"ebs encryption", func(i *ec2.InstanceTypeInfo, _ InstanceTypeDetails) string {
+		if i.EbsInfo == nil {
+			return "none"
+		}
+		return formatSpecValue(i.EbsInfo.EncryptionSupport)
+	}},
+	{"instance storage (GB)", func(i *ec2.InstanceTypeInfo, _ InstanceTypeDetails) string {
+		if i.InstanceStorageInfo == nil {
+			return "none"
+		}
+		return formatSpecValue(i.InstanceStorageInfo.TotalSizeInGB)
+	}},
+	{"hypervisor", func(_ *ec2.InstanceTypeInfo, d InstanceTypeDetails) string { return d.Hypervisor }},
+	{"burstable", func(_ *ec2.InstanceTypeInfo, d InstanceTypeDetails) string { return strconv.FormatBool(d.Burstable) }},
+	{"baremetal", func(_ *ec2.InstanceTypeInfo, d InstanceTypeDetails) string { return strconv.FormatBool(d.BareMetal) }},
+	{"current generation", func(_ *ec2.InstanceTypeInfo, d InstanceTypeDetails) string {
+		return strconv.FormatBool(d.CurrentGeneration)
+	}},
+	{"spot price ($/hr)", func(_ *ec2.InstanceTypeInfo, d InstanceTypeDetails) string {
+		if d.PricePerHour == nil {
+			return "none"
+		}
+		return strconv.FormatFloat(*d.PricePerHour, 'f', -1, 64)
+	}},
+}
+
+// Compare returns a field-by-field comparison of the specs of two instance types (i.e. m5.xlarge and m6i.xlarge)
+// along with the lowest current Linux spot price of each in any zone of the region
+func (itf Selector) Compare(instanceTypeA string, instanceTypeB string) (*Comparison, error) {
+	instanceTypeInfoA, err := itf.describeInstanceType(instanceTypeA)
+	if err != nil {
+		return nil, err
+	}
+	instanceTypeInfoB, err := itf.describeInstanceType(instanceTypeB)
+	if err != nil {
+		return nil, err
+	}
+	detailsA := newInstanceTypeDetails(instanceTypeInfoA)
+	detailsB := newInstanceTypeDetails(instanceTypeInfoB)
+	spotPrices := itf.locationSpotPrices(Filters{}, nil, []*ec2.InstanceTypeInfo{instanceTypeInfoA, instanceTypeInfoB})
+	if price, ok := spotPrices[detailsA.InstanceType]; ok {
+		detailsA.PricePerHour = aws.Float64(price)
+	}
+	if price, ok := spotPrices[detailsB.InstanceType]; ok {
+		detailsB.PricePerHour = aws.Float64(price)
+	}
+	comparison := &Comparison{
+		InstanceTypeA: aws.StringValue(instanceTypeInfoA.InstanceType),
+		InstanceTypeB: aws.StringValue(instanceTypeInfoB.InstanceType),
+	}
+	for _, spec := range comparedSpecs {
+		valueA := spec.value(instanceTypeInfoA, detailsA)
+		valueB := spec.value(instanceTypeInfoB, detailsB)
+		comparison.Specs = append(comparison.Specs, SpecComparison{
+			Spec:      spec.name,
+			A:         valueA,
+			B:         valueB,
+			Different: valueA != valueB,
+		})
+	}
+	return comparison, nil
+}
@@ -0,0 +1,59 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+)
+
+func TestCompare(t *testing.T) {
+	itf := selector.Selector{
+		EC2: setupMock(t, describeInstanceTypes, "t3_micro_and_p3_16xl.json"),
+	}
+	comparison, err := itf.Compare("t3.micro", "p3.16xlarge")
+	h.Ok(t, err)
+	h.Equals(t, "t3.micro", comparison.InstanceTypeA)
+	h.Equals(t, "p3.16xlarge", comparison.InstanceTypeB)
+	specs := map[string]selector.SpecComparison{}
+	for _, spec := range comparison.Specs {
+		specs[spec.Spec] = spec
+	}
+	h.Equals(t, selector.SpecComparison{Spec: "vcpus", A: "2", B: "64", Different: true}, specs["vcpus"])
+	h.Equals(t, selector.SpecComparison{Spec: "gpus", A: "0", B: "8", Different: true}, specs["gpus"])
+	h.Equals(t, selector.SpecComparison{Spec: "cpu architecture", A: "x86_64", B: "x86_64", Different: false}, specs["cpu architecture"])
+	h.Equals(t, selector.SpecComparison{Spec: "spot price ($/hr)", A: "none", B: "none", Different: false}, specs["spot price ($/hr)"])
+}
+
+func TestCompare_SpotPrice(t *testing.T) {
+	itf := selector.Selector{EC2: setupSpotMock(t)}
+	comparison, err := itf.Compare("t3.micro", "p3.16xlarge")
+	h.Ok(t, err)
+	specs := map[string]selector.SpecComparison{}
+	for _, spec := range comparison.Specs {
+		specs[spec.Spec] = spec
+	}
+	h.Equals(t, selector.SpecComparison{Spec: "spot price ($/hr)", A: "0.0031", B: "7.3", Different: true}, specs["spot price ($/hr)"])
+}
+
+func TestCompare_NotFound(t *testing.T) {
+	itf := selector.Selector{
+		EC2: setupMock(t, describeInstanceTypes, "t3_micro.json"),
+	}
+	comparison, err := itf.Compare("t3.micro", "m5.xlarge")
+	h.Nok(t, err)
+	h.Assert(t, comparison == nil, "Comparison should be nil")
+}
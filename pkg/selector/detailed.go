@@ -0,0 +1,125 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// FilterDetailed accepts a Filters struct which is used to select the available instance types
+// matching the criteria within Filters and returns a summary of the specs of each matching instance type
+func (itf Selector) FilterDetailed(filters Filters) ([]InstanceTypeDetails, error) {
+	filters, instanceTypeInfoSlice, locationInstanceOfferings, err := itf.prepareFilters(filters)
+	if err != nil {
+		return nil, err
+	}
+	matchingInstanceTypes, _, err := itf.filterInstanceTypes(filters, instanceTypeInfoSlice, locationInstanceOfferings)
+	if err != nil {
+		return nil, err
+	}
+	matchingInstanceTypes = itf.truncateResults(filters, matchingInstanceTypes)
+	zone := itf.locationZone(filters, matchingInstanceTypes)
+	spotPrices := itf.locationSpotPrices(filters, zone, matchingInstanceTypes)
+	instanceTypeDetails := []InstanceTypeDetails{}
+	for _, instanceTypeInfo := range matchingInstanceTypes {
+		details := newInstanceTypeDetails(instanceTypeInfo)
+		if location, ok := locationInstanceOfferings[details.InstanceType]; ok {
+			details.Locations = []string{location}
+			details.Zone = zone
+		}
+		if price, ok := spotPrices[details.InstanceType]; ok {
+			details.PricePerHour = aws.Float64(price)
+		}
+		details.InstanceType = serviceInstanceTypeName(filters.Service, details.InstanceType)
+		instanceTypeDetails = append(instanceTypeDetails, details)
+	}
+	return instanceTypeDetails, nil
+}
+
+// locationZone resolves the location filter to the name and zone ID of its availability zone.
+// Nil is returned when the location is not a zone, nothing matched, the instance types come from a data provider,
+// or the zone could not be resolved, since the annotation is not worth failing the results over.
+func (itf Selector) locationZone(filters Filters, matchingInstanceTypes []*ec2.InstanceTypeInfo) *Zone {
+	if len(matchingInstanceTypes) == 0 || itf.DataProvider != nil {
+		return nil
+	}
+	location := getLocation(filters)
+	if !IsZone(location) {
+		return nil
+	}
+	zone, err := itf.ResolveZone(location)
+	if err != nil {
+		itf.debugf("unable to resolve the zone id of %s: %v", location, err)
+		return nil
+	}
+	return zone
+}
+
+// locationSpotPrices returns the lowest current spot price of each matching instance type in the zone of the location filter,
+// or in any zone of the region. Nil is returned when nothing matched, the instance types come from a data provider,
+// or the prices could not be retrieved, like without permission to call DescribeSpotPriceHistory.
+func (itf Selector) locationSpotPrices(filters Filters, zone *Zone, matchingInstanceTypes []*ec2.InstanceTypeInfo) map[string]float64 {
+	if len(matchingInstanceTypes) == 0 || itf.DataProvider != nil {
+		return nil
+	}
+	zoneName := ""
+	if zone != nil {
+		zoneName = zone.Name
+	} else if location := getLocation(filters); IsZone(location) {
+		zoneName = location
+	}
+	instanceTypes := []string{}
+	for _, instanceTypeInfo := range matchingInstanceTypes {
+		instanceTypes = append(instanceTypes, aws.StringValue(instanceTypeInfo.InstanceType))
+	}
+	spotPrices, err := itf.lowestSpotPrices(instanceTypes, zoneName)
+	if err != nil {
+		itf.debugf("unable to retrieve the spot prices of the matching instance types: %v", err)
+		return nil
+	}
+	return spotPrices
+}
+
+// newInstanceTypeDetails summarizes the specs of the instance type info
+func newInstanceTypeDetails(instanceTypeInfo *ec2.InstanceTypeInfo) InstanceTypeDetails {
+	details := InstanceTypeDetails{
+		InstanceType:      aws.StringValue(instanceTypeInfo.InstanceType),
+		CPUArchitectures:  []string{},
+		Hypervisor:        aws.StringValue(instanceTypeInfo.Hypervisor),
+		CurrentGeneration: aws.BoolValue(instanceTypeInfo.CurrentGeneration),
+		Burstable:         aws.BoolValue(instanceTypeInfo.BurstablePerformanceSupported),
+		BareMetal:         aws.BoolValue(instanceTypeInfo.BareMetal),
+	}
+	if instanceTypeInfo.VCpuInfo != nil {
+		details.VCpus = int(aws.Int64Value(instanceTypeInfo.VCpuInfo.DefaultVCpus))
+	}
+	if instanceTypeInfo.MemoryInfo != nil {
+		details.MemoryMiB = int(aws.Int64Value(instanceTypeInfo.MemoryInfo.SizeInMiB))
+	}
+	if instanceTypeInfo.ProcessorInfo != nil {
+		details.CPUArchitectures = aws.StringValueSlice(instanceTypeInfo.ProcessorInfo.SupportedArchitectures)
+	}
+	if instanceTypeInfo.GpuInfo != nil {
+		details.GpuMemoryMiB = int(aws.Int64Value(instanceTypeInfo.GpuInfo.TotalGpuMemoryInMiB))
+		for _, gpuInfo := range instanceTypeInfo.GpuInfo.Gpus {
+			details.Gpus += int(aws.Int64Value(gpuInfo.Count))
+		}
+	}
+	if instanceTypeInfo.NetworkInfo != nil {
+		details.NetworkPerformance = aws.StringValue(instanceTypeInfo.NetworkInfo.NetworkPerformance)
+		details.NetworkInterfaces = int(aws.Int64Value(instanceTypeInfo.NetworkInfo.MaximumNetworkInterfaces))
+	}
+	return details
+}
@@ -0,0 +1,122 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func TestFilterDetailed(t *testing.T) {
+	ec2Mock := mockedEC2{
+		DescribeInstanceTypesResp:         setupMock(t, describeInstanceTypes, "t3_micro.json").DescribeInstanceTypesResp,
+		DescribeInstanceTypeOfferingsResp: setupMock(t, describeInstanceTypeOfferings, "us-east-2a.json").DescribeInstanceTypeOfferingsResp,
+	}
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	results, err := itf.FilterDetailed(selector.Filters{
+		VCpusRange:       &selector.IntRangeFilter{LowerBound: 2, UpperBound: 2},
+		AvailabilityZone: aws.String("us-east-2a"),
+	})
+	h.Ok(t, err)
+	h.Equals(t, []selector.InstanceTypeDetails{
+		{
+			InstanceType:       "t3.micro",
+			VCpus:              2,
+			MemoryMiB:          1024,
+			CPUArchitectures:   []string{"x86_64"},
+			NetworkPerformance: "Up to 5 Gigabit",
+			NetworkInterfaces:  2,
+			Hypervisor:         "nitro",
+			CurrentGeneration:  true,
+			Burstable:          true,
+			Locations:          []string{"us-east-2a"},
+		},
+	}, results)
+}
+
+func TestFilterDetailed_ZoneID(t *testing.T) {
+	ec2Mock := mockedEC2{
+		DescribeInstanceTypesResp:         setupMock(t, describeInstanceTypes, "t3_micro.json").DescribeInstanceTypesResp,
+		DescribeInstanceTypeOfferingsResp: setupMock(t, describeInstanceTypeOfferings, "us-east-2a.json").DescribeInstanceTypeOfferingsResp,
+		DescribeAvailabilityZonesResp:     usEast2Zones(),
+	}
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	results, err := itf.FilterDetailed(selector.Filters{
+		VCpusRange:       &selector.IntRangeFilter{LowerBound: 2, UpperBound: 2},
+		AvailabilityZone: aws.String("use2-az1"),
+	})
+	h.Ok(t, err)
+	h.Equals(t, 1, len(results))
+	h.Equals(t, "us-east-2a", results[0].Zone.Name)
+	h.Equals(t, "use2-az1", results[0].Zone.ID)
+}
+
+func TestFilterDetailed_SpotPrices(t *testing.T) {
+	itf := selector.Selector{EC2: setupSpotMock(t)}
+	filters := selector.Filters{
+		VCpusRange: &selector.IntRangeFilter{LowerBound: 2, UpperBound: 2},
+	}
+	results, err := itf.FilterDetailed(filters)
+	h.Ok(t, err)
+	h.Equals(t, 1, len(results))
+	h.Equals(t, aws.Float64(0.0031), results[0].PricePerHour)
+
+	// the price is the one of the location filter's zone
+	filters.AvailabilityZone = aws.String("us-east-2a")
+	results, err = itf.FilterDetailed(filters)
+	h.Ok(t, err)
+	h.Equals(t, aws.Float64(0.004), results[0].PricePerHour)
+
+	// prices are left unset rather than failing the results
+	ec2Mock := setupSpotMock(t)
+	ec2Mock.DescribeSpotPriceHistoryErr = errors.New("error")
+	itf = selector.Selector{EC2: ec2Mock}
+	results, err = itf.FilterDetailed(filters)
+	h.Ok(t, err)
+	h.Assert(t, results[0].PricePerHour == nil, "PricePerHour should not be set when spot prices cannot be retrieved")
+}
+
+func TestFilterDetailed_Gpus(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypes, "t3_micro_and_p3_16xl.json")
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	results, err := itf.FilterDetailed(selector.Filters{
+		GpusRange: &selector.IntRangeFilter{LowerBound: 1, UpperBound: 8},
+	})
+	h.Ok(t, err)
+	h.Assert(t, len(results) == 1, "Should only return 1 instance type with gpus")
+	h.Equals(t, "p3.16xlarge", results[0].InstanceType)
+	h.Equals(t, 8, results[0].Gpus)
+	h.Assert(t, results[0].Locations == nil, "Locations should be empty without a location filter")
+}
+
+func TestFilterDetailed_Failure(t *testing.T) {
+	itf := selector.Selector{
+		EC2: mockedEC2{
+			DescribeInstanceTypesErr: errors.New("error"),
+		},
+	}
+	results, err := itf.FilterDetailed(selector.Filters{})
+	h.Nok(t, err)
+	h.Assert(t, results == nil, "Results should be nil")
+}
@@ -0,0 +1,66 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"sort"
+)
+
+// DiffInstanceTypeDetails compares the instance types returned by two evaluations of the same filters with FilterDetailed
+// and returns the instance types which were added and removed along with any changes to their price
+func DiffInstanceTypeDetails(previous []InstanceTypeDetails, current []InstanceTypeDetails) InstanceTypesChange {
+	previousByName := map[string]InstanceTypeDetails{}
+	for _, details := range previous {
+		previousByName[details.InstanceType] = details
+	}
+	currentByName := map[string]InstanceTypeDetails{}
+	for _, details := range current {
+		currentByName[details.InstanceType] = details
+	}
+	change := InstanceTypesChange{
+		Added:        []string{},
+		Removed:      []string{},
+		PriceChanges: []PriceChange{},
+	}
+	for name, currentDetails := range currentByName {
+		previousDetails, ok := previousByName[name]
+		if !ok {
+			change.Added = append(change.Added, name)
+			continue
+		}
+		if previousDetails.PricePerHour != nil && currentDetails.PricePerHour != nil && *previousDetails.PricePerHour != *currentDetails.PricePerHour {
+			change.PriceChanges = append(change.PriceChanges, PriceChange{
+				InstanceType:         name,
+				PreviousPricePerHour: *previousDetails.PricePerHour,
+				PricePerHour:         *currentDetails.PricePerHour,
+			})
+		}
+	}
+	for name := range previousByName {
+		if _, ok := currentByName[name]; !ok {
+			change.Removed = append(change.Removed, name)
+		}
+	}
+	sort.Strings(change.Added)
+	sort.Strings(change.Removed)
+	sort.Slice(change.PriceChanges, func(i, j int) bool {
+		return change.PriceChanges[i].InstanceType < change.PriceChanges[j].InstanceType
+	})
+	return change
+}
+
+// HasChanges returns true if any instance types were added, removed, or changed price
+func (c InstanceTypesChange) HasChanges() bool {
+	return len(c.Added) != 0 || len(c.Removed) != 0 || len(c.PriceChanges) != 0
+}
@@ -0,0 +1,71 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestDiffInstanceTypeDetails(t *testing.T) {
+	previous := []selector.InstanceTypeDetails{
+		{InstanceType: "c5.large", PricePerHour: aws.Float64(0.085)},
+		{InstanceType: "m5.large", PricePerHour: aws.Float64(0.096)},
+		{InstanceType: "t3.medium"},
+	}
+	current := []selector.InstanceTypeDetails{
+		{InstanceType: "c5.large", PricePerHour: aws.Float64(0.08)},
+		{InstanceType: "m5.large", PricePerHour: aws.Float64(0.096)},
+		{InstanceType: "m7i.large"},
+		{InstanceType: "c7i.large"},
+	}
+	change := selector.DiffInstanceTypeDetails(previous, current)
+	h.Assert(t, change.HasChanges(), "Should have changes")
+	h.Equals(t, []string{"c7i.large", "m7i.large"}, change.Added)
+	h.Equals(t, []string{"t3.medium"}, change.Removed)
+	h.Equals(t, []selector.PriceChange{{InstanceType: "c5.large", PreviousPricePerHour: 0.085, PricePerHour: 0.08}}, change.PriceChanges)
+}
+
+func TestDiffInstanceTypeDetails_NoChanges(t *testing.T) {
+	details := []selector.InstanceTypeDetails{{InstanceType: "c5.large"}, {InstanceType: "m5.large"}}
+	change := selector.DiffInstanceTypeDetails(details, details)
+	h.Assert(t, !change.HasChanges(), "Should not have changes")
+	change = selector.DiffInstanceTypeDetails(nil, nil)
+	h.Assert(t, !change.HasChanges(), "Should not have changes")
+}
+
+func TestDiffInstanceTypeDetails_FilterDetailedSpotPrices(t *testing.T) {
+	filters := selector.Filters{VCpusRange: &selector.IntRangeFilter{LowerBound: 2, UpperBound: 2}}
+	itf := selector.Selector{EC2: setupSpotMock(t)}
+	previous, err := itf.FilterDetailed(filters)
+	h.Ok(t, err)
+
+	ec2Mock := setupSpotMock(t)
+	ec2Mock.DescribeSpotPriceHistoryResp = ec2.DescribeSpotPriceHistoryOutput{
+		SpotPriceHistory: []*ec2.SpotPrice{
+			{InstanceType: aws.String("t3.micro"), AvailabilityZone: aws.String("us-east-2b"), SpotPrice: aws.String("0.0029"), Timestamp: aws.Time(time.Now())},
+		},
+	}
+	itf = selector.Selector{EC2: ec2Mock}
+	current, err := itf.FilterDetailed(filters)
+	h.Ok(t, err)
+
+	change := selector.DiffInstanceTypeDetails(previous, current)
+	h.Equals(t, []selector.PriceChange{{InstanceType: "t3.micro", PreviousPricePerHour: 0.0031, PricePerHour: 0.0029}}, change.PriceChanges)
+}
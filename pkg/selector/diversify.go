@@ -0,0 +1,158 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+const (
+	// launchTemplateIDPrefix distinguishes launch template ids from launch template names
+	launchTemplateIDPrefix = "lt-"
+	// launchTemplateVersionSeparator separates a launch template id or name from an optional version, like my-template:3
+	launchTemplateVersionSeparator = ":"
+	defaultLaunchTemplateVersion   = "$Default"
+)
+
+// Diversify derives the common spec envelope of the current instance types, like the overrides of an ASG, and returns the other
+// instance types within the envelope which could be added to improve diversification. Filters which are set take precedence
+// over the envelope so that it can be narrowed or widened. MaxResults and the other result options apply to the suggestions.
+func (itf Selector) Diversify(current []string, filters Filters) (*Diversification, error) {
+	envelope, err := itf.FiltersFromInstanceTypes(current)
+	if err != nil {
+		return nil, err
+	}
+	filters = envelope.Merge(filters)
+	instanceTypeInfoSlice, err := itf.rawFilter(filters)
+	if err != nil {
+		return nil, err
+	}
+	currentSet := map[string]bool{}
+	for _, instanceType := range current {
+		currentSet[instanceType] = true
+	}
+	suggestions := []*ec2.InstanceTypeInfo{}
+	for _, instanceTypeInfo := range instanceTypeInfoSlice {
+		if !currentSet[aws.StringValue(instanceTypeInfo.InstanceType)] {
+			suggestions = append(suggestions, instanceTypeInfo)
+		}
+	}
+	return &Diversification{
+		Current:       current,
+		Filters:       filters,
+		InstanceTypes: renameForService(filters.Service, itf.truncateResults(filters, suggestions)),
+	}, nil
+}
+
+// FiltersFromInstanceTypes returns the filters of the spec envelope shared by the instance types: the vcpus, memory, and gpus
+// ranges spanning their specs and the cpu architectures they all support
+func (itf Selector) FiltersFromInstanceTypes(instanceTypes []string) (Filters, error) {
+	if len(instanceTypes) == 0 {
+		return Filters{}, newClassifiedError(ErrInvalidFilters, "At least one instance type is required to derive a spec envelope")
+	}
+	var vcpusRange, memoryRange, gpusRange *IntRangeFilter
+	var architectures []string
+	for _, instanceType := range instanceTypes {
+		instanceTypeInfo, err := itf.describeInstanceType(instanceType)
+		if err != nil {
+			return Filters{}, err
+		}
+		if instanceTypeInfo.VCpuInfo != nil {
+			vcpusRange = spanIntRange(vcpusRange, int(aws.Int64Value(instanceTypeInfo.VCpuInfo.DefaultVCpus)))
+		}
+		if instanceTypeInfo.MemoryInfo != nil {
+			memoryRange = spanIntRange(memoryRange, int(aws.Int64Value(instanceTypeInfo.MemoryInfo.SizeInMiB)))
+		}
+		gpusRange = spanIntRange(gpusRange, int(aws.Int64Value(getTotalGpusCount(instanceTypeInfo.GpuInfo))))
+		supported := []string{}
+		if instanceTypeInfo.ProcessorInfo != nil {
+			supported = aws.StringValueSlice(instanceTypeInfo.ProcessorInfo.SupportedArchitectures)
+		}
+		if architectures == nil {
+			architectures = supported
+		} else {
+			architectures = intersection(architectures, supported)
+		}
+	}
+	if len(architectures) == 0 {
+		return Filters{}, newClassifiedError(ErrInvalidFilters, "The instance types %s do not share a cpu architecture", strings.Join(instanceTypes, ", "))
+	}
+	return Filters{
+		VCpusRange:      vcpusRange,
+		MemoryRange:     memoryRange,
+		GpusRange:       gpusRange,
+		CPUArchitecture: architectures,
+	}, nil
+}
+
+// InstanceTypesFromLaunchTemplate returns the instance type of a launch template version. The launch template is an id or name
+// with an optional version after a colon, like my-template:3. The default version is used when no version is passed.
+func (itf Selector) InstanceTypesFromLaunchTemplate(launchTemplate string) ([]string, error) {
+	version := defaultLaunchTemplateVersion
+	if i := strings.LastIndex(launchTemplate, launchTemplateVersionSeparator); i != -1 {
+		launchTemplate, version = launchTemplate[:i], launchTemplate[i+1:]
+	}
+	input := &ec2.DescribeLaunchTemplateVersionsInput{Versions: []*string{aws.String(version)}}
+	if strings.HasPrefix(launchTemplate, launchTemplateIDPrefix) {
+		input.LaunchTemplateId = aws.String(launchTemplate)
+	} else {
+		input.LaunchTemplateName = aws.String(launchTemplate)
+	}
+	itf.debugf("calling DescribeLaunchTemplateVersions for %s version %s", launchTemplate, version)
+	output, err := itf.EC2.DescribeLaunchTemplateVersions(input)
+	if err != nil {
+		return nil, fmt.Errorf("Encountered an error when describing launch template %s: %w", launchTemplate, classifyAPIError(err))
+	}
+	if len(output.LaunchTemplateVersions) == 0 {
+		return nil, newClassifiedError(ErrNotFound, "The launch template %s version %s was not found", launchTemplate, version)
+	}
+	templateData := output.LaunchTemplateVersions[0].LaunchTemplateData
+	if templateData == nil || templateData.InstanceType == nil {
+		return nil, newClassifiedError(ErrNotFound, "The launch template %s version %s does not set an instance type", launchTemplate, version)
+	}
+	return []string{*templateData.InstanceType}, nil
+}
+
+// spanIntRange returns the range widened to include the value, or a range of only the value if the range is nil
+func spanIntRange(intRange *IntRangeFilter, value int) *IntRangeFilter {
+	if intRange == nil {
+		return &IntRangeFilter{LowerBound: value, UpperBound: value}
+	}
+	if value < intRange.LowerBound {
+		intRange.LowerBound = value
+	}
+	if value > intRange.UpperBound {
+		intRange.UpperBound = value
+	}
+	return intRange
+}
+
+// intersection returns the values of a which are also in b, in the order of a
+func intersection(a []string, b []string) []string {
+	inB := map[string]bool{}
+	for _, value := range b {
+		inB[value] = true
+	}
+	result := []string{}
+	for _, value := range a {
+		if inB[value] {
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,103 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestDiversify(t *testing.T) {
+	itf := selector.Selector{
+		EC2: setupMock(t, describeInstanceTypes, "25_instances.json"),
+	}
+	diversification, err := itf.Diversify([]string{"c4.2xlarge", "c5.2xlarge"}, selector.Filters{})
+	h.Ok(t, err)
+	h.Equals(t, &selector.IntRangeFilter{LowerBound: 8, UpperBound: 8}, diversification.Filters.VCpusRange)
+	h.Equals(t, &selector.IntRangeFilter{LowerBound: 15360, UpperBound: 16384}, diversification.Filters.MemoryRange)
+	h.Equals(t, []string{"x86_64"}, diversification.Filters.CPUArchitecture)
+	h.Equals(t, 1, len(diversification.InstanceTypes))
+	h.Equals(t, "c3.2xlarge", *diversification.InstanceTypes[0].InstanceType)
+}
+
+func TestDiversify_FiltersOverrideEnvelope(t *testing.T) {
+	itf := selector.Selector{
+		EC2: setupMock(t, describeInstanceTypes, "25_instances.json"),
+	}
+	diversification, err := itf.Diversify([]string{"c4.2xlarge"}, selector.Filters{
+		VCpusRange:  &selector.IntRangeFilter{LowerBound: 8, UpperBound: 16},
+		MemoryRange: &selector.IntRangeFilter{LowerBound: 15360, UpperBound: 32768},
+		MaxResults:  aws.Int(2),
+	})
+	h.Ok(t, err)
+	h.Equals(t, 2, len(diversification.InstanceTypes))
+	h.Equals(t, "c4.4xlarge", *diversification.InstanceTypes[0].InstanceType)
+	h.Equals(t, "c5.2xlarge", *diversification.InstanceTypes[1].InstanceType)
+}
+
+func TestFiltersFromInstanceTypes_NoCommonArchitecture(t *testing.T) {
+	itf := selector.Selector{
+		EC2: setupMock(t, describeInstanceTypes, "25_instances.json"),
+	}
+	_, err := itf.FiltersFromInstanceTypes([]string{"a1.large", "c5.large"})
+	h.Assert(t, errors.Is(err, selector.ErrInvalidFilters), "Error should be classified as invalid filters")
+	_, err = itf.FiltersFromInstanceTypes([]string{})
+	h.Nok(t, err)
+}
+
+func TestFiltersFromInstanceTypes_NotFound(t *testing.T) {
+	itf := selector.Selector{
+		EC2: setupMock(t, describeInstanceTypes, "25_instances.json"),
+	}
+	_, err := itf.FiltersFromInstanceTypes([]string{"m5.xlarge"})
+	h.Nok(t, err)
+}
+
+func TestInstanceTypesFromLaunchTemplate(t *testing.T) {
+	itf := selector.Selector{
+		EC2: mockedEC2{
+			DescribeLaunchTemplateVersionsResp: ec2.DescribeLaunchTemplateVersionsOutput{
+				LaunchTemplateVersions: []*ec2.LaunchTemplateVersion{
+					{LaunchTemplateData: &ec2.ResponseLaunchTemplateData{InstanceType: aws.String("m5.large")}},
+				},
+			},
+		},
+	}
+	instanceTypes, err := itf.InstanceTypesFromLaunchTemplate("lt-0123456789abcdef0:3")
+	h.Ok(t, err)
+	h.Equals(t, []string{"m5.large"}, instanceTypes)
+}
+
+func TestInstanceTypesFromLaunchTemplate_NoInstanceType(t *testing.T) {
+	itf := selector.Selector{
+		EC2: mockedEC2{
+			DescribeLaunchTemplateVersionsResp: ec2.DescribeLaunchTemplateVersionsOutput{
+				LaunchTemplateVersions: []*ec2.LaunchTemplateVersion{{LaunchTemplateData: &ec2.ResponseLaunchTemplateData{}}},
+			},
+		},
+	}
+	_, err := itf.InstanceTypesFromLaunchTemplate("my-template")
+	h.Assert(t, errors.Is(err, selector.ErrNotFound), "Error should be classified as not found")
+	itf.EC2 = mockedEC2{}
+	_, err = itf.InstanceTypesFromLaunchTemplate("my-template")
+	h.Assert(t, errors.Is(err, selector.ErrNotFound), "Error should be classified as not found")
+	itf.EC2 = mockedEC2{DescribeLaunchTemplateVersionsErr: errors.New("error")}
+	_, err = itf.InstanceTypesFromLaunchTemplate("my-template")
+	h.Nok(t, err)
+}
@@ -0,0 +1,33 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+// DryRun validates the filters and returns the EC2 API requests that filtering with them would issue without calling the EC2 API.
+// This can be used to check that the credentials are permitted to make the requests. Cached responses are not taken into account.
+func (itf Selector) DryRun(filters Filters) (*APICallPlan, error) {
+	if err := filters.Validate(); err != nil {
+		return nil, err
+	}
+	plan := &APICallPlan{
+		DescribeInstanceTypes: newInstanceTypesInput(filters.RawEC2Filters),
+	}
+	if location := getLocation(filters); location != "" {
+		instanceTypeOfferingsInput, err := newInstanceTypeOfferingsInput(location)
+		if err != nil {
+			return nil, err
+		}
+		plan.DescribeInstanceTypeOfferings = instanceTypeOfferingsInput
+	}
+	return plan, nil
+}
@@ -0,0 +1,60 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestDryRun(t *testing.T) {
+	itf := selector.Selector{
+		EC2: mockedEC2{
+			DescribeInstanceTypesErr:         errors.New("error"),
+			DescribeInstanceTypeOfferingsErr: errors.New("error"),
+		},
+	}
+	rawEC2Filters := []*ec2.Filter{{Name: aws.String("processor-info.supported-architecture"), Values: []*string{aws.String("arm64")}}}
+	plan, err := itf.DryRun(selector.Filters{
+		AvailabilityZone: aws.String("use1-az1"),
+		RawEC2Filters:    rawEC2Filters,
+	})
+	h.Ok(t, err)
+	h.Equals(t, rawEC2Filters, plan.DescribeInstanceTypes.Filters)
+	h.Equals(t, "availability-zone-id", *plan.DescribeInstanceTypeOfferings.LocationType)
+	h.Equals(t, "use1-az1", *plan.DescribeInstanceTypeOfferings.Filters[0].Values[0])
+}
+
+func TestDryRun_NoLocation(t *testing.T) {
+	itf := selector.Selector{
+		EC2: mockedEC2{},
+	}
+	plan, err := itf.DryRun(selector.Filters{})
+	h.Ok(t, err)
+	h.Assert(t, plan.DescribeInstanceTypeOfferings == nil, "DescribeInstanceTypeOfferings should not be planned without a location")
+	h.Assert(t, plan.DescribeInstanceTypes.Filters == nil, "DescribeInstanceTypes should not have filters")
+}
+
+func TestDryRun_InvalidLocation(t *testing.T) {
+	itf := selector.Selector{
+		EC2: mockedEC2{},
+	}
+	_, err := itf.DryRun(selector.Filters{Region: aws.String("not-a-location")})
+	h.Assert(t, errors.Is(err, selector.ErrInvalidLocation), "Should return ErrInvalidLocation")
+}
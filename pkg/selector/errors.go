@@ -0,0 +1,98 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+)
+
+// Errors returned by the selector can be matched with errors.Is to branch on the failure mode
+var (
+	// ErrNoMatches is returned by APIs which cannot return an empty result, like SuggestNewerGeneration.
+	// The Filter APIs return an empty list instead so that no matches is not treated as a failure.
+	ErrNoMatches = errors.New("no instance types matched")
+	// ErrInvalidLocation is returned when a location is not a valid zone-id, zone-name, or region name
+	ErrInvalidLocation = errors.New("invalid location")
+	// ErrInvalidFilters is returned when the filters contradict each other or contain invalid values
+	ErrInvalidFilters = errors.New("invalid filters")
+	// ErrNotFound is returned when a named instance type, instance, or preset does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrThrottled is returned when the EC2 API throttled the request
+	ErrThrottled = errors.New("request throttled")
+	// ErrUnauthorized is returned when the credentials are invalid or not allowed to call the EC2 API
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+// throttlingErrorCodes are the EC2 API error codes returned when a request is throttled
+var throttlingErrorCodes = map[string]bool{
+	"Throttling":               true,
+	"ThrottlingException":      true,
+	"RequestLimitExceeded":     true,
+	"RequestThrottled":         true,
+	"TooManyRequestsException": true,
+}
+
+// unauthorizedErrorCodes are the EC2 API error codes returned when the credentials are invalid or not permitted
+var unauthorizedErrorCodes = map[string]bool{
+	"AuthFailure":           true,
+	"UnauthorizedOperation": true,
+	"AccessDenied":          true,
+	"AccessDeniedException": true,
+	"InvalidClientTokenId":  true,
+	"ExpiredToken":          true,
+	"NoCredentialProviders": true,
+}
+
+// classifiedError wraps an error with the sentinel error matching its failure mode without changing the error message
+type classifiedError struct {
+	sentinel error
+	err      error
+}
+
+func (e classifiedError) Error() string {
+	return e.err.Error()
+}
+
+func (e classifiedError) Unwrap() error {
+	return e.err
+}
+
+// Is allows errors.Is to match the sentinel error
+func (e classifiedError) Is(target error) bool {
+	return target == e.sentinel
+}
+
+// newClassifiedError creates an error with the formatted message which matches the sentinel error with errors.Is
+func newClassifiedError(sentinel error, format string, args ...interface{}) error {
+	return classifiedError{sentinel: sentinel, err: fmt.Errorf(format, args...)}
+}
+
+// classifyAPIError wraps errors returned by the EC2 API so that throttling and authorization failures match
+// ErrThrottled and ErrUnauthorized. Other errors are returned as is.
+func classifyAPIError(err error) error {
+	var awsErr awserr.Error
+	if err == nil || !errors.As(err, &awsErr) {
+		return err
+	}
+	if throttlingErrorCodes[awsErr.Code()] {
+		return classifiedError{sentinel: ErrThrottled, err: err}
+	}
+	if unauthorizedErrorCodes[awsErr.Code()] {
+		return classifiedError{sentinel: ErrUnauthorized, err: err}
+	}
+	return err
+}
@@ -0,0 +1,53 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+)
+
+func TestClassifyAPIError(t *testing.T) {
+	err := classifyAPIError(awserr.New("RequestLimitExceeded", "Request limit exceeded.", nil))
+	h.Assert(t, errors.Is(err, ErrThrottled), "RequestLimitExceeded should match ErrThrottled")
+	h.Assert(t, !errors.Is(err, ErrUnauthorized), "RequestLimitExceeded should not match ErrUnauthorized")
+	h.Equals(t, "RequestLimitExceeded: Request limit exceeded.", err.Error())
+
+	err = classifyAPIError(awserr.New("UnauthorizedOperation", "You are not authorized to perform this operation.", nil))
+	h.Assert(t, errors.Is(err, ErrUnauthorized), "UnauthorizedOperation should match ErrUnauthorized")
+
+	err = fmt.Errorf("wrapped: %w", classifyAPIError(awserr.New("AuthFailure", "", nil)))
+	h.Assert(t, errors.Is(err, ErrUnauthorized), "Wrapped AuthFailure should match ErrUnauthorized")
+	var awsErr awserr.Error
+	h.Assert(t, errors.As(err, &awsErr), "The original aws error should still be accessible")
+}
+
+func TestClassifyAPIError_Unclassified(t *testing.T) {
+	h.Assert(t, classifyAPIError(nil) == nil, "nil should not be classified")
+	err := errors.New("error")
+	h.Equals(t, err, classifyAPIError(err))
+	awsErr := awserr.New("InvalidParameterValue", "", nil)
+	h.Equals(t, awsErr, classifyAPIError(awsErr))
+}
+
+func TestNewClassifiedError(t *testing.T) {
+	err := newClassifiedError(ErrNotFound, "The instance type %s was not found", "m5.xlarge")
+	h.Assert(t, errors.Is(err, ErrNotFound), "Should match ErrNotFound")
+	h.Assert(t, !errors.Is(err, ErrNoMatches), "Should not match ErrNoMatches")
+	h.Equals(t, "The instance type m5.xlarge was not found", err.Error())
+}
@@ -0,0 +1,125 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// LoadFiltersFile reads a YAML or JSON file and returns the Filters defined in it
+func LoadFiltersFile(path string) (Filters, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Filters{}, fmt.Errorf("Unable to read filters file %s: %w", path, err)
+	}
+	filters, err := ParseFilters(contents)
+	if err != nil {
+		return Filters{}, fmt.Errorf("Unable to parse filters file %s: %w", path, err)
+	}
+	return filters, nil
+}
+
+// ParseFilters accepts a YAML or JSON document and returns the Filters defined in it.
+// Unknown filter names result in an error so that typos are not silently ignored.
+func ParseFilters(contents []byte) (Filters, error) {
+	filters := Filters{}
+	filtersJSON, err := yaml.YAMLToJSON(contents)
+	if err != nil {
+		return Filters{}, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(filtersJSON))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&filters); err != nil {
+		return Filters{}, err
+	}
+	return filters, nil
+}
+
+// ToYAML returns the filters as a YAML document which can be loaded with ParseFilters
+func (f Filters) ToYAML() (string, error) {
+	filtersYAML, err := yaml.Marshal(f)
+	if err != nil {
+		return "", err
+	}
+	return string(filtersYAML), nil
+}
+
+// Merge returns a copy of the filters with every filter that is set in overrides applied on top
+func (f Filters) Merge(overrides Filters) Filters {
+	merged := f
+	mergedValue := reflect.ValueOf(&merged).Elem()
+	overridesValue := reflect.ValueOf(overrides)
+	for i := 0; i < overridesValue.NumField(); i++ {
+		if !overridesValue.Field(i).IsNil() {
+			mergedValue.Field(i).Set(overridesValue.Field(i))
+		}
+	}
+	return merged
+}
+
+// Validate checks the filters for contradictions which would never match any instance type,
+// like a range with a lower bound greater than its upper bound, and returns a descriptive error for the first one found
+func (f Filters) Validate() error {
+	filtersValue := reflect.ValueOf(f)
+	filtersType := filtersValue.Type()
+	for i := 0; i < filtersValue.NumField(); i++ {
+		intRange, ok := filtersValue.Field(i).Interface().(*IntRangeFilter)
+		if !ok || intRange == nil {
+			continue
+		}
+		filterName := strings.Split(filtersType.Field(i).Tag.Get("json"), ",")[0]
+		if intRange.LowerBound < 0 {
+			return newClassifiedError(ErrInvalidFilters, "Invalid filter %s: lower bound %d cannot be negative", filterName, intRange.LowerBound)
+		}
+		if intRange.LowerBound > intRange.UpperBound {
+			return newClassifiedError(ErrInvalidFilters, "Invalid filter %s: lower bound %d is greater than upper bound %d", filterName, intRange.LowerBound, intRange.UpperBound)
+		}
+	}
+	if f.GpuMemoryRange != nil && f.GpuMemoryGiBRange != nil {
+		return newClassifiedError(ErrInvalidFilters, "Invalid filters %s and %s: only one of them can be set", gpuMemoryRange, gpuMemoryGiBRange)
+	}
+	gpuMemory := f.GpuMemoryRange
+	if gpuMemory == nil {
+		gpuMemory = f.GpuMemoryGiBRange
+	}
+	if f.GpusRange != nil && f.GpusRange.UpperBound == 0 && gpuMemory != nil && gpuMemory.LowerBound > 0 {
+		return newClassifiedError(ErrInvalidFilters, "Invalid filters %s and %s: instance types without GPUs cannot have GPU memory", gpusRange, gpuMemoryRange)
+	}
+	if f.BareMetal != nil && *f.BareMetal && f.Burstable != nil && *f.Burstable {
+		return newClassifiedError(ErrInvalidFilters, "Invalid filters %s and %s: bare metal instance types are never burstable", baremetal, burstable)
+	}
+	if f.VCpusToMemoryRatio != nil && *f.VCpusToMemoryRatio <= 0 {
+		return newClassifiedError(ErrInvalidFilters, "Invalid filter %s: ratio %.2f must be greater than 0", vcpusToMemoryRatio, *f.VCpusToMemoryRatio)
+	}
+	if f.MaxResults != nil && *f.MaxResults < 0 {
+		return newClassifiedError(ErrInvalidFilters, "Invalid filter maxResults: %d cannot be negative", *f.MaxResults)
+	}
+	if _, err := parseSortBy(f.SortBy); err != nil {
+		return err
+	}
+	if _, err := lookupService(f.Service); err != nil {
+		return err
+	}
+	if f.MaxResultsPerFamily != nil && *f.MaxResultsPerFamily < 0 {
+		return newClassifiedError(ErrInvalidFilters, "Invalid filter maxResultsPerFamily: %d cannot be negative", *f.MaxResultsPerFamily)
+	}
+	return nil
+}
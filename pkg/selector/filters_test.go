@@ -0,0 +1,158 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+const (
+	filtersFiles = "Filters"
+)
+
+func TestLoadFiltersFile(t *testing.T) {
+	filters, err := selector.LoadFiltersFile(fmt.Sprintf("%s/%s/%s", mockFilesPath, filtersFiles, "policy.yaml"))
+	h.Ok(t, err)
+	h.Equals(t, selector.IntRangeFilter{LowerBound: 2, UpperBound: 4}, *filters.VCpusRange)
+	h.Equals(t, selector.IntRangeFilter{LowerBound: 4096, UpperBound: 8192}, *filters.MemoryRange)
+	h.Equals(t, []string{"x86_64"}, filters.CPUArchitecture)
+	h.Equals(t, true, *filters.CurrentGeneration)
+	h.Equals(t, 10, *filters.MaxResults)
+	h.Assert(t, filters.GpusRange == nil, "Filters not in the file should be nil")
+}
+
+func TestLoadFiltersFile_UnknownFilter(t *testing.T) {
+	_, err := selector.LoadFiltersFile(fmt.Sprintf("%s/%s/%s", mockFilesPath, filtersFiles, "unknown_filter.yaml"))
+	h.Nok(t, err)
+}
+
+func TestLoadFiltersFile_Missing(t *testing.T) {
+	_, err := selector.LoadFiltersFile(fmt.Sprintf("%s/%s/%s", mockFilesPath, filtersFiles, "does_not_exist.yaml"))
+	h.Nok(t, err)
+}
+
+func TestParseFilters_JSON(t *testing.T) {
+	filters, err := selector.ParseFilters([]byte(`{"hypervisor": ["nitro"], "gpusRange": {"lowerBound": 1, "upperBound": 2}}`))
+	h.Ok(t, err)
+	h.Equals(t, []string{"nitro"}, filters.Hypervisor)
+	h.Equals(t, selector.IntRangeFilter{LowerBound: 1, UpperBound: 2}, *filters.GpusRange)
+}
+
+func TestToYAML_RoundTrip(t *testing.T) {
+	filters := selector.Filters{
+		VCpusRange:         &selector.IntRangeFilter{LowerBound: 2, UpperBound: 2},
+		BareMetal:          aws.Bool(false),
+		UsageClass:         []string{"spot"},
+		VCpusToMemoryRatio: aws.Float64(2.0),
+	}
+	filtersYAML, err := filters.ToYAML()
+	h.Ok(t, err)
+	parsedFilters, err := selector.ParseFilters([]byte(filtersYAML))
+	h.Ok(t, err)
+	h.Equals(t, filters, parsedFilters)
+}
+
+func TestMerge(t *testing.T) {
+	filters := selector.Filters{
+		VCpusRange: &selector.IntRangeFilter{LowerBound: 2, UpperBound: 2},
+		UsageClass: []string{"spot"},
+	}
+	overrides := selector.Filters{
+		VCpusRange: &selector.IntRangeFilter{LowerBound: 4, UpperBound: 4},
+		Hypervisor: []string{"nitro"},
+	}
+	merged := filters.Merge(overrides)
+	h.Equals(t, selector.IntRangeFilter{LowerBound: 4, UpperBound: 4}, *merged.VCpusRange)
+	h.Equals(t, []string{"spot"}, merged.UsageClass)
+	h.Equals(t, []string{"nitro"}, merged.Hypervisor)
+	h.Equals(t, selector.IntRangeFilter{LowerBound: 2, UpperBound: 2}, *filters.VCpusRange)
+}
+
+func TestValidate(t *testing.T) {
+	filters := selector.Filters{
+		VCpusRange:     &selector.IntRangeFilter{LowerBound: 2, UpperBound: 4},
+		GpusRange:      &selector.IntRangeFilter{LowerBound: 0, UpperBound: 0},
+		GpuMemoryRange: &selector.IntRangeFilter{LowerBound: 0, UpperBound: 0},
+		BareMetal:      aws.Bool(true),
+		Burstable:      aws.Bool(false),
+	}
+	h.Ok(t, filters.Validate())
+	h.Ok(t, selector.Filters{}.Validate())
+}
+
+func TestValidate_Invalid(t *testing.T) {
+	invalidFilters := map[string]selector.Filters{
+		"memoryRange": {
+			MemoryRange: &selector.IntRangeFilter{LowerBound: 8, UpperBound: 4},
+		},
+		"vcpusRange": {
+			VCpusRange: &selector.IntRangeFilter{LowerBound: -1, UpperBound: 4},
+		},
+		"gpusRange and gpuMemoryRange": {
+			GpusRange:      &selector.IntRangeFilter{LowerBound: 0, UpperBound: 0},
+			GpuMemoryRange: &selector.IntRangeFilter{LowerBound: 1024, UpperBound: 2048},
+		},
+		"baremetal and burstable": {
+			BareMetal: aws.Bool(true),
+			Burstable: aws.Bool(true),
+		},
+		"vcpusToMemoryRatio": {
+			VCpusToMemoryRatio: aws.Float64(0),
+		},
+		"maxResults": {
+			MaxResults: aws.Int(-1),
+		},
+		"sortBy": {
+			SortBy: []string{"price:asc"},
+		},
+		"maxResultsPerFamily": {
+			MaxResultsPerFamily: aws.Int(-1),
+		},
+	}
+	for expectedMsg, filters := range invalidFilters {
+		err := filters.Validate()
+		h.Nok(t, err)
+		h.Assert(t, strings.Contains(err.Error(), expectedMsg), "Error %q should mention %s", err, expectedMsg)
+	}
+}
+
+func TestFilter_InvalidFilters(t *testing.T) {
+	itf := selector.Selector{
+		EC2: mockedEC2{},
+	}
+	_, err := itf.Filter(selector.Filters{
+		BareMetal: aws.Bool(true),
+		Burstable: aws.Bool(true),
+	})
+	h.Assert(t, errors.Is(err, selector.ErrInvalidFilters), "Should return ErrInvalidFilters")
+}
+
+func TestParseFilters_RawEC2Filters(t *testing.T) {
+	filters, err := selector.ParseFilters([]byte(`
+rawEC2Filters:
+  - Name: processor-info.sustained-clock-speed-in-ghz
+    Values: ["3.1", "3.5"]
+`))
+	h.Ok(t, err)
+	h.Assert(t, len(filters.RawEC2Filters) == 1, "Should parse 1 raw EC2 filter")
+	h.Equals(t, "processor-info.sustained-clock-speed-in-ghz", *filters.RawEC2Filters[0].Name)
+	h.Equals(t, []string{"3.1", "3.5"}, aws.StringValueSlice(filters.RawEC2Filters[0].Values))
+}
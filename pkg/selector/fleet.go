@@ -0,0 +1,50 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// dryRunOperationErrorCode is returned by the EC2 API when a DryRun request would have succeeded
+const dryRunOperationErrorCode = "DryRunOperation"
+
+// ValidateFleetConfig submits an EC2 Fleet config, like the ec2-fleet output format, to CreateFleet with DryRun set
+// so that malformed configs and missing permissions are found without launching instances.
+// It returns nil if the fleet would have been created, an ErrUnauthorized error if permissions are missing,
+// or an ErrInvalidFilters error if the config could not be parsed.
+func (itf Selector) ValidateFleetConfig(fleetConfigJSON string) error {
+	fleetInput := &ec2.CreateFleetInput{}
+	if err := json.Unmarshal([]byte(fleetConfigJSON), fleetInput); err != nil {
+		return newClassifiedError(ErrInvalidFilters, "Unable to parse the EC2 Fleet config: %v", err)
+	}
+	fleetInput.DryRun = aws.Bool(true)
+	itf.debugf("calling CreateFleet with DryRun")
+	_, err := itf.EC2.CreateFleet(fleetInput)
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) && awsErr.Code() == dryRunOperationErrorCode {
+		return nil
+	}
+	if err == nil {
+		// the EC2 API always returns an error for DryRun requests, but a success would still mean the config is valid
+		return nil
+	}
+	return fmt.Errorf("The EC2 Fleet config failed validation: %w", classifyAPIError(err))
+}
@@ -0,0 +1,45 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector/outputs"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestValidateFleetConfig(t *testing.T) {
+	fleetConfig := outputs.EC2FleetJSONOutput("lt-0123456789abcdef0")([]*ec2.InstanceTypeInfo{{InstanceType: aws.String("m5.large")}})[0]
+	itf := selector.Selector{EC2: mockedEC2{CreateFleetErr: awserr.New("DryRunOperation", "Request would have succeeded, but DryRun flag is set.", nil)}}
+	h.Ok(t, itf.ValidateFleetConfig(fleetConfig))
+
+	itf = selector.Selector{EC2: mockedEC2{CreateFleetErr: awserr.New("UnauthorizedOperation", "You are not authorized to perform this operation.", nil)}}
+	err := itf.ValidateFleetConfig(fleetConfig)
+	h.Assert(t, errors.Is(err, selector.ErrUnauthorized), "Should return ErrUnauthorized when permissions are missing")
+
+	itf = selector.Selector{EC2: mockedEC2{CreateFleetErr: awserr.New("InvalidLaunchTemplateId.Malformed", "The launch template ID is malformed.", nil)}}
+	h.Nok(t, itf.ValidateFleetConfig(fleetConfig))
+}
+
+func TestValidateFleetConfig_InvalidJSON(t *testing.T) {
+	itf := selector.Selector{EC2: mockedEC2{}}
+	err := itf.ValidateFleetConfig("not json")
+	h.Assert(t, errors.Is(err, selector.ErrInvalidFilters), "Should return ErrInvalidFilters when the config cannot be parsed")
+}
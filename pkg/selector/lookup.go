@@ -0,0 +1,116 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+const (
+	instanceTypeFilterKey = "instance-type"
+)
+
+// GetInstanceTypeDetails returns the full specs of the named instance type (i.e. m5.xlarge)
+// and the availability zones in the region where it is offered, with their zone IDs, without running the filters
+func (itf Selector) GetInstanceTypeDetails(instanceType string) (*InstanceTypeLookup, error) {
+	instanceTypeInfo, err := itf.describeInstanceType(instanceType)
+	if err != nil {
+		return nil, err
+	}
+
+	instanceTypeOfferingsInput := &ec2.DescribeInstanceTypeOfferingsInput{
+		LocationType: aws.String(zoneNameLocationType),
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String(instanceTypeFilterKey),
+				Values: []*string{aws.String(instanceType)},
+			},
+		},
+	}
+	availabilityZones := []string{}
+	itf.debugf("calling DescribeInstanceTypeOfferings for %s", instanceType)
+	err = itf.EC2.DescribeInstanceTypeOfferingsPages(instanceTypeOfferingsInput, func(page *ec2.DescribeInstanceTypeOfferingsOutput, lastPage bool) bool {
+		for _, offering := range page.InstanceTypeOfferings {
+			if aws.StringValue(offering.InstanceType) == instanceType {
+				availabilityZones = append(availabilityZones, aws.StringValue(offering.Location))
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Encountered an error when describing instance type offerings: %w", classifyAPIError(err))
+	}
+	sort.Strings(availabilityZones)
+	availabilityZoneIDs := map[string]string{}
+	// the zone ids are an annotation, so the offerings are still returned if the zones cannot be described
+	zones, err := itf.AvailabilityZones()
+	if err != nil {
+		itf.debugf("unable to resolve the zone ids of %s: %v", instanceType, err)
+	}
+	zoneIDs := map[string]string{}
+	for _, zone := range zones {
+		zoneIDs[zone.Name] = zone.ID
+	}
+	for _, availabilityZone := range availabilityZones {
+		if zoneID, ok := zoneIDs[availabilityZone]; ok {
+			availabilityZoneIDs[availabilityZone] = zoneID
+		}
+	}
+	return &InstanceTypeLookup{
+		InstanceTypeInfo:    instanceTypeInfo,
+		AvailabilityZones:   availabilityZones,
+		AvailabilityZoneIDs: availabilityZoneIDs,
+	}, nil
+}
+
+// describeInstanceType returns the detailed specs of the named instance type
+func (itf Selector) describeInstanceType(instanceType string) (*ec2.InstanceTypeInfo, error) {
+	if itf.DataProvider != nil {
+		instanceTypeInfoSlice, err := itf.DataProvider.InstanceTypes(nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, instanceTypeInfo := range instanceTypeInfoSlice {
+			if aws.StringValue(instanceTypeInfo.InstanceType) == instanceType {
+				return instanceTypeInfo, nil
+			}
+		}
+		return nil, newClassifiedError(ErrNotFound, "The instance type %s was not found", instanceType)
+	}
+	instanceTypesInput := &ec2.DescribeInstanceTypesInput{
+		InstanceTypes: []*string{aws.String(instanceType)},
+	}
+	var instanceTypeInfo *ec2.InstanceTypeInfo
+	itf.debugf("calling DescribeInstanceTypes for %s", instanceType)
+	err := itf.EC2.DescribeInstanceTypesPages(instanceTypesInput, func(page *ec2.DescribeInstanceTypesOutput, lastPage bool) bool {
+		for _, info := range page.InstanceTypes {
+			if aws.StringValue(info.InstanceType) == instanceType {
+				instanceTypeInfo = info
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Unable to describe the instance type %s: %w", instanceType, classifyAPIError(err))
+	}
+	if instanceTypeInfo == nil {
+		return nil, newClassifiedError(ErrNotFound, "The instance type %s was not found", instanceType)
+	}
+	return instanceTypeInfo, nil
+}
@@ -0,0 +1,59 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+)
+
+func TestGetInstanceTypeDetails(t *testing.T) {
+	ec2Mock := mockedEC2{
+		DescribeInstanceTypesResp:         setupMock(t, describeInstanceTypes, "t3_micro_and_p3_16xl.json").DescribeInstanceTypesResp,
+		DescribeInstanceTypeOfferingsResp: setupMock(t, describeInstanceTypeOfferings, "us-east-2a.json").DescribeInstanceTypeOfferingsResp,
+		DescribeAvailabilityZonesResp:     usEast2Zones(),
+	}
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	details, err := itf.GetInstanceTypeDetails("t3.micro")
+	h.Ok(t, err)
+	h.Equals(t, "t3.micro", *details.InstanceTypeInfo.InstanceType)
+	h.Equals(t, []string{"us-east-2a"}, details.AvailabilityZones)
+	h.Equals(t, map[string]string{"us-east-2a": "use2-az1"}, details.AvailabilityZoneIDs)
+}
+
+func TestGetInstanceTypeDetails_NotFound(t *testing.T) {
+	itf := selector.Selector{
+		EC2: setupMock(t, describeInstanceTypes, "t3_micro.json"),
+	}
+	details, err := itf.GetInstanceTypeDetails("m5.xlarge")
+	h.Nok(t, err)
+	h.Assert(t, details == nil, "Details should be nil")
+}
+
+func TestGetInstanceTypeDetails_Failure(t *testing.T) {
+	itf := selector.Selector{
+		EC2: mockedEC2{
+			DescribeInstanceTypesResp:        setupMock(t, describeInstanceTypes, "t3_micro.json").DescribeInstanceTypesResp,
+			DescribeInstanceTypeOfferingsErr: errors.New("error"),
+		},
+	}
+	details, err := itf.GetInstanceTypeDetails("t3.micro")
+	h.Nok(t, err)
+	h.Assert(t, details == nil, "Details should be nil")
+}
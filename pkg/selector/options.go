@@ -0,0 +1,148 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+// Option configures a Selector created with NewWithOptions
+type Option func(*selectorOptions)
+
+// selectorOptions holds the configuration collected from each Option before the Selector is built
+type selectorOptions struct {
+	ec2Client         ec2iface.EC2API
+	dataProvider      InstanceDataProvider
+	regionalEC2Client func(region string) ec2iface.EC2API
+	region            *string
+	endpoint          *string
+	roleARN           *string
+	externalID        *string
+	retryer           request.Retryer
+	logger            Logger
+	progress          *ProgressHooks
+	cacheTTL          time.Duration
+	cacheDir          string
+	userAgents        []string
+	handlerFns        []func(handlers *request.Handlers)
+}
+
+// WithCache caches EC2 API responses in memory for the ttl so that repeated filtering does not call the API each time
+func WithCache(ttl time.Duration) Option {
+	return func(opts *selectorOptions) {
+		opts.cacheTTL = ttl
+	}
+}
+
+// WithCacheDir persists the responses cached by WithCache to files in the dir so that they are reused by other Selectors
+// and processes until the ttl expires. Files are named by the region of the aws session, not the account, so the dir should not be
+// shared by sessions of different accounts. WithCacheDir has no effect without WithCache or with WithAssumeRole.
+func WithCacheDir(dir string) Option {
+	return func(opts *selectorOptions) {
+		opts.cacheDir = dir
+	}
+}
+
+// WithRegion overrides the region of the aws session passed to NewWithOptions
+func WithRegion(region string) Option {
+	return func(opts *selectorOptions) {
+		opts.region = aws.String(region)
+	}
+}
+
+// WithEndpoint sends the requests of the EC2 client created from the aws session to the endpoint URL instead of the endpoint
+// resolved for the region, like a VPC interface endpoint, a FIPS endpoint, or LocalStack for testing. The endpoint serves a single
+// region, so it is not used by the clients FilterAcrossRegions creates for other regions. Files written by WithCacheDir are named
+// by region, so an endpoint which serves different data than the region's endpoint should use a separate cache dir.
+func WithEndpoint(endpoint string) Option {
+	return func(opts *selectorOptions) {
+		opts.endpoint = aws.String(endpoint)
+	}
+}
+
+// WithAssumeRole uses credentials from assuming the IAM role for the EC2 clients created from the aws session,
+// which allows querying instance type availability in another account. The externalID is optional and ignored if empty.
+func WithAssumeRole(roleARN string, externalID string) Option {
+	return func(opts *selectorOptions) {
+		opts.roleARN = aws.String(roleARN)
+		if externalID != "" {
+			opts.externalID = aws.String(externalID)
+		}
+	}
+}
+
+// WithEC2Client uses the provided EC2 client instead of creating one from the aws session.
+// WithRegion, WithEndpoint, WithRetryer, WithAssumeRole, WithUserAgent, and WithRequestHandlers have no effect when an EC2 client is provided.
+func WithEC2Client(ec2Client ec2iface.EC2API) Option {
+	return func(opts *selectorOptions) {
+		opts.ec2Client = ec2Client
+	}
+}
+
+// WithDataProvider retrieves the instance type specs and offerings the filters are executed against from the provider
+// instead of the EC2 API, like a StaticDataProvider serving a snapshot file. WithCache has no effect on the provider.
+func WithDataProvider(provider InstanceDataProvider) Option {
+	return func(opts *selectorOptions) {
+		opts.dataProvider = provider
+	}
+}
+
+// WithRegionalEC2Clients uses the provided func to create the EC2 client for each region queried by FilterAcrossRegions
+// instead of creating them from the aws session
+func WithRegionalEC2Clients(regionalEC2Client func(region string) ec2iface.EC2API) Option {
+	return func(opts *selectorOptions) {
+		opts.regionalEC2Client = regionalEC2Client
+	}
+}
+
+// WithLogger sets the Logger which receives debug logs while filtering
+func WithLogger(logger Logger) Option {
+	return func(opts *selectorOptions) {
+		opts.logger = logger
+	}
+}
+
+// WithProgressHooks sets the callbacks which are invoked while filtering to report progress
+func WithProgressHooks(hooks ProgressHooks) Option {
+	return func(opts *selectorOptions) {
+		opts.progress = &hooks
+	}
+}
+
+// WithRetryer sets the retryer used by the EC2 client created from the aws session
+func WithRetryer(retryer request.Retryer) Option {
+	return func(opts *selectorOptions) {
+		opts.retryer = retryer
+	}
+}
+
+// WithUserAgent appends the segment, like "my-tool/1.0", to the user agent of the EC2 clients created from the aws session
+// after the instance-selector segment
+func WithUserAgent(segment string) Option {
+	return func(opts *selectorOptions) {
+		opts.userAgents = append(opts.userAgents, segment)
+	}
+}
+
+// WithRequestHandlers calls handlersFn with the request handlers of each EC2 client created from the aws session
+// so that handlers can be added for tracing or auditing API calls
+func WithRequestHandlers(handlersFn func(handlers *request.Handlers)) Option {
+	return func(opts *selectorOptions) {
+		opts.handlerFns = append(opts.handlerFns, handlersFn)
+	}
+}
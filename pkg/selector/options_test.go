@@ -0,0 +1,238 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	itf := selector.NewWithOptions(session.Must(session.NewSession()),
+		selector.WithRegion("us-west-2"),
+		selector.WithRetryer(client.DefaultRetryer{NumMaxRetries: 1}),
+	)
+	h.Assert(t, itf != nil, "selector instance created without error")
+	ec2Client, ok := itf.EC2.(*ec2.EC2)
+	h.Assert(t, ok, "Should create an EC2 client from the session")
+	h.Equals(t, "us-west-2", *ec2Client.Config.Region)
+}
+
+func TestNewWithOptions_Endpoint(t *testing.T) {
+	sess := session.Must(session.NewSession(&aws.Config{Region: aws.String("us-east-1")}))
+	itf := selector.NewWithOptions(sess, selector.WithEndpoint("http://localhost:4566"))
+	ec2Client, ok := itf.EC2.(*ec2.EC2)
+	h.Assert(t, ok, "Should create an EC2 client from the session")
+	h.Equals(t, "http://localhost:4566", ec2Client.Endpoint)
+	req, _ := ec2Client.DescribeInstanceTypesRequest(&ec2.DescribeInstanceTypesInput{})
+	h.Equals(t, "localhost:4566", req.HTTPRequest.URL.Host)
+
+	defaultClient := selector.NewWithOptions(sess).EC2.(*ec2.EC2)
+	h.Equals(t, "https://ec2.us-east-1.amazonaws.com", defaultClient.Endpoint)
+}
+
+func TestNewWithOptions_EC2ClientAndLogger(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypes, "t3_micro.json")
+	logs := []string{}
+	itf := selector.NewWithOptions(nil,
+		selector.WithEC2Client(ec2Mock),
+		selector.WithLogger(selector.LoggerFn(func(format string, args ...interface{}) {
+			logs = append(logs, fmt.Sprintf(format, args...))
+		})),
+	)
+	results, err := itf.Filter(selector.Filters{
+		VCpusRange: &selector.IntRangeFilter{LowerBound: 2, UpperBound: 2},
+	})
+	h.Ok(t, err)
+	h.Equals(t, []string{"t3.micro"}, results)
+	h.Assert(t, len(logs) > 0, "Should send debug logs to the logger")
+}
+
+func TestNewWithOptions_Cache(t *testing.T) {
+	ec2Mock := mockedEC2{
+		DescribeInstanceTypesResp:         setupMock(t, describeInstanceTypes, "t3_micro.json").DescribeInstanceTypesResp,
+		DescribeInstanceTypeOfferingsResp: setupMock(t, describeInstanceTypeOfferings, "us-east-2a.json").DescribeInstanceTypeOfferingsResp,
+	}
+	itf := selector.NewWithOptions(nil, selector.WithEC2Client(ec2Mock), selector.WithCache(time.Hour))
+	filters := selector.Filters{
+		VCpusRange:       &selector.IntRangeFilter{LowerBound: 2, UpperBound: 2},
+		AvailabilityZone: aws.String("us-east-2a"),
+	}
+	results, err := itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"t3.micro"}, results)
+
+	// The cached responses should be used instead of calling the failing EC2 client
+	itf.EC2 = mockedEC2{
+		DescribeInstanceTypesErr:         errors.New("error"),
+		DescribeInstanceTypeOfferingsErr: errors.New("error"),
+	}
+	results, err = itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"t3.micro"}, results)
+	h.Equals(t, selector.CacheStats{Hits: 2, Misses: 2}, itf.CacheStats())
+}
+
+func TestNewWithOptions_CacheExpired(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypes, "t3_micro.json")
+	itf := selector.NewWithOptions(nil, selector.WithEC2Client(ec2Mock), selector.WithCache(time.Nanosecond))
+	_, err := itf.Filter(selector.Filters{})
+	h.Ok(t, err)
+	time.Sleep(time.Millisecond)
+
+	itf.EC2 = mockedEC2{
+		DescribeInstanceTypesErr: errors.New("error"),
+	}
+	_, err = itf.Filter(selector.Filters{})
+	h.Nok(t, err)
+}
+
+func TestNewWithOptions_CacheDir(t *testing.T) {
+	cacheDir, err := ioutil.TempDir("", "ec2-instance-selector-cache")
+	h.Ok(t, err)
+	defer os.RemoveAll(cacheDir)
+	ec2Mock := mockedEC2{
+		DescribeInstanceTypesResp:         setupMock(t, describeInstanceTypes, "t3_micro.json").DescribeInstanceTypesResp,
+		DescribeInstanceTypeOfferingsResp: setupMock(t, describeInstanceTypeOfferings, "us-east-2a.json").DescribeInstanceTypeOfferingsResp,
+	}
+	filters := selector.Filters{
+		VCpusRange:       &selector.IntRangeFilter{LowerBound: 2, UpperBound: 2},
+		AvailabilityZone: aws.String("us-east-2a"),
+	}
+	itf := selector.NewWithOptions(nil, selector.WithEC2Client(ec2Mock), selector.WithCache(time.Hour), selector.WithCacheDir(cacheDir))
+	results, err := itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"t3.micro"}, results)
+
+	// A new selector with the same cache dir should use the persisted responses instead of calling the failing EC2 client
+	failingEC2Mock := mockedEC2{
+		DescribeInstanceTypesErr:         errors.New("error"),
+		DescribeInstanceTypeOfferingsErr: errors.New("error"),
+	}
+	itf = selector.NewWithOptions(nil, selector.WithEC2Client(failingEC2Mock), selector.WithCache(time.Hour), selector.WithCacheDir(cacheDir))
+	results, err = itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"t3.micro"}, results)
+
+	// After clearing the cache dir, the EC2 client should be called again
+	h.Ok(t, selector.ClearCacheDir(cacheDir))
+	itf = selector.NewWithOptions(nil, selector.WithEC2Client(failingEC2Mock), selector.WithCache(time.Hour), selector.WithCacheDir(cacheDir))
+	_, err = itf.Filter(filters)
+	h.Nok(t, err)
+}
+
+func TestNewWithOptions_CacheDirAssumeRole(t *testing.T) {
+	cacheDir, err := ioutil.TempDir("", "ec2-instance-selector-cache")
+	h.Ok(t, err)
+	defer os.RemoveAll(cacheDir)
+	sess := session.Must(session.NewSession(&aws.Config{Region: aws.String("us-east-2")}))
+	itf := selector.NewWithOptions(sess,
+		selector.WithAssumeRole("arn:aws:iam::123456789012:role/test", ""),
+		selector.WithEC2Client(setupMock(t, describeInstanceTypes, "t3_micro.json")),
+		selector.WithCache(time.Hour),
+		selector.WithCacheDir(cacheDir),
+	)
+	_, err = itf.Filter(selector.Filters{})
+	h.Ok(t, err)
+
+	// the responses are only cached in memory since the files are not named by account
+	metadata, err := selector.CacheDirMetadata(cacheDir)
+	h.Ok(t, err)
+	h.Equals(t, 0, len(metadata))
+	h.Equals(t, selector.CacheStats{Hits: 0, Misses: 1}, itf.CacheStats())
+}
+
+func TestCacheDirMetadata(t *testing.T) {
+	cacheDir, err := ioutil.TempDir("", "ec2-instance-selector-cache")
+	h.Ok(t, err)
+	defer os.RemoveAll(cacheDir)
+	h.Ok(t, ioutil.WriteFile(filepath.Join(cacheDir, "config.json"), []byte("{}"), 0600))
+	ec2Mock := mockedEC2{
+		DescribeInstanceTypesResp:         setupMock(t, describeInstanceTypes, "t3_micro.json").DescribeInstanceTypesResp,
+		DescribeInstanceTypeOfferingsResp: setupMock(t, describeInstanceTypeOfferings, "us-east-2a.json").DescribeInstanceTypeOfferingsResp,
+	}
+	sess := session.Must(session.NewSession(&aws.Config{Region: aws.String("us-east-2")}))
+	itf := selector.NewWithOptions(sess, selector.WithEC2Client(ec2Mock), selector.WithCache(time.Hour), selector.WithCacheDir(cacheDir))
+	_, err = itf.Filter(selector.Filters{AvailabilityZone: aws.String("us-east-2a")})
+	h.Ok(t, err)
+
+	metadata, err := selector.CacheDirMetadata(cacheDir)
+	h.Ok(t, err)
+	h.Equals(t, 2, len(metadata))
+	h.Equals(t, "instance-types", metadata[0].Data)
+	h.Equals(t, "us-east-2", metadata[0].Region)
+	h.Equals(t, "aws", metadata[0].Partition)
+	h.Equals(t, 1, metadata[0].InstanceTypes)
+	h.Equals(t, "offerings", metadata[1].Data)
+	h.Equals(t, "us-east-2a", metadata[1].Location)
+	h.Assert(t, !metadata[1].Refreshed.IsZero(), "Refreshed should be recorded")
+	h.Assert(t, !metadata[1].Expired, "Cache file should not be expired")
+}
+
+func TestClearCacheDir_KeepsOtherFiles(t *testing.T) {
+	cacheDir, err := ioutil.TempDir("", "ec2-instance-selector-cache")
+	h.Ok(t, err)
+	defer os.RemoveAll(cacheDir)
+	otherFile := filepath.Join(cacheDir, "config.json")
+	h.Ok(t, ioutil.WriteFile(otherFile, []byte("{}"), 0600))
+	h.Ok(t, selector.ClearCacheDir(cacheDir))
+	_, err = os.Stat(otherFile)
+	h.Ok(t, err)
+	h.Ok(t, selector.ClearCacheDir(filepath.Join(cacheDir, "does-not-exist")))
+}
+
+func TestNewWithOptions_AssumeRole(t *testing.T) {
+	sess := session.Must(session.NewSession())
+	itf := selector.NewWithOptions(sess, selector.WithAssumeRole("arn:aws:iam::123456789012:role/test", "external-id"))
+	ec2Client, ok := itf.EC2.(*ec2.EC2)
+	h.Assert(t, ok, "Should create an EC2 client from the session")
+	h.Assert(t, ec2Client.Config.Credentials != sess.Config.Credentials, "Should use assumed role credentials instead of the session credentials")
+}
+
+func TestNewWithOptions_UserAgentAndRequestHandlers(t *testing.T) {
+	sess := session.Must(session.NewSession(&aws.Config{Region: aws.String("us-east-1")}))
+	sessionBuildHandlers := sess.Handlers.Build.Len()
+	builtOperations := []string{}
+	itf := selector.NewWithOptions(sess,
+		selector.WithUserAgent("my-tool/1.0"),
+		selector.WithRequestHandlers(func(handlers *request.Handlers) {
+			handlers.Build.PushBack(func(r *request.Request) {
+				builtOperations = append(builtOperations, r.Operation.Name)
+			})
+		}),
+	)
+	ec2Client, ok := itf.EC2.(*ec2.EC2)
+	h.Assert(t, ok, "Should create an EC2 client from the session")
+	req, _ := ec2Client.DescribeInstanceTypesRequest(&ec2.DescribeInstanceTypesInput{})
+	h.Ok(t, req.Build())
+	userAgent := req.HTTPRequest.Header.Get("User-Agent")
+	h.Assert(t, strings.Contains(userAgent, "instance-selector-v"), "User agent should contain the instance-selector segment: "+userAgent)
+	h.Assert(t, strings.HasSuffix(userAgent, "my-tool/1.0"), "User agent should end with the custom segment: "+userAgent)
+	h.Equals(t, []string{"DescribeInstanceTypes"}, builtOperations)
+	h.Equals(t, sessionBuildHandlers, sess.Handlers.Build.Len())
+}
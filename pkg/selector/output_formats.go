@@ -0,0 +1,161 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector/outputs"
+)
+
+// Built-in output format names which can be passed to OutputFormat
+const (
+	// OutputFormatSimple outputs the instance type names
+	OutputFormatSimple = "simple"
+	// OutputFormatVerbose outputs the full instance type specs as JSON
+	OutputFormatVerbose = "verbose"
+	// OutputFormatTable outputs a table of instance types with vcpus and memory
+	OutputFormatTable = "table"
+	// OutputFormatTableWide outputs a table of instance types with detailed specs
+	OutputFormatTableWide = "table-wide"
+	// OutputFormatWide is a shorter name for OutputFormatTableWide
+	OutputFormatWide = "wide"
+	// OutputFormatJSON outputs the full instance type specs as JSON
+	OutputFormatJSON = "json"
+	// OutputFormatYAML outputs the full instance type specs as YAML
+	OutputFormatYAML = "yaml"
+	// OutputFormatCSV outputs the detailed specs of the wide table as CSV
+	OutputFormatCSV = "csv"
+	// OutputFormatASG outputs an ASG MixedInstancesPolicy in the JSON syntax accepted by the AWS CLI
+	OutputFormatASG = "asg"
+	// OutputFormatKarpenter outputs Karpenter provisioner requirements in YAML syntax
+	OutputFormatKarpenter = "karpenter"
+	// OutputFormatCfnJSON outputs an ASG MixedInstancesPolicy in CloudFormation JSON syntax
+	OutputFormatCfnJSON = "cfn-json"
+	// OutputFormatCfnYAML outputs an ASG MixedInstancesPolicy in CloudFormation YAML syntax
+	OutputFormatCfnYAML = "cfn-yaml"
+	// OutputFormatEC2Fleet outputs a spot EC2 Fleet config in the JSON syntax accepted by the AWS CLI
+	OutputFormatEC2Fleet = "ec2-fleet"
+	// OutputFormatTerraformHCL outputs an ASG MixedInstancesPolicy in Terraform HCL syntax
+	OutputFormatTerraformHCL = "terraform-hcl"
+)
+
+// OutputPluginPrefix is the prefix of executables which provide an output format named by the rest of the executable name,
+// like ec2-instance-selector-output-cmdb for the cmdb output format
+const OutputPluginPrefix = "ec2-instance-selector-output-"
+
+var (
+	outputFormatsMu sync.RWMutex
+	// outputFormats maps an output format name to the output which renders it
+	outputFormats = map[string]InstanceTypesOutput{
+		OutputFormatSimple:       InstanceTypesOutputFn(outputs.SimpleInstanceTypeOutput),
+		OutputFormatVerbose:      InstanceTypesOutputFn(outputs.VerboseInstanceTypeOutput),
+		OutputFormatTable:        InstanceTypesOutputFn(outputs.TableOutputShort),
+		OutputFormatTableWide:    InstanceTypesOutputFn(outputs.TableOutputWide),
+		OutputFormatWide:         InstanceTypesOutputFn(outputs.TableOutputWide),
+		OutputFormatJSON:         InstanceTypesOutputFn(outputs.VerboseInstanceTypeOutput),
+		OutputFormatYAML:         InstanceTypesOutputFn(outputs.YAMLInstanceTypeOutput),
+		OutputFormatCSV:          InstanceTypesOutputFn(outputs.CSVOutput),
+		OutputFormatASG:          InstanceTypesOutputFn(outputs.AutoScalingGroupMixedInstancesPolicyJSONOutput),
+		OutputFormatKarpenter:    InstanceTypesOutputFn(outputs.KarpenterRequirementsYAMLOutput),
+		OutputFormatCfnJSON:      InstanceTypesOutputFn(outputs.CloudFormationSpotMixedInstancesPolicyJSONOutput),
+		OutputFormatCfnYAML:      InstanceTypesOutputFn(outputs.CloudFormationSpotMixedInstancesPolicyYAMLOutput),
+		OutputFormatTerraformHCL: InstanceTypesOutputFn(outputs.TerraformSpotMixedInstancesPolicyHCLOutput),
+		OutputFormatEC2Fleet:     InstanceTypesOutputFn(outputs.EC2FleetJSONOutput("")),
+	}
+)
+
+// RegisterOutputFormat registers an output under the name so that it can be looked up with OutputFormat.
+// An error is returned if the name is empty or already registered.
+func RegisterOutputFormat(name string, output InstanceTypesOutput) error {
+	if name == "" {
+		return fmt.Errorf("An output format name is required")
+	}
+	if output == nil {
+		return fmt.Errorf("The output format %s must have an output", name)
+	}
+	outputFormatsMu.Lock()
+	defer outputFormatsMu.Unlock()
+	if _, ok := outputFormats[name]; ok {
+		return fmt.Errorf("The output format %s is already registered", name)
+	}
+	outputFormats[name] = output
+	return nil
+}
+
+// OutputFormat returns the output registered under the name
+func OutputFormat(name string) (InstanceTypesOutput, error) {
+	outputFormatsMu.RLock()
+	output, ok := outputFormats[name]
+	outputFormatsMu.RUnlock()
+	if !ok {
+		return nil, newClassifiedError(ErrNotFound, "The output format %s does not exist. Valid output formats are: %s", name, strings.Join(OutputFormatNames(), ", "))
+	}
+	return output, nil
+}
+
+// OutputFormatNames returns the sorted names of all registered output formats
+func OutputFormatNames() []string {
+	outputFormatsMu.RLock()
+	defer outputFormatsMu.RUnlock()
+	names := []string{}
+	for name := range outputFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RegisterOutputPlugins registers an output format for each executable in the dirs of pathList, like the PATH environment variable,
+// whose name starts with OutputPluginPrefix. The executable receives the full instance type specs as a JSON array on stdin
+// and its stdout is the output. Like the PATH, the first executable found for a name is used,
+// and registered output formats, including the built-in ones, cannot be replaced by a plugin.
+// The names of the registered output formats are returned sorted.
+func RegisterOutputPlugins(pathList string) []string {
+	registered := []string{}
+	for _, dir := range filepath.SplitList(pathList) {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			name := strings.TrimPrefix(file.Name(), OutputPluginPrefix)
+			path := filepath.Join(dir, file.Name())
+			if name == file.Name() || name == "" || !isExecutable(path) {
+				continue
+			}
+			pluginOutput := InstanceTypesOutputFn(outputs.PluginOutput(path))
+			if err := RegisterOutputFormat(name, pluginOutput); err == nil {
+				registered = append(registered, name)
+			}
+		}
+	}
+	sort.Strings(registered)
+	return registered
+}
+
+// isExecutable returns true if the path, or the file it links to, is a regular file with any execute permission bit set
+func isExecutable(path string) bool {
+	file, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return file.Mode().IsRegular() && file.Mode().Perm()&0111 != 0
+}
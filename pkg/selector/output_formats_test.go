@@ -0,0 +1,88 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestOutputFormat(t *testing.T) {
+	for _, name := range []string{"simple", "verbose", "table", "table-wide", "wide", "json", "yaml", "csv", "asg", "karpenter", "cfn-json", "cfn-yaml", "terraform-hcl", "ec2-fleet"} {
+		output, err := selector.OutputFormat(name)
+		h.Ok(t, err)
+		h.Assert(t, output != nil, "Should return an output for "+name)
+	}
+}
+
+func TestOutputFormat_Unknown(t *testing.T) {
+	_, err := selector.OutputFormat("does-not-exist")
+	h.Assert(t, errors.Is(err, selector.ErrNotFound), "Should return ErrNotFound for an unknown output format")
+}
+
+func TestRegisterOutputFormat(t *testing.T) {
+	countOutput := selector.InstanceTypesOutputFn(func(instanceTypes []*ec2.InstanceTypeInfo) []string {
+		return []string{"count"}
+	})
+	h.Ok(t, selector.RegisterOutputFormat("test-count", countOutput))
+	output, err := selector.OutputFormat("test-count")
+	h.Ok(t, err)
+	h.Equals(t, []string{"count"}, output.Output(nil))
+	h.Assert(t, contains(selector.OutputFormatNames(), "test-count"), "Registered output format should be in the output format names")
+
+	h.Nok(t, selector.RegisterOutputFormat("test-count", countOutput))
+	h.Nok(t, selector.RegisterOutputFormat(selector.OutputFormatTable, countOutput))
+	h.Nok(t, selector.RegisterOutputFormat("", countOutput))
+	h.Nok(t, selector.RegisterOutputFormat("test-nil", nil))
+}
+
+func contains(names []string, target string) bool {
+	for _, name := range names {
+		if name == target {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRegisterOutputPlugins(t *testing.T) {
+	dir, err := ioutil.TempDir("", "output-plugins")
+	h.Ok(t, err)
+	defer os.RemoveAll(dir)
+	// the plugin counts the instance types in the JSON array it receives on stdin
+	plugin := "#!/bin/sh\ngrep -o '\"InstanceType\"' | wc -l | tr -d ' '\n"
+	h.Ok(t, ioutil.WriteFile(filepath.Join(dir, selector.OutputPluginPrefix+"test-plugin-count"), []byte(plugin), 0755))
+	h.Ok(t, ioutil.WriteFile(filepath.Join(dir, selector.OutputPluginPrefix+"test-plugin-not-executable"), []byte(plugin), 0644))
+	h.Ok(t, ioutil.WriteFile(filepath.Join(dir, selector.OutputPluginPrefix+selector.OutputFormatTable), []byte(plugin), 0755))
+	h.Ok(t, ioutil.WriteFile(filepath.Join(dir, selector.OutputPluginPrefix+"test-plugin-fails"), []byte("#!/bin/sh\nexit 1\n"), 0755))
+
+	registered := selector.RegisterOutputPlugins(dir + string(os.PathListSeparator) + filepath.Join(dir, "does-not-exist"))
+	h.Equals(t, []string{"test-plugin-count", "test-plugin-fails"}, registered)
+	output, err := selector.OutputFormat("test-plugin-count")
+	h.Ok(t, err)
+	h.Equals(t, []string{"2"}, output.Output([]*ec2.InstanceTypeInfo{{InstanceType: aws.String("m5.large")}, {InstanceType: aws.String("c5.large")}}))
+	output, err = selector.OutputFormat("test-plugin-fails")
+	h.Ok(t, err)
+	h.Equals(t, []string{}, output.Output(nil))
+	_, err = selector.OutputFormat("test-plugin-not-executable")
+	h.Nok(t, err)
+}
@@ -16,12 +16,15 @@ package outputs
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/ghodss/yaml"
 )
@@ -35,6 +38,59 @@ func SimpleInstanceTypeOutput(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []s
 	return instanceTypeStrings
 }
 
+// ANSI escape codes used by ColorInstanceTypeOutput
+const (
+	colorReset  = "\033[0m"
+	colorYellow = "\033[33m"
+	colorGray   = "\033[90m"
+	colorCyan   = "\033[36m"
+)
+
+const upToNetworkPerformancePrefix = "Up to "
+
+// ColorInstanceTypeOutput is an OutputFn which outputs instance type names highlighted with ANSI colors and followed by
+// their caveats, so that interactive users notice them. Burstable instance types are yellow, previous generation
+// instance types are gray, and instance types with "Up to" network performance are cyan.
+func ColorInstanceTypeOutput(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []string {
+	instanceTypeStrings := []string{}
+	for _, instanceTypeInfo := range instanceTypeInfoSlice {
+		caveats := []string{}
+		color := ""
+		if aws.BoolValue(instanceTypeInfo.BurstablePerformanceSupported) {
+			caveats = append(caveats, "burstable")
+			color = colorYellow
+		}
+		if instanceTypeInfo.CurrentGeneration != nil && !*instanceTypeInfo.CurrentGeneration {
+			caveats = append(caveats, "previous generation")
+			if color == "" {
+				color = colorGray
+			}
+		}
+		if instanceTypeInfo.NetworkInfo != nil && strings.HasPrefix(aws.StringValue(instanceTypeInfo.NetworkInfo.NetworkPerformance), upToNetworkPerformancePrefix) {
+			caveats = append(caveats, strings.ToLower(*instanceTypeInfo.NetworkInfo.NetworkPerformance)+" network")
+			if color == "" {
+				color = colorCyan
+			}
+		}
+		if len(caveats) == 0 {
+			instanceTypeStrings = append(instanceTypeStrings, *instanceTypeInfo.InstanceType)
+			continue
+		}
+		instanceTypeStrings = append(instanceTypeStrings, fmt.Sprintf("%s%s (%s)%s", color, *instanceTypeInfo.InstanceType, strings.Join(caveats, ", "), colorReset))
+	}
+	return instanceTypeStrings
+}
+
+// JSONInstanceTypeNamesOutput is an OutputFn which outputs the instance type names as a single JSON array
+func JSONInstanceTypeNamesOutput(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []string {
+	output, err := json.Marshal(SimpleInstanceTypeOutput(instanceTypeInfoSlice))
+	if err != nil {
+		log.Printf("Unable to convert instance type names to JSON: %v\n", err)
+		return []string{}
+	}
+	return []string{string(output)}
+}
+
 // VerboseInstanceTypeOutput is an OutputFn which outputs a slice of instance type names
 func VerboseInstanceTypeOutput(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []string {
 	output, err := json.MarshalIndent(instanceTypeInfoSlice, "", "    ")
@@ -48,6 +104,216 @@ func VerboseInstanceTypeOutput(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []
 	return []string{string(output)}
 }
 
+// VerboseInstanceTypeWithZonesOutput returns an OutputFn which outputs the full instance type specs as JSON
+// with the availability zones each instance type is offered in
+func VerboseInstanceTypeWithZonesOutput(zones map[string][]string) func([]*ec2.InstanceTypeInfo) []string {
+	return func(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []string {
+		if len(instanceTypeInfoSlice) == 0 {
+			return []string{}
+		}
+		output, err := json.MarshalIndent(withZones(instanceTypeInfoSlice, zones), "", "    ")
+		if err != nil {
+			log.Println("Unable to convert instance type info to JSON")
+			return []string{}
+		}
+		return []string{string(output)}
+	}
+}
+
+// YAMLInstanceTypeWithZonesOutput returns an OutputFn which outputs the full instance type specs in YAML syntax
+// with the availability zones each instance type is offered in
+func YAMLInstanceTypeWithZonesOutput(zones map[string][]string) func([]*ec2.InstanceTypeInfo) []string {
+	return func(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []string {
+		if len(instanceTypeInfoSlice) == 0 {
+			return []string{}
+		}
+		output, err := yaml.Marshal(withZones(instanceTypeInfoSlice, zones))
+		if err != nil {
+			log.Printf("Unable to convert instance type info to YAML: %v\n", err)
+			return []string{}
+		}
+		return []string{string(output)}
+	}
+}
+
+// withZones pairs each instance type's specs with the availability zones it is offered in
+func withZones(instanceTypeInfoSlice []*ec2.InstanceTypeInfo, zones map[string][]string) []InstanceTypeInfoWithZones {
+	instanceTypesWithZones := []InstanceTypeInfoWithZones{}
+	for _, instanceTypeInfo := range instanceTypeInfoSlice {
+		instanceTypeZones := zones[aws.StringValue(instanceTypeInfo.InstanceType)]
+		if instanceTypeZones == nil {
+			instanceTypeZones = []string{}
+		}
+		instanceTypesWithZones = append(instanceTypesWithZones, InstanceTypeInfoWithZones{
+			InstanceTypeInfo:  instanceTypeInfo,
+			AvailabilityZones: instanceTypeZones,
+		})
+	}
+	return instanceTypesWithZones
+}
+
+// YAMLInstanceTypeOutput is an OutputFn which outputs the full instance type specs in YAML syntax
+func YAMLInstanceTypeOutput(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []string {
+	if len(instanceTypeInfoSlice) == 0 {
+		return []string{}
+	}
+	output, err := yaml.Marshal(instanceTypeInfoSlice)
+	if err != nil {
+		log.Printf("Unable to convert instance type info to YAML: %v\n", err)
+		return []string{}
+	}
+	return []string{string(output)}
+}
+
+// CSVOutput is an OutputFn which outputs the instance type specs of the wide table in CSV syntax with a header row
+func CSVOutput(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []string {
+	if len(instanceTypeInfoSlice) == 0 {
+		return nil
+	}
+	buf := new(bytes.Buffer)
+	w := csv.NewWriter(buf)
+	w.Write([]string{
+		"Instance Type",
+		"VCPUs",
+		"Mem (MiB)",
+		"Hypervisor",
+		"Current Gen",
+		"Hibernation Support",
+		"CPU Arch",
+		"Network Performance",
+		"ENIs",
+		"GPUs",
+		"GPU Mem (MiB)",
+		"GPU Info",
+	})
+	for _, instanceTypeInfo := range instanceTypeInfoSlice {
+		var vcpus, memory, enis, gpus, gpuMemory int64
+		cpuArchitectures := []string{}
+		networkPerformance := ""
+		gpuType := []string{}
+		if instanceTypeInfo.VCpuInfo != nil {
+			vcpus = aws.Int64Value(instanceTypeInfo.VCpuInfo.DefaultVCpus)
+		}
+		if instanceTypeInfo.MemoryInfo != nil {
+			memory = aws.Int64Value(instanceTypeInfo.MemoryInfo.SizeInMiB)
+		}
+		if instanceTypeInfo.ProcessorInfo != nil {
+			cpuArchitectures = aws.StringValueSlice(instanceTypeInfo.ProcessorInfo.SupportedArchitectures)
+		}
+		if instanceTypeInfo.NetworkInfo != nil {
+			networkPerformance = aws.StringValue(instanceTypeInfo.NetworkInfo.NetworkPerformance)
+			enis = aws.Int64Value(instanceTypeInfo.NetworkInfo.MaximumNetworkInterfaces)
+		}
+		if instanceTypeInfo.GpuInfo != nil {
+			gpuMemory = aws.Int64Value(instanceTypeInfo.GpuInfo.TotalGpuMemoryInMiB)
+			for _, gpuInfo := range instanceTypeInfo.GpuInfo.Gpus {
+				gpus = gpus + aws.Int64Value(gpuInfo.Count)
+				gpuType = append(gpuType, aws.StringValue(gpuInfo.Manufacturer)+" "+aws.StringValue(gpuInfo.Name))
+			}
+		}
+		hypervisor := aws.StringValue(instanceTypeInfo.Hypervisor)
+		if hypervisor == "" {
+			hypervisor = "none"
+		}
+		w.Write([]string{
+			aws.StringValue(instanceTypeInfo.InstanceType),
+			strconv.FormatInt(vcpus, 10),
+			strconv.FormatInt(memory, 10),
+			hypervisor,
+			strconv.FormatBool(aws.BoolValue(instanceTypeInfo.CurrentGeneration)),
+			strconv.FormatBool(aws.BoolValue(instanceTypeInfo.HibernationSupported)),
+			strings.Join(cpuArchitectures, ", "),
+			networkPerformance,
+			strconv.FormatInt(enis, 10),
+			strconv.FormatInt(gpus, 10),
+			strconv.FormatInt(gpuMemory, 10),
+			strings.Join(gpuType, ", "),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Printf("Unable to create CSV: %v\n", err)
+		return []string{}
+	}
+	return []string{strings.TrimSuffix(buf.String(), "\n")}
+}
+
+// AutoScalingGroupMixedInstancesPolicyJSONOutput is an OutputFn which returns a spot MixedInstancesPolicy in the JSON syntax
+// accepted by the --mixed-instances-policy argument of aws autoscaling create-auto-scaling-group
+func AutoScalingGroupMixedInstancesPolicyJSONOutput(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []string {
+	instanceTypeOverrides := instanceTypeInfoToOverrides(instanceTypeInfoSlice)
+	mixedInstancesPolicy := getCfnMIGResources(instanceTypeOverrides).Resources["AutoScalingGroupMIG"].Properties.MixedInstancesPolicy
+	mixedInstancesPolicyJSON, err := json.MarshalIndent(mixedInstancesPolicy, "", "    ")
+	if err != nil {
+		log.Printf("Unable to create ASG MixedInstancesPolicy JSON: %v\n", err)
+		return []string{}
+	}
+	return []string{string(mixedInstancesPolicyJSON)}
+}
+
+// KarpenterRequirementsYAMLOutput is an OutputFn which returns Karpenter provisioner requirements in YAML syntax
+// restricting nodes to the instance types
+func KarpenterRequirementsYAMLOutput(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []string {
+	instanceTypes := []string{}
+	for _, instanceTypeInfo := range instanceTypeInfoSlice {
+		instanceTypes = append(instanceTypes, aws.StringValue(instanceTypeInfo.InstanceType))
+	}
+	requirements := KarpenterRequirements{
+		Requirements: []KarpenterRequirement{
+			{
+				Key:      karpenterInstanceTypeKey,
+				Operator: karpenterOperatorIn,
+				Values:   instanceTypes,
+			},
+		},
+	}
+	requirementsYAML, err := yaml.Marshal(requirements)
+	if err != nil {
+		log.Printf("Unable to create Karpenter requirements YAML: %v\n", err)
+		return []string{}
+	}
+	return []string{string(requirementsYAML)}
+}
+
+// EC2FleetJSONOutput returns an OutputFn which returns a spot EC2 Fleet config in the JSON syntax accepted by the --cli-input-json
+// argument of aws ec2 create-fleet, with an override for each instance type. If launchTemplateID is empty, a placeholder is used.
+func EC2FleetJSONOutput(launchTemplateID string) func([]*ec2.InstanceTypeInfo) []string {
+	if launchTemplateID == "" {
+		launchTemplateID = "REPLACE_WITH_LAUNCH_TEMPLATE_ID"
+	}
+	return func(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []string {
+		overrides := []*ec2.FleetLaunchTemplateOverridesRequest{}
+		for _, instanceTypeInfo := range instanceTypeInfoSlice {
+			overrides = append(overrides, &ec2.FleetLaunchTemplateOverridesRequest{InstanceType: instanceTypeInfo.InstanceType})
+		}
+		fleetConfig := ec2.CreateFleetInput{
+			Type: aws.String(ec2.FleetTypeInstant),
+			LaunchTemplateConfigs: []*ec2.FleetLaunchTemplateConfigRequest{
+				{
+					LaunchTemplateSpecification: &ec2.FleetLaunchTemplateSpecificationRequest{
+						LaunchTemplateId: aws.String(launchTemplateID),
+						Version:          aws.String("$Latest"),
+					},
+					Overrides: overrides,
+				},
+			},
+			SpotOptions: &ec2.SpotOptionsRequest{
+				AllocationStrategy: aws.String(capacityOptimized),
+			},
+			TargetCapacitySpecification: &ec2.TargetCapacitySpecificationRequest{
+				DefaultTargetCapacityType: aws.String(ec2.DefaultTargetCapacityTypeSpot),
+				TotalTargetCapacity:       aws.Int64(1),
+			},
+		}
+		fleetConfigJSON, err := json.MarshalIndent(fleetConfig, "", "    ")
+		if err != nil {
+			log.Printf("Unable to create EC2 Fleet JSON: %v\n", err)
+			return []string{}
+		}
+		return []string{string(fleetConfigJSON)}
+	}
+}
+
 // TerraformSpotMixedInstancesPolicyHCLOutput is an OutputFn which returns an ASG MixedInstancePolicy in Terraform HCL syntax
 func TerraformSpotMixedInstancesPolicyHCLOutput(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []string {
 	instanceTypeOverrides := instanceTypeInfoToOverrides(instanceTypeInfoSlice)
@@ -153,6 +419,18 @@ func instanceTypeInfoToOverrides(instanceTypeInfoSlice []*ec2.InstanceTypeInfo)
 
 // TableOutputShort is an OutputFn which returns a CLI table for easy reading
 func TableOutputShort(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []string {
+	return tableOutputShort(instanceTypeInfoSlice, nil)
+}
+
+// TableOutputShortWithZones returns an OutputFn which returns a CLI table with a column of the availability zones each instance type is offered in
+func TableOutputShortWithZones(zones map[string][]string) func([]*ec2.InstanceTypeInfo) []string {
+	return func(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []string {
+		return tableOutputShort(instanceTypeInfoSlice, zones)
+	}
+}
+
+// tableOutputShort returns a CLI table of the instance types, with an availability zones column if zones is not nil
+func tableOutputShort(instanceTypeInfoSlice []*ec2.InstanceTypeInfo, zones map[string][]string) []string {
 	if instanceTypeInfoSlice == nil || len(instanceTypeInfoSlice) == 0 {
 		return nil
 	}
@@ -166,6 +444,9 @@ func TableOutputShort(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []string {
 		"VCPUs",
 		"Mem (MiB)",
 	}
+	if zones != nil {
+		headers = append(headers, zonesHeader)
+	}
 	separators := []interface{}{}
 
 	headerFormat := ""
@@ -182,6 +463,9 @@ func TableOutputShort(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []string {
 			*instanceTypeInfo.VCpuInfo.DefaultVCpus,
 			*instanceTypeInfo.MemoryInfo.SizeInMiB,
 		)
+		if zones != nil {
+			fmt.Fprintf(w, "%s\t", zonesColumn(zones, *instanceTypeInfo.InstanceType))
+		}
 	}
 	w.Flush()
 	return []string{buf.String()}
@@ -189,6 +473,18 @@ func TableOutputShort(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []string {
 
 // TableOutputWide is an OutputFn which returns a detailed CLI table for easy reading
 func TableOutputWide(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []string {
+	return tableOutputWide(instanceTypeInfoSlice, nil)
+}
+
+// TableOutputWideWithZones returns an OutputFn which returns a detailed CLI table with a column of the availability zones each instance type is offered in
+func TableOutputWideWithZones(zones map[string][]string) func([]*ec2.InstanceTypeInfo) []string {
+	return func(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []string {
+		return tableOutputWide(instanceTypeInfoSlice, zones)
+	}
+}
+
+// tableOutputWide returns a detailed CLI table of the instance types, with an availability zones column if zones is not nil
+func tableOutputWide(instanceTypeInfoSlice []*ec2.InstanceTypeInfo, zones map[string][]string) []string {
 	if instanceTypeInfoSlice == nil || len(instanceTypeInfoSlice) == 0 {
 		return nil
 	}
@@ -212,6 +508,9 @@ func TableOutputWide(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []string {
 		"GPU Mem (MiB)",
 		"GPU Info",
 	}
+	if zones != nil {
+		headers = append(headers, zonesHeader)
+	}
 	separators := []interface{}{}
 
 	headerFormat := ""
@@ -256,7 +555,18 @@ func TableOutputWide(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []string {
 			gpuMemory,
 			strings.Join(gpuType, ", "),
 		)
+		if zones != nil {
+			fmt.Fprintf(w, "%s\t", zonesColumn(zones, *instanceTypeInfo.InstanceType))
+		}
 	}
 	w.Flush()
 	return []string{buf.String()}
 }
+
+// zonesColumn returns the table cell of the availability zones the instance type is offered in
+func zonesColumn(zones map[string][]string, instanceType string) string {
+	if len(zones[instanceType]) == 0 {
+		return "none"
+	}
+	return strings.Join(zones[instanceType], ", ")
+}
@@ -14,9 +14,11 @@
 package outputs_test
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"strings"
 	"testing"
 
@@ -55,6 +57,28 @@ func TestSimpleInstanceTypeOutput(t *testing.T) {
 	h.Assert(t, len(instanceTypeOut) == 0, "Should return 0 instance types when passed nil")
 }
 
+func TestColorInstanceTypeOutput(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "t3_micro_and_p3_16xl.json")
+	instanceTypeOut := outputs.ColorInstanceTypeOutput(instanceTypes)
+	h.Equals(t, []string{"\033[33mt3.micro (burstable, up to 5 gigabit network)\033[0m", "p3.16xlarge"}, instanceTypeOut)
+
+	instanceTypes = getInstanceTypes(t, "25_instances.json")
+	instanceTypeOut = outputs.ColorInstanceTypeOutput(instanceTypes)
+	h.Equals(t, "\033[36ma1.2xlarge (up to 10 gigabit network)\033[0m", instanceTypeOut[0])
+
+	instanceTypeOut = outputs.ColorInstanceTypeOutput(nil)
+	h.Equals(t, 0, len(instanceTypeOut))
+}
+
+func TestJSONInstanceTypeNamesOutput(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "t3_micro_and_p3_16xl.json")
+	instanceTypeOut := outputs.JSONInstanceTypeNamesOutput(instanceTypes)
+	h.Equals(t, []string{`["t3.micro","p3.16xlarge"]`}, instanceTypeOut)
+
+	instanceTypeOut = outputs.JSONInstanceTypeNamesOutput(nil)
+	h.Equals(t, []string{"[]"}, instanceTypeOut)
+}
+
 func TestVerboseInstanceTypeOutput(t *testing.T) {
 	instanceTypes := getInstanceTypes(t, "t3_micro.json")
 	outputExpectation, err := json.MarshalIndent(instanceTypes, "", "    ")
@@ -117,3 +141,142 @@ func TestTableOutputWide(t *testing.T) {
 	h.Assert(t, strings.Contains(outputStr, "Moderate"), "wide table should include network performance")
 	h.Assert(t, strings.Contains(outputStr, "NVIDIA K520"), "wide table should include GPU Info")
 }
+
+func TestTableOutputShortWithZones(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "t3_micro.json")
+	instanceTypeOut := outputs.TableOutputShortWithZones(map[string][]string{"t3.micro": {"us-east-1a", "us-east-1b"}})(instanceTypes)
+	outputStr := strings.Join(instanceTypeOut, "")
+	lines := strings.Split(outputStr, "\n")
+	h.Assert(t, len(lines) == 3, "table should include a 2 header lines and 1 instance type result line")
+	h.Assert(t, strings.Contains(lines[0], "Availability Zones"), "table should include the availability zones header")
+	h.Assert(t, strings.Contains(lines[2], "us-east-1a, us-east-1b"), "table should include the instance type's availability zones")
+}
+
+func TestTableOutputWideWithZones(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "g2_2xlarge.json")
+	instanceTypeOut := outputs.TableOutputWideWithZones(map[string][]string{})(instanceTypes)
+	outputStr := strings.Join(instanceTypeOut, "")
+	lines := strings.Split(outputStr, "\n")
+	h.Assert(t, len(lines) == 3, "table should include a 2 header lines and 1 instance type result line")
+	h.Assert(t, strings.Contains(lines[0], "Availability Zones"), "table should include the availability zones header")
+	h.Assert(t, strings.HasSuffix(strings.TrimSpace(lines[2]), "none"), "table should show none for an instance type without zones")
+
+	instanceTypeOut = outputs.TableOutputWideWithZones(map[string][]string{})(nil)
+	h.Assert(t, len(instanceTypeOut) == 0, "Should return 0 instance types when passed nil")
+}
+
+func TestVerboseInstanceTypeWithZonesOutput(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "t3_micro.json")
+	instanceTypeOut := outputs.VerboseInstanceTypeWithZonesOutput(map[string][]string{"t3.micro": {"us-east-1a"}})(instanceTypes)
+	instanceTypesWithZones := []map[string]interface{}{}
+	h.Ok(t, json.Unmarshal([]byte(strings.Join(instanceTypeOut, "")), &instanceTypesWithZones))
+	h.Assert(t, len(instanceTypesWithZones) == 1, "Should only return 1 instance type")
+	h.Equals(t, "t3.micro", instanceTypesWithZones[0]["InstanceType"])
+	h.Equals(t, []interface{}{"us-east-1a"}, instanceTypesWithZones[0]["AvailabilityZones"])
+
+	instanceTypeOut = outputs.VerboseInstanceTypeWithZonesOutput(nil)(nil)
+	h.Assert(t, len(instanceTypeOut) == 0, "Should return 0 instance types when passed nil")
+}
+
+func TestYAMLInstanceTypeWithZonesOutput(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "t3_micro.json")
+	instanceTypeOut := outputs.YAMLInstanceTypeWithZonesOutput(map[string][]string{})(instanceTypes)
+	outputStr := strings.Join(instanceTypeOut, "")
+	_, err := yaml.YAMLToJSON([]byte(outputStr))
+	h.Ok(t, err)
+	h.Assert(t, strings.Contains(outputStr, "InstanceType: t3.micro"), "YAML should include the t3.micro instance type")
+	h.Assert(t, strings.Contains(outputStr, "AvailabilityZones: []"), "YAML should include an empty list of availability zones")
+}
+
+func TestYAMLInstanceTypeOutput(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "t3_micro.json")
+	instanceTypeOut := outputs.YAMLInstanceTypeOutput(instanceTypes)
+	outputStr := strings.Join(instanceTypeOut, "")
+	_, err := yaml.YAMLToJSON([]byte(outputStr))
+	h.Ok(t, err)
+	h.Assert(t, strings.Contains(outputStr, "InstanceType: t3.micro"), "YAML should include the t3.micro instance type")
+
+	instanceTypeOut = outputs.YAMLInstanceTypeOutput(nil)
+	h.Assert(t, len(instanceTypeOut) == 0, "Should return 0 instance types when passed nil")
+}
+
+func TestCSVOutput(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "g2_2xlarge.json")
+	instanceTypeOut := outputs.CSVOutput(instanceTypes)
+	records, err := csv.NewReader(strings.NewReader(strings.Join(instanceTypeOut, ""))).ReadAll()
+	h.Ok(t, err)
+	h.Assert(t, len(records) == 2, "CSV should include a header row and 1 instance type row")
+	h.Equals(t, "Instance Type", records[0][0])
+	h.Equals(t, "g2.2xlarge", records[1][0])
+	h.Equals(t, "NVIDIA K520", records[1][11])
+
+	instanceTypeOut = outputs.CSVOutput(nil)
+	h.Assert(t, len(instanceTypeOut) == 0, "Should return 0 instance types when passed nil")
+}
+
+func TestAutoScalingGroupMixedInstancesPolicyJSONOutput(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "t3_micro.json")
+	instanceTypeOut := outputs.AutoScalingGroupMixedInstancesPolicyJSONOutput(instanceTypes)
+	mixedInstancesPolicy := outputs.MixedInstancesPolicy{}
+	err := json.Unmarshal([]byte(strings.Join(instanceTypeOut, "")), &mixedInstancesPolicy)
+	h.Ok(t, err)
+	h.Equals(t, []outputs.InstanceTypeOverride{{InstanceType: "t3.micro"}}, mixedInstancesPolicy.LaunchTemplate.Overrides)
+}
+
+func TestKarpenterRequirementsYAMLOutput(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "t3_micro_and_p3_16xl.json")
+	instanceTypeOut := outputs.KarpenterRequirementsYAMLOutput(instanceTypes)
+	requirements := outputs.KarpenterRequirements{}
+	err := yaml.Unmarshal([]byte(strings.Join(instanceTypeOut, "")), &requirements)
+	h.Ok(t, err)
+	h.Assert(t, len(requirements.Requirements) == 1, "Should include a single instance type requirement")
+	h.Equals(t, "node.kubernetes.io/instance-type", requirements.Requirements[0].Key)
+	h.Equals(t, "In", requirements.Requirements[0].Operator)
+	h.Equals(t, []string{"t3.micro", "p3.16xlarge"}, requirements.Requirements[0].Values)
+}
+
+func TestTemplateOutput(t *testing.T) {
+	templateFile, err := ioutil.TempFile("", "capacity-report-*.tmpl")
+	h.Ok(t, err)
+	defer os.Remove(templateFile.Name())
+	_, err = templateFile.WriteString(`Capacity report: {{len .}} instance types
+{{range .}}{{.InstanceType}} vcpus={{.VCpuInfo.DefaultVCpus}} arch={{join .ProcessorInfo.SupportedArchitectures ","}}{{if gt (deref .VCpuInfo.DefaultVCpus) 32}} large{{end}}
+{{end}}`)
+	h.Ok(t, err)
+	templateFile.Close()
+
+	tmpl, err := outputs.ParseTemplateFile(templateFile.Name())
+	h.Ok(t, err)
+	instanceTypes := getInstanceTypes(t, "t3_micro_and_p3_16xl.json")
+	instanceTypeOut := outputs.TemplateOutput(tmpl)(instanceTypes)
+	h.Equals(t, []string{"Capacity report: 2 instance types\nt3.micro vcpus=2 arch=x86_64\np3.16xlarge vcpus=64 arch=x86_64 large"}, instanceTypeOut)
+}
+
+func TestTemplateOutput_Error(t *testing.T) {
+	_, err := outputs.ParseTemplateFile("does-not-exist.tmpl")
+	h.Nok(t, err)
+
+	templateFile, err := ioutil.TempFile("", "invalid-*.tmpl")
+	h.Ok(t, err)
+	defer os.Remove(templateFile.Name())
+	_, err = templateFile.WriteString(`{{range .}}{{.DoesNotExist}}{{end}}`)
+	h.Ok(t, err)
+	templateFile.Close()
+	tmpl, err := outputs.ParseTemplateFile(templateFile.Name())
+	h.Ok(t, err)
+	h.Equals(t, []string{}, outputs.TemplateOutput(tmpl)(getInstanceTypes(t, "t3_micro.json")))
+}
+
+func TestEC2FleetJSONOutput(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "t3_micro_and_p3_16xl.json")
+	instanceTypeOut := outputs.EC2FleetJSONOutput("lt-0123456789abcdef0")(instanceTypes)
+	h.Equals(t, 1, len(instanceTypeOut))
+	fleetConfig := ec2.CreateFleetInput{}
+	h.Ok(t, json.Unmarshal([]byte(instanceTypeOut[0]), &fleetConfig))
+	h.Equals(t, "lt-0123456789abcdef0", *fleetConfig.LaunchTemplateConfigs[0].LaunchTemplateSpecification.LaunchTemplateId)
+	h.Equals(t, 2, len(fleetConfig.LaunchTemplateConfigs[0].Overrides))
+	h.Equals(t, "spot", *fleetConfig.TargetCapacitySpecification.DefaultTargetCapacityType)
+
+	instanceTypeOut = outputs.EC2FleetJSONOutput("")(instanceTypes)
+	h.Assert(t, strings.Contains(instanceTypeOut[0], "REPLACE_WITH_LAUNCH_TEMPLATE_ID"), "A placeholder should be used when no launch template is passed")
+}
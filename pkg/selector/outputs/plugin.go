@@ -0,0 +1,53 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package outputs
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"os/exec"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// PluginOutput returns an OutputFn which runs the executable at path with the full instance type specs
+// as a JSON array on stdin, the same as the json output format, and outputs what the executable writes to stdout.
+// Nothing is output if the executable fails, and what it wrote to stderr is logged.
+func PluginOutput(path string) func([]*ec2.InstanceTypeInfo) []string {
+	return func(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []string {
+		if instanceTypeInfoSlice == nil {
+			instanceTypeInfoSlice = []*ec2.InstanceTypeInfo{}
+		}
+		input, err := json.Marshal(instanceTypeInfoSlice)
+		if err != nil {
+			log.Printf("Unable to convert instance type info to JSON for output plugin %s: %v\n", path, err)
+			return []string{}
+		}
+		stderr := bytes.Buffer{}
+		cmd := exec.Command(path)
+		cmd.Stdin = bytes.NewReader(input)
+		cmd.Stderr = &stderr
+		output, err := cmd.Output()
+		if err != nil {
+			log.Printf("Output plugin %s failed: %v %s\n", path, err, strings.TrimSpace(stderr.String()))
+			return []string{}
+		}
+		if len(output) == 0 {
+			return []string{}
+		}
+		return []string{strings.TrimSuffix(string(output), "\n")}
+	}
+}
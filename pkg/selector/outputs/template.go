@@ -0,0 +1,71 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package outputs
+
+import (
+	"bytes"
+	"log"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// templateFuncs are the functions available to output templates in addition to the text/template built-ins
+var templateFuncs = template.FuncMap{
+	// join joins a slice of strings with the separator, like the supported architectures of an instance type
+	"join": func(values []*string, separator string) string {
+		strs := []string{}
+		for _, value := range values {
+			if value != nil {
+				strs = append(strs, *value)
+			}
+		}
+		return strings.Join(strs, separator)
+	},
+	// deref returns the value of an AWS SDK pointer field so that it can be compared, like gt (deref .VCpuInfo.DefaultVCpus) 4
+	"deref": func(value interface{}) interface{} {
+		v := reflect.ValueOf(value)
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return nil
+			}
+			v = v.Elem()
+		}
+		return v.Interface()
+	},
+}
+
+// ParseTemplateFile parses a Go text/template file which renders the full instance type results with TemplateOutput
+func ParseTemplateFile(path string) (*template.Template, error) {
+	return template.New(filepath.Base(path)).Funcs(templateFuncs).ParseFiles(path)
+}
+
+// TemplateOutput returns an OutputFn which renders the whole slice of instance type specs through the template at once,
+// so that the template can produce a complete document like a capacity report rather than one line per instance type
+func TemplateOutput(tmpl *template.Template) func([]*ec2.InstanceTypeInfo) []string {
+	return func(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []string {
+		output := bytes.Buffer{}
+		if err := tmpl.Execute(&output, instanceTypeInfoSlice); err != nil {
+			log.Printf("Unable to render the output template %s: %v\n", tmpl.Name(), err)
+			return []string{}
+		}
+		if output.Len() == 0 {
+			return []string{}
+		}
+		return []string{strings.TrimSuffix(output.String(), "\n")}
+	}
+}
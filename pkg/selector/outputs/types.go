@@ -13,9 +13,16 @@
 
 package outputs
 
+import "github.com/aws/aws-sdk-go/service/ec2"
+
 const (
 	capacityOptimized = "capacity-optimized"
 	typeASG           = "AWS::AutoScaling::AutoScalingGroup"
+
+	karpenterInstanceTypeKey = "node.kubernetes.io/instance-type"
+	karpenterOperatorIn      = "In"
+
+	zonesHeader = "Availability Zones"
 )
 
 // Resources is a struct to represent json for a cloudformation Resources definition block.
@@ -73,3 +80,21 @@ type InstanceTypeOverride struct {
 	InstanceType     string `json:"InstanceType"`
 	WeightedCapacity int    `json:"WeightedCapacity,omitempty"`
 }
+
+// KarpenterRequirements is a struct to represent yaml for the requirements of a Karpenter provisioner spec
+type KarpenterRequirements struct {
+	Requirements []KarpenterRequirement `json:"requirements"`
+}
+
+// KarpenterRequirement is a struct to represent yaml for a single Karpenter provisioner requirement
+type KarpenterRequirement struct {
+	Key      string   `json:"key"`
+	Operator string   `json:"operator"`
+	Values   []string `json:"values"`
+}
+
+// InstanceTypeInfoWithZones is the full instance type specs with the availability zones the instance type is offered in
+type InstanceTypeInfoWithZones struct {
+	*ec2.InstanceTypeInfo
+	AvailabilityZones []string `json:"AvailabilityZones"`
+}
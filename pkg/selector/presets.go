@@ -0,0 +1,94 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// Built-in preset names which can be passed to FiltersFromPreset
+const (
+	// PresetGeneralPurpose matches current generation instance types with 4 GiB of memory per vcpu
+	PresetGeneralPurpose = "general-purpose"
+	// PresetComputeOptimized matches current generation instance types with 2 GiB of memory per vcpu
+	PresetComputeOptimized = "compute-optimized"
+	// PresetMemoryOptimized matches current generation instance types with 8 GiB of memory per vcpu
+	PresetMemoryOptimized = "memory-optimized"
+	// PresetGpuML matches current generation x86_64 instance types with at least 1 GPU
+	PresetGpuML = "gpu-ml"
+	// PresetSpotFriendly matches current generation, non-burstable instance types which support spot
+	PresetSpotFriendly = "spot-friendly"
+)
+
+// presets maps a preset name to a func building its filters so that each caller receives its own copy
+var presets = map[string]func() Filters{
+	PresetGeneralPurpose: func() Filters {
+		return Filters{
+			CurrentGeneration:  aws.Bool(true),
+			VCpusToMemoryRatio: aws.Float64(4),
+		}
+	},
+	PresetComputeOptimized: func() Filters {
+		return Filters{
+			CurrentGeneration:  aws.Bool(true),
+			VCpusToMemoryRatio: aws.Float64(2),
+		}
+	},
+	PresetMemoryOptimized: func() Filters {
+		return Filters{
+			CurrentGeneration:  aws.Bool(true),
+			VCpusToMemoryRatio: aws.Float64(8),
+		}
+	},
+	PresetGpuML: func() Filters {
+		return Filters{
+			CurrentGeneration: aws.Bool(true),
+			CPUArchitecture:   []string{ec2.ArchitectureTypeX8664},
+			GpusRange:         &IntRangeFilter{LowerBound: 1, UpperBound: maxInt},
+		}
+	},
+	PresetSpotFriendly: func() Filters {
+		return Filters{
+			CurrentGeneration: aws.Bool(true),
+			UsageClass:        []string{ec2.UsageClassTypeSpot},
+			Burstable:         aws.Bool(false),
+			BareMetal:         aws.Bool(false),
+		}
+	},
+}
+
+// PresetNames returns the sorted names of all built-in presets
+func PresetNames() []string {
+	names := []string{}
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FiltersFromPreset returns the Filters of a built-in preset.
+// User overrides can be applied on top of a preset with Merge, for example:
+// FiltersFromPreset(PresetGeneralPurpose).Merge(Filters{VCpusRange: &IntRangeFilter{LowerBound: 4, UpperBound: 4}})
+func FiltersFromPreset(name string) (Filters, error) {
+	preset, ok := presets[name]
+	if !ok {
+		return Filters{}, newClassifiedError(ErrNotFound, "The preset %s does not exist. Valid presets are: %s", name, strings.Join(PresetNames(), ", "))
+	}
+	return preset(), nil
+}
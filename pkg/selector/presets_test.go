@@ -0,0 +1,59 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+)
+
+func TestPresetNames(t *testing.T) {
+	names := selector.PresetNames()
+	h.Equals(t, []string{"compute-optimized", "general-purpose", "gpu-ml", "memory-optimized", "spot-friendly"}, names)
+	for _, name := range names {
+		_, err := selector.FiltersFromPreset(name)
+		h.Ok(t, err)
+	}
+}
+
+func TestFiltersFromPreset_Unknown(t *testing.T) {
+	_, err := selector.FiltersFromPreset("does-not-exist")
+	h.Nok(t, err)
+}
+
+func TestFiltersFromPreset_WithOverrides(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypes, "25_instances.json")
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	presetFilters, err := selector.FiltersFromPreset(selector.PresetComputeOptimized)
+	h.Ok(t, err)
+	filters := presetFilters.Merge(selector.Filters{
+		VCpusRange: &selector.IntRangeFilter{LowerBound: 2, UpperBound: 2},
+	})
+	results, err := itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"a1.large", "c5.large"}, results)
+}
+
+func TestFiltersFromPreset_ReturnsCopy(t *testing.T) {
+	presetFilters, err := selector.FiltersFromPreset(selector.PresetGpuML)
+	h.Ok(t, err)
+	presetFilters.GpusRange.LowerBound = 8
+	presetFilters, err = selector.FiltersFromPreset(selector.PresetGpuML)
+	h.Ok(t, err)
+	h.Assert(t, presetFilters.GpusRange.LowerBound == 1, "Modifying a preset should not modify subsequent presets")
+}
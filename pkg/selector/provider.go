@@ -0,0 +1,129 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// InstanceDataProvider supplies the instance type specs and offerings the filters are executed against, so that filtering
+// can run against a catalog other than the live EC2 API, like a snapshot file or a test fixture.
+// Selectors without a provider retrieve the data from their EC2 client.
+type InstanceDataProvider interface {
+	// InstanceTypes returns the specs of every instance type matching the raw EC2 filters
+	InstanceTypes(rawEC2Filters []*ec2.Filter) ([]*ec2.InstanceTypeInfo, error)
+	// InstanceTypeOfferings returns the instance types offered in the location, a region, zone name, or zone id, mapped to the location.
+	// A nil map means the offerings are not known and instance types are not filtered by location.
+	InstanceTypeOfferings(location string) (map[string]string, error)
+}
+
+// zoneOfferingsProvider is implemented by InstanceDataProviders which can list the availability zones each instance type is offered in
+type zoneOfferingsProvider interface {
+	InstanceTypeZones() (map[string][]string, error)
+}
+
+// Snapshot is a static catalog of instance type specs and offerings.
+// The instance types cache files written by WithCacheDir can be read as a Snapshot.
+type Snapshot struct {
+	InstanceTypes []*ec2.InstanceTypeInfo `json:"instanceTypes"`
+	// LocationOfferings maps a location to the instance types offered in it
+	LocationOfferings map[string][]string `json:"locationOfferings,omitempty"`
+}
+
+// StaticDataProvider is an InstanceDataProvider which serves a Snapshot without calling any API
+type StaticDataProvider struct {
+	snapshot Snapshot
+}
+
+// NewStaticDataProvider creates a StaticDataProvider serving the snapshot
+func NewStaticDataProvider(snapshot Snapshot) *StaticDataProvider {
+	return &StaticDataProvider{snapshot: snapshot}
+}
+
+// LoadSnapshotFile reads a JSON Snapshot from the path and returns a StaticDataProvider serving it
+func LoadSnapshotFile(path string) (*StaticDataProvider, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read snapshot file %s: %w", path, err)
+	}
+	snapshot := Snapshot{}
+	if err := json.Unmarshal(contents, &snapshot); err != nil {
+		return nil, fmt.Errorf("Unable to parse snapshot file %s: %w", path, err)
+	}
+	if len(snapshot.InstanceTypes) == 0 {
+		return nil, fmt.Errorf("The snapshot file %s does not contain any instance types", path)
+	}
+	return NewStaticDataProvider(snapshot), nil
+}
+
+// InstanceTypes returns the instance types of the snapshot. Only exact values of the instance-type raw EC2 filter are supported
+// since the other filters are evaluated by the EC2 API.
+func (p *StaticDataProvider) InstanceTypes(rawEC2Filters []*ec2.Filter) ([]*ec2.InstanceTypeInfo, error) {
+	instanceTypeInfoSlice := p.snapshot.InstanceTypes
+	for _, rawEC2Filter := range rawEC2Filters {
+		if aws.StringValue(rawEC2Filter.Name) != instanceTypeFilterKey {
+			return nil, newClassifiedError(ErrInvalidFilters, "The raw EC2 filter %s is not supported with a snapshot", aws.StringValue(rawEC2Filter.Name))
+		}
+		values := map[string]bool{}
+		for _, value := range rawEC2Filter.Values {
+			values[aws.StringValue(value)] = true
+		}
+		filtered := []*ec2.InstanceTypeInfo{}
+		for _, instanceTypeInfo := range instanceTypeInfoSlice {
+			if values[aws.StringValue(instanceTypeInfo.InstanceType)] {
+				filtered = append(filtered, instanceTypeInfo)
+			}
+		}
+		instanceTypeInfoSlice = filtered
+	}
+	return instanceTypeInfoSlice, nil
+}
+
+// InstanceTypeOfferings returns the instance types the snapshot lists for the location,
+// or nil if the snapshot has no offerings for the location
+func (p *StaticDataProvider) InstanceTypeOfferings(location string) (map[string]string, error) {
+	instanceTypes, ok := p.snapshot.LocationOfferings[location]
+	if !ok {
+		return nil, nil
+	}
+	offerings := map[string]string{}
+	for _, instanceType := range instanceTypes {
+		offerings[instanceType] = location
+	}
+	return offerings, nil
+}
+
+// InstanceTypeZones returns the sorted availability zone names the snapshot lists for each instance type.
+// Zone ids and regions in the snapshot's offerings are skipped.
+func (p *StaticDataProvider) InstanceTypeZones() (map[string][]string, error) {
+	instanceTypeZones := map[string][]string{}
+	for location, instanceTypes := range p.snapshot.LocationOfferings {
+		if locationType, err := getLocationType(location); err != nil || locationType != zoneNameLocationType {
+			continue
+		}
+		for _, instanceType := range instanceTypes {
+			instanceTypeZones[instanceType] = append(instanceTypeZones[instanceType], location)
+		}
+	}
+	for _, zones := range instanceTypeZones {
+		sort.Strings(zones)
+	}
+	return instanceTypeZones, nil
+}
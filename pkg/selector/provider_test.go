@@ -0,0 +1,113 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func loadSnapshot(t *testing.T, file string) *selector.StaticDataProvider {
+	provider, err := selector.LoadSnapshotFile(fmt.Sprintf("%s/%s/%s", mockFilesPath, describeInstanceTypes, file))
+	h.Ok(t, err)
+	return provider
+}
+
+func TestFilter_DataProvider(t *testing.T) {
+	itf := selector.NewWithOptions(nil,
+		selector.WithEC2Client(mockedEC2{DescribeInstanceTypesErr: errors.New("EC2 should not be called")}),
+		selector.WithDataProvider(loadSnapshot(t, "25_instances.json")),
+	)
+	results, err := itf.Filter(selector.Filters{
+		VCpusRange:      &selector.IntRangeFilter{LowerBound: 8, UpperBound: 8},
+		CPUArchitecture: []string{"x86_64"},
+	})
+	h.Ok(t, err)
+	h.Equals(t, []string{"c1.xlarge", "c3.2xlarge", "c4.2xlarge", "c5.2xlarge"}, results)
+}
+
+func TestFilter_DataProviderOfferings(t *testing.T) {
+	instanceTypes, err := loadSnapshot(t, "25_instances.json").InstanceTypes(nil)
+	h.Ok(t, err)
+	provider := selector.NewStaticDataProvider(selector.Snapshot{
+		InstanceTypes:     instanceTypes,
+		LocationOfferings: map[string][]string{"us-east-2a": {"c4.2xlarge", "c5.2xlarge"}},
+	})
+	itf := selector.Selector{DataProvider: provider}
+	results, err := itf.Filter(selector.Filters{
+		VCpusRange:       &selector.IntRangeFilter{LowerBound: 8, UpperBound: 8},
+		AvailabilityZone: aws.String("us-east-2a"),
+	})
+	h.Ok(t, err)
+	h.Equals(t, []string{"c4.2xlarge", "c5.2xlarge"}, results)
+
+	// locations without offerings in the snapshot are not filtered by location
+	results, err = itf.Filter(selector.Filters{
+		VCpusRange:       &selector.IntRangeFilter{LowerBound: 8, UpperBound: 8},
+		AvailabilityZone: aws.String("us-east-2b"),
+	})
+	h.Ok(t, err)
+	h.Equals(t, 5, len(results))
+}
+
+func TestFiltersFromInstanceTypes_DataProvider(t *testing.T) {
+	itf := selector.Selector{
+		EC2:          mockedEC2{DescribeInstanceTypesErr: errors.New("EC2 should not be called")},
+		DataProvider: loadSnapshot(t, "25_instances.json"),
+	}
+	envelope, err := itf.FiltersFromInstanceTypes([]string{"c4.2xlarge"})
+	h.Ok(t, err)
+	h.Equals(t, &selector.IntRangeFilter{LowerBound: 8, UpperBound: 8}, envelope.VCpusRange)
+	_, err = itf.FiltersFromInstanceTypes([]string{"m5.xlarge"})
+	h.Assert(t, errors.Is(err, selector.ErrNotFound), "Error should be classified as not found")
+}
+
+func TestRetrieveInstanceTypeZones_DataProvider(t *testing.T) {
+	itf := selector.Selector{
+		EC2: mockedEC2{DescribeInstanceTypeOfferingsErr: errors.New("EC2 should not be called")},
+		DataProvider: selector.NewStaticDataProvider(selector.Snapshot{
+			LocationOfferings: map[string][]string{
+				"us-east-2b": {"c5.large"},
+				"us-east-2a": {"c4.large", "c5.large"},
+				"use2-az1":   {"c4.large", "c5.large"},
+				"us-east-2":  {"c4.large", "c5.large"},
+			},
+		}),
+	}
+	results, err := itf.RetrieveInstanceTypeZones()
+	h.Ok(t, err)
+	h.Equals(t, map[string][]string{"c4.large": {"us-east-2a"}, "c5.large": {"us-east-2a", "us-east-2b"}}, results)
+}
+
+func TestStaticDataProvider_RawEC2Filters(t *testing.T) {
+	provider := loadSnapshot(t, "25_instances.json")
+	instanceTypes, err := provider.InstanceTypes([]*ec2.Filter{{Name: aws.String("instance-type"), Values: aws.StringSlice([]string{"c5.large", "a1.large"})}})
+	h.Ok(t, err)
+	h.Equals(t, 2, len(instanceTypes))
+	_, err = provider.InstanceTypes([]*ec2.Filter{{Name: aws.String("processor-info.supported-architecture"), Values: aws.StringSlice([]string{"arm64"})}})
+	h.Assert(t, errors.Is(err, selector.ErrInvalidFilters), "Error should be classified as invalid filters")
+}
+
+func TestLoadSnapshotFile_Invalid(t *testing.T) {
+	_, err := selector.LoadSnapshotFile("does-not-exist.json")
+	h.Nok(t, err)
+	_, err = selector.LoadSnapshotFile(fmt.Sprintf("%s/%s/%s", mockFilesPath, describeInstances, "m4_xlarge_us-east-2a.json"))
+	h.Nok(t, err)
+}
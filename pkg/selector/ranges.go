@@ -0,0 +1,179 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ParseIntRangeFilter parses a range string into an IntRangeFilter using the same bounds as the CLI range flags.
+// Supported formats are "4" (exactly 4), "4-8" (4 through 8 inclusive), and "16+" (16 or more).
+func ParseIntRangeFilter(rangeStr string) (IntRangeFilter, error) {
+	rangeStr = strings.TrimSpace(rangeStr)
+	if strings.HasSuffix(rangeStr, "+") {
+		lowerBound, err := parseRangeBound(strings.TrimSuffix(rangeStr, "+"), rangeStr)
+		if err != nil {
+			return IntRangeFilter{}, err
+		}
+		return IntRangeFilter{LowerBound: lowerBound, UpperBound: maxInt}, nil
+	}
+	bounds := strings.Split(rangeStr, "-")
+	switch len(bounds) {
+	case 1:
+		bound, err := parseRangeBound(bounds[0], rangeStr)
+		if err != nil {
+			return IntRangeFilter{}, err
+		}
+		return IntRangeFilter{LowerBound: bound, UpperBound: bound}, nil
+	case 2:
+		lowerBound, err := parseRangeBound(bounds[0], rangeStr)
+		if err != nil {
+			return IntRangeFilter{}, err
+		}
+		upperBound, err := parseRangeBound(bounds[1], rangeStr)
+		if err != nil {
+			return IntRangeFilter{}, err
+		}
+		if lowerBound > upperBound {
+			return IntRangeFilter{}, fmt.Errorf("Invalid range %q: lower bound %d is greater than upper bound %d", rangeStr, lowerBound, upperBound)
+		}
+		return IntRangeFilter{LowerBound: lowerBound, UpperBound: upperBound}, nil
+	}
+	return IntRangeFilter{}, fmt.Errorf("Invalid range %q: expected a format like \"4\", \"4-8\", or \"16+\"", rangeStr)
+}
+
+// MustParseIntRangeFilter is like ParseIntRangeFilter but panics if the range string cannot be parsed
+func MustParseIntRangeFilter(rangeStr string) IntRangeFilter {
+	intRange, err := ParseIntRangeFilter(rangeStr)
+	if err != nil {
+		panic(err)
+	}
+	return intRange
+}
+
+// String returns the range in the format accepted by ParseIntRangeFilter
+func (r IntRangeFilter) String() string {
+	switch {
+	case r.LowerBound == r.UpperBound:
+		return strconv.Itoa(r.LowerBound)
+	case r.UpperBound == maxInt:
+		return fmt.Sprintf("%d+", r.LowerBound)
+	}
+	return fmt.Sprintf("%d-%d", r.LowerBound, r.UpperBound)
+}
+
+func parseRangeBound(bound string, rangeStr string) (int, error) {
+	value, err := strconv.Atoi(strings.TrimSpace(bound))
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("Invalid range %q: %q is not a non-negative integer", rangeStr, bound)
+	}
+	return value, nil
+}
+
+// byteQuantityRegex matches a quantity like 8, 8.5GiB, or 8 gb and captures the value and unit
+var byteQuantityRegex = regexp.MustCompile(`^([0-9]*\.?[0-9]+)\s*([a-zA-Z]*)$`)
+
+// byteQuantityMiBMultipliers maps lower-case units to the number of MiB in one unit.
+// Decimal units like GB are treated as their binary equivalents since that is what EC2 reports memory in.
+var byteQuantityMiBMultipliers = map[string]float64{
+	"":    1,
+	"m":   1,
+	"mb":  1,
+	"mib": 1,
+	"g":   1024,
+	"gb":  1024,
+	"gib": 1024,
+	"t":   1024 * 1024,
+	"tb":  1024 * 1024,
+	"tib": 1024 * 1024,
+}
+
+// ParseByteQuantityRangeFilter parses a range of memory quantities like "16GiB", "16-32 GiB", "16GiB-32GiB", or "16GiB+"
+// into an IntRangeFilter in MiB. A unit after the upper bound also applies to a lower bound without a unit.
+func ParseByteQuantityRangeFilter(rangeStr string) (IntRangeFilter, error) {
+	rangeStr = strings.TrimSpace(rangeStr)
+	if strings.HasSuffix(rangeStr, "+") {
+		lowerBound, err := ParseByteQuantity(strings.TrimSuffix(rangeStr, "+"))
+		if err != nil {
+			return IntRangeFilter{}, err
+		}
+		return IntRangeFilter{LowerBound: lowerBound, UpperBound: maxInt}, nil
+	}
+	bounds := strings.Split(rangeStr, "-")
+	switch len(bounds) {
+	case 1:
+		bound, err := ParseByteQuantity(bounds[0])
+		if err != nil {
+			return IntRangeFilter{}, err
+		}
+		return IntRangeFilter{LowerBound: bound, UpperBound: bound}, nil
+	case 2:
+		upperBound, err := ParseByteQuantity(bounds[1])
+		if err != nil {
+			return IntRangeFilter{}, err
+		}
+		lowerBoundStr := strings.TrimSpace(bounds[0])
+		if unit := byteQuantityUnit(bounds[1]); byteQuantityUnit(lowerBoundStr) == "" && unit != "" {
+			lowerBoundStr += unit
+		}
+		lowerBound, err := ParseByteQuantity(lowerBoundStr)
+		if err != nil {
+			return IntRangeFilter{}, err
+		}
+		if lowerBound > upperBound {
+			return IntRangeFilter{}, fmt.Errorf("Invalid range %q: lower bound %d MiB is greater than upper bound %d MiB", rangeStr, lowerBound, upperBound)
+		}
+		return IntRangeFilter{LowerBound: lowerBound, UpperBound: upperBound}, nil
+	}
+	return IntRangeFilter{}, fmt.Errorf("Invalid range %q: expected a format like \"16GiB\", \"16-32GiB\", or \"16GiB+\"", rangeStr)
+}
+
+// ParseByteQuantity parses a quantity of memory like "8gb", "8 GiB", "1.5g", or "8192MiB" and returns it in mebibytes (MiB).
+// A quantity without a unit is treated as MiB.
+func ParseByteQuantity(quantity string) (int, error) {
+	matches := byteQuantityRegex.FindStringSubmatch(strings.TrimSpace(quantity))
+	if matches == nil {
+		return 0, fmt.Errorf("Invalid byte quantity %q: expected a format like \"8192\", \"8192MiB\", or \"8 GiB\"", quantity)
+	}
+	multiplier, ok := byteQuantityMiBMultipliers[strings.ToLower(matches[2])]
+	if !ok {
+		return 0, fmt.Errorf("Invalid byte quantity %q: unit %q is not one of MiB, GiB, or TiB", quantity, matches[2])
+	}
+	value, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid byte quantity %q: %w", quantity, err)
+	}
+	return int(math.Round(value * multiplier)), nil
+}
+
+// byteQuantityUnit returns the unit of the byte quantity or an empty string if there is no unit
+func byteQuantityUnit(quantity string) string {
+	matches := byteQuantityRegex.FindStringSubmatch(strings.TrimSpace(quantity))
+	if matches == nil {
+		return ""
+	}
+	return matches[2]
+}
+
+// gibToMiB converts GiB to MiB without overflowing unbounded ranges
+func gibToMiB(gib int) int {
+	if gib > maxInt/1024 {
+		return maxInt
+	}
+	return gib * 1024
+}
@@ -0,0 +1,109 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+)
+
+const maxInt = int(^uint(0) >> 1)
+
+func TestParseIntRangeFilter(t *testing.T) {
+	intRange, err := selector.ParseIntRangeFilter("4")
+	h.Ok(t, err)
+	h.Equals(t, selector.IntRangeFilter{LowerBound: 4, UpperBound: 4}, intRange)
+
+	intRange, err = selector.ParseIntRangeFilter("4-8")
+	h.Ok(t, err)
+	h.Equals(t, selector.IntRangeFilter{LowerBound: 4, UpperBound: 8}, intRange)
+
+	intRange, err = selector.ParseIntRangeFilter(" 16+ ")
+	h.Ok(t, err)
+	h.Equals(t, selector.IntRangeFilter{LowerBound: 16, UpperBound: maxInt}, intRange)
+}
+
+func TestParseIntRangeFilter_Invalid(t *testing.T) {
+	for _, rangeStr := range []string{"", "a", "8-4", "4-8-16", "-4", "4-", "+", "4+8"} {
+		_, err := selector.ParseIntRangeFilter(rangeStr)
+		h.Assert(t, err != nil, "Range %q should fail to parse", rangeStr)
+	}
+}
+
+func TestMustParseIntRangeFilter(t *testing.T) {
+	h.Equals(t, selector.IntRangeFilter{LowerBound: 2, UpperBound: 4}, selector.MustParseIntRangeFilter("2-4"))
+	defer func() {
+		h.Assert(t, recover() != nil, "Should panic on an invalid range")
+	}()
+	selector.MustParseIntRangeFilter("invalid")
+}
+
+func TestIntRangeFilter_String(t *testing.T) {
+	for _, rangeStr := range []string{"4", "4-8", "16+"} {
+		h.Equals(t, rangeStr, selector.MustParseIntRangeFilter(rangeStr).String())
+	}
+}
+
+func TestParseByteQuantity(t *testing.T) {
+	quantities := map[string]int{
+		"8192":     8192,
+		"8192MiB":  8192,
+		"512 mb":   512,
+		"8gb":      8192,
+		"8 GiB":    8192,
+		"1.5g":     1536,
+		"1TiB":     1024 * 1024,
+		" 16 GB ":  16384,
+		"0.5 gib":  512,
+		"4096 MiB": 4096,
+	}
+	for quantity, expected := range quantities {
+		mib, err := selector.ParseByteQuantity(quantity)
+		h.Ok(t, err)
+		h.Assert(t, mib == expected, "%q should be parsed to %d MiB but was %d", quantity, expected, mib)
+	}
+}
+
+func TestParseByteQuantity_Invalid(t *testing.T) {
+	for _, quantity := range []string{"", "gb", "8 kb", "-8gb", "8 g b", "eight"} {
+		_, err := selector.ParseByteQuantity(quantity)
+		h.Assert(t, err != nil, "Quantity %q should fail to parse", quantity)
+	}
+}
+
+func TestParseByteQuantityRangeFilter(t *testing.T) {
+	ranges := map[string]selector.IntRangeFilter{
+		"16GiB":          {LowerBound: 16384, UpperBound: 16384},
+		"16-32 GiB":      {LowerBound: 16384, UpperBound: 32768},
+		"16GiB-32GiB":    {LowerBound: 16384, UpperBound: 32768},
+		"512MiB-1GiB":    {LowerBound: 512, UpperBound: 1024},
+		"16gb+":          {LowerBound: 16384, UpperBound: maxInt},
+		"4096":           {LowerBound: 4096, UpperBound: 4096},
+		" 1 TiB - 2TiB ": {LowerBound: 1024 * 1024, UpperBound: 2 * 1024 * 1024},
+	}
+	for rangeStr, expected := range ranges {
+		intRange, err := selector.ParseByteQuantityRangeFilter(rangeStr)
+		h.Ok(t, err)
+		h.Assert(t, intRange == expected, "%q should be parsed to %v but was %v", rangeStr, expected, intRange)
+	}
+}
+
+func TestParseByteQuantityRangeFilter_Invalid(t *testing.T) {
+	for _, rangeStr := range []string{"", "32-16GiB", "16-32-64GiB", "GiB", "16 gigs"} {
+		_, err := selector.ParseByteQuantityRangeFilter(rangeStr)
+		h.Assert(t, err != nil, "Range %q should fail to parse", rangeStr)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// RecommendRegions ranks the regions by how suitable they are for running targetCapacity spot instances of the instance types
+// matching the filters. Regions are ranked by their Spot Placement Score, then by the number of matching instance types offered,
+// then by the lowest current spot price. MaxResults is ignored so that every matching instance type is evaluated.
+// The Selector must be created with NewWithOptions from an aws session or with WithRegionalEC2Clients, and without a DataProvider
+// since a snapshot holds the instance types of a single region.
+func (itf Selector) RecommendRegions(filters Filters, regions []string, targetCapacity int) ([]RegionRecommendation, error) {
+	if itf.regionalEC2Client == nil {
+		return nil, fmt.Errorf("Recommending regions requires a Selector created with an aws session or regional EC2 clients")
+	}
+	if itf.DataProvider != nil {
+		return nil, fmt.Errorf("Recommending regions cannot be done with a data provider since it holds the instance types of a single region")
+	}
+	if len(regions) == 0 {
+		return nil, fmt.Errorf("At least one region is required to recommend regions")
+	}
+	if targetCapacity <= 0 {
+		return nil, newClassifiedError(ErrInvalidFilters, "The target capacity must be greater than 0 but was %d", targetCapacity)
+	}
+	uniqueRegions := map[string]bool{}
+	for _, region := range regions {
+		uniqueRegions[region] = true
+	}
+	type regionResult struct {
+		recommendation RegionRecommendation
+		instanceTypes  []string
+		err            error
+	}
+	regionResults := make(chan regionResult, len(uniqueRegions))
+	wg := sync.WaitGroup{}
+	for region := range uniqueRegions {
+		wg.Add(1)
+		go func(region string) {
+			defer wg.Done()
+			recommendation, instanceTypes, err := itf.evaluateRegion(filters, region)
+			regionResults <- regionResult{recommendation: recommendation, instanceTypes: instanceTypes, err: err}
+		}(region)
+	}
+	wg.Wait()
+	close(regionResults)
+
+	recommendations := []RegionRecommendation{}
+	matchedInstanceTypes := map[string]bool{}
+	for regionResult := range regionResults {
+		if regionResult.err != nil {
+			return nil, fmt.Errorf("Unable to evaluate %s: %w", regionResult.recommendation.Region, regionResult.err)
+		}
+		for _, instanceType := range regionResult.instanceTypes {
+			matchedInstanceTypes[instanceType] = true
+		}
+		recommendations = append(recommendations, regionResult.recommendation)
+	}
+	sort.Slice(recommendations, func(i, j int) bool {
+		return recommendations[i].Region < recommendations[j].Region
+	})
+	if len(matchedInstanceTypes) != 0 {
+		scores, err := itf.retrieveSpotPlacementScores(recommendations[0].Region, matchedInstanceTypes, recommendations, targetCapacity)
+		if err != nil {
+			return nil, err
+		}
+		for i := range recommendations {
+			recommendations[i].SpotPlacementScore = scores[recommendations[i].Region]
+		}
+	}
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		ri, rj := recommendations[i], recommendations[j]
+		if ri.SpotPlacementScore != rj.SpotPlacementScore {
+			return ri.SpotPlacementScore > rj.SpotPlacementScore
+		}
+		if ri.InstanceTypes != rj.InstanceTypes {
+			return ri.InstanceTypes > rj.InstanceTypes
+		}
+		if (ri.LowestSpotPricePerHour == nil) != (rj.LowestSpotPricePerHour == nil) {
+			return ri.LowestSpotPricePerHour != nil
+		}
+		return ri.LowestSpotPricePerHour != nil && *ri.LowestSpotPricePerHour < *rj.LowestSpotPricePerHour
+	})
+	return recommendations, nil
+}
+
+// evaluateRegion returns the instance types matching the filters in the region along with the lowest spot price of them
+func (itf Selector) evaluateRegion(filters Filters, region string) (RegionRecommendation, []string, error) {
+	recommendation := RegionRecommendation{Region: region}
+	regionalSelector := itf
+	regionalSelector.EC2 = itf.regionalEC2Client(region)
+	// the cache only holds the responses of the Selector's own region so each region must query its own EC2 API
+	regionalSelector.cache = nil
+	regionalFilters := filters
+	regionalFilters.Region = nil
+	regionalFilters.AvailabilityZone = nil
+	instanceTypeInfoSlice, err := regionalSelector.rawFilter(regionalFilters)
+	if err != nil {
+		return recommendation, nil, err
+	}
+	instanceTypes := []string{}
+	for _, instanceTypeInfo := range instanceTypeInfoSlice {
+		instanceTypes = append(instanceTypes, aws.StringValue(instanceTypeInfo.InstanceType))
+	}
+	recommendation.InstanceTypes = len(instanceTypes)
+	spotPrices, err := regionalSelector.retrieveSpotPrices(instanceTypes)
+	if err != nil {
+		return recommendation, nil, err
+	}
+	for _, zonePrices := range spotPrices {
+		for _, price := range zonePrices {
+			if recommendation.LowestSpotPricePerHour == nil || price < *recommendation.LowestSpotPricePerHour {
+				recommendation.LowestSpotPricePerHour = aws.Float64(price)
+			}
+		}
+	}
+	return recommendation, instanceTypes, nil
+}
+
+// retrieveSpotPlacementScores returns a map of region -> the Spot Placement Score of running targetCapacity instances
+// of the instance types in the region. The scores of every region are requested at once through the EC2 API of apiRegion.
+func (itf Selector) retrieveSpotPlacementScores(apiRegion string, instanceTypes map[string]bool, recommendations []RegionRecommendation, targetCapacity int) (map[string]int64, error) {
+	instanceTypeNames := []string{}
+	for instanceType := range instanceTypes {
+		instanceTypeNames = append(instanceTypeNames, instanceType)
+	}
+	sort.Strings(instanceTypeNames)
+	regionNames := []string{}
+	for _, recommendation := range recommendations {
+		regionNames = append(regionNames, recommendation.Region)
+	}
+	spotPlacementScoresInput := &ec2.GetSpotPlacementScoresInput{
+		InstanceTypes:          aws.StringSlice(instanceTypeNames),
+		RegionNames:            aws.StringSlice(regionNames),
+		TargetCapacity:         aws.Int64(int64(targetCapacity)),
+		TargetCapacityUnitType: aws.String(ec2.TargetCapacityUnitTypeUnits),
+	}
+	itf.debugf("calling GetSpotPlacementScores for %d instance types in %d regions", len(instanceTypeNames), len(regionNames))
+	scores := map[string]int64{}
+	err := itf.regionalEC2Client(apiRegion).GetSpotPlacementScoresPages(spotPlacementScoresInput, func(page *ec2.GetSpotPlacementScoresOutput, lastPage bool) bool {
+		for _, score := range page.SpotPlacementScores {
+			scores[aws.StringValue(score.Region)] = aws.Int64Value(score.Score)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Encountered an error when retrieving spot placement scores: %w", classifyAPIError(err))
+	}
+	return scores, nil
+}
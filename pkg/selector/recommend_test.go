@@ -0,0 +1,108 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+func setupRecommendRegionsMocks(t *testing.T, scores map[string]int64) map[string]ec2iface.EC2API {
+	now := time.Now()
+	spotPlacementScores := ec2.GetSpotPlacementScoresOutput{}
+	for region, score := range scores {
+		spotPlacementScores.SpotPlacementScores = append(spotPlacementScores.SpotPlacementScores, &ec2.SpotPlacementScore{
+			Region: aws.String(region),
+			Score:  aws.Int64(score),
+		})
+	}
+	useast1 := setupMock(t, describeInstanceTypes, "t3_micro_and_p3_16xl.json")
+	useast1.DescribeSpotPriceHistoryResp = ec2.DescribeSpotPriceHistoryOutput{
+		SpotPriceHistory: []*ec2.SpotPrice{
+			{InstanceType: aws.String("t3.micro"), AvailabilityZone: aws.String("us-east-1a"), SpotPrice: aws.String("0.0035"), Timestamp: aws.Time(now)},
+			{InstanceType: aws.String("p3.16xlarge"), AvailabilityZone: aws.String("us-east-1a"), SpotPrice: aws.String("7.3"), Timestamp: aws.Time(now)},
+		},
+	}
+	useast1.GetSpotPlacementScoresResp = spotPlacementScores
+	useast2 := setupMock(t, describeInstanceTypes, "t3_micro.json")
+	useast2.DescribeSpotPriceHistoryResp = ec2.DescribeSpotPriceHistoryOutput{
+		SpotPriceHistory: []*ec2.SpotPrice{
+			{InstanceType: aws.String("t3.micro"), AvailabilityZone: aws.String("us-east-2b"), SpotPrice: aws.String("0.0031"), Timestamp: aws.Time(now)},
+		},
+	}
+	useast2.GetSpotPlacementScoresResp = spotPlacementScores
+	return map[string]ec2iface.EC2API{"us-east-1": useast1, "us-east-2": useast2}
+}
+
+func TestRecommendRegions(t *testing.T) {
+	regionalMocks := setupRecommendRegionsMocks(t, map[string]int64{"us-east-1": 3, "us-east-2": 9})
+	itf := selector.NewWithOptions(nil, selector.WithRegionalEC2Clients(func(region string) ec2iface.EC2API {
+		return regionalMocks[region]
+	}))
+	recommendations, err := itf.RecommendRegions(selector.Filters{}, []string{"us-east-1", "us-east-2"}, 10)
+	h.Ok(t, err)
+	h.Equals(t, []selector.RegionRecommendation{
+		{Region: "us-east-2", SpotPlacementScore: 9, InstanceTypes: 1, LowestSpotPricePerHour: aws.Float64(0.0031)},
+		{Region: "us-east-1", SpotPlacementScore: 3, InstanceTypes: 2, LowestSpotPricePerHour: aws.Float64(0.0035)},
+	}, recommendations)
+}
+
+func TestRecommendRegions_SameScore(t *testing.T) {
+	regionalMocks := setupRecommendRegionsMocks(t, map[string]int64{"us-east-1": 5, "us-east-2": 5})
+	itf := selector.NewWithOptions(nil, selector.WithRegionalEC2Clients(func(region string) ec2iface.EC2API {
+		return regionalMocks[region]
+	}))
+	recommendations, err := itf.RecommendRegions(selector.Filters{}, []string{"us-east-2", "us-east-1"}, 10)
+	h.Ok(t, err)
+	// regions with the same score are ranked by the number of matching instance types
+	h.Equals(t, "us-east-1", recommendations[0].Region)
+	h.Equals(t, "us-east-2", recommendations[1].Region)
+}
+
+func TestRecommendRegions_DataProvider(t *testing.T) {
+	regionalMocks := setupRecommendRegionsMocks(t, map[string]int64{})
+	itf := *selector.NewWithOptions(nil,
+		selector.WithRegionalEC2Clients(func(region string) ec2iface.EC2API { return regionalMocks[region] }),
+		selector.WithDataProvider(selector.NewStaticDataProvider(selector.Snapshot{})),
+	)
+	_, err := itf.RecommendRegions(selector.Filters{}, []string{"us-east-1"}, 10)
+	h.Nok(t, err)
+}
+
+func TestRecommendRegions_Failure(t *testing.T) {
+	itf := selector.Selector{EC2: mockedEC2{}}
+	_, err := itf.RecommendRegions(selector.Filters{}, []string{"us-east-1"}, 10)
+	h.Nok(t, err)
+
+	regionalMocks := setupRecommendRegionsMocks(t, map[string]int64{})
+	itf = *selector.NewWithOptions(nil, selector.WithRegionalEC2Clients(func(region string) ec2iface.EC2API {
+		return regionalMocks[region]
+	}))
+	_, err = itf.RecommendRegions(selector.Filters{}, []string{"us-east-1"}, 0)
+	h.Nok(t, err)
+	h.Assert(t, errors.Is(err, selector.ErrInvalidFilters), "Should return ErrInvalidFilters for a target capacity of 0")
+
+	useast1 := regionalMocks["us-east-1"].(mockedEC2)
+	useast1.GetSpotPlacementScoresErr = errors.New("UnauthorizedOperation")
+	regionalMocks["us-east-1"] = useast1
+	_, err = itf.RecommendRegions(selector.Filters{}, []string{"us-east-1", "us-east-2"}, 10)
+	h.Nok(t, err)
+}
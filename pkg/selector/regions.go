@@ -0,0 +1,148 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+const (
+	defaultRegionEnvVar = "AWS_DEFAULT_REGION"
+)
+
+// ResolveRegion determines the AWS Region for the aws session and sets it on the session's config.
+// The region is resolved from the session config (which includes the AWS_REGION env var and the shared config when enabled),
+// then the AWS_DEFAULT_REGION env var, and finally the EC2 instance metadata service (IMDSv2) when running on EC2.
+// An error listing each source that was tried is returned if the region could not be determined.
+func ResolveRegion(sess *session.Session) (string, error) {
+	if region := aws.StringValue(sess.Config.Region); region != "" {
+		return region, nil
+	}
+	if region := os.Getenv(defaultRegionEnvVar); region != "" {
+		sess.Config.Region = aws.String(region)
+		return region, nil
+	}
+	region, err := ec2metadata.New(sess).Region()
+	if err == nil && region == "" {
+		err = fmt.Errorf("no region was returned")
+	}
+	if err != nil {
+		return "", fmt.Errorf("Unable to determine the AWS Region. Tried the session config (AWS_REGION env var and shared config), "+
+			"the %s env var, and the EC2 instance metadata service (%v)", defaultRegionEnvVar, err)
+	}
+	sess.Config.Region = aws.String(region)
+	return region, nil
+}
+
+// FilterAcrossRegions accepts a Filters struct and a list of regions and concurrently selects the instance types
+// matching the criteria within Filters in each region. The results include the instance types matched in each region
+// along with the instance types matched in every region. The Region and AvailabilityZone filters are ignored, so the results
+// of each region are the instance types its DescribeInstanceTypes returns without checking their offerings in the region's zones.
+// The Selector must be created with NewWithOptions from an aws session or with WithRegionalEC2Clients, and without a DataProvider
+// since a snapshot holds the instance types of a single region.
+func (itf Selector) FilterAcrossRegions(filters Filters, regions []string) (*MultiRegionResults, error) {
+	if itf.regionalEC2Client == nil {
+		return nil, fmt.Errorf("Filtering across regions requires a Selector created with an aws session or regional EC2 clients")
+	}
+	if itf.DataProvider != nil {
+		return nil, fmt.Errorf("Filtering across regions cannot be done with a data provider since it holds the instance types of a single region")
+	}
+	if len(regions) == 0 {
+		return nil, fmt.Errorf("At least one region is required to filter across regions")
+	}
+	uniqueRegions := map[string]bool{}
+	for _, region := range regions {
+		uniqueRegions[region] = true
+	}
+	type regionResult struct {
+		region        string
+		instanceTypes []*ec2.InstanceTypeInfo
+		err           error
+	}
+	regionResults := make(chan regionResult, len(uniqueRegions))
+	wg := sync.WaitGroup{}
+	for region := range uniqueRegions {
+		wg.Add(1)
+		go func(region string) {
+			defer wg.Done()
+			regionalSelector := itf
+			regionalSelector.EC2 = itf.regionalEC2Client(region)
+			// the cache only holds the responses of the Selector's own region so each region must query its own EC2 API
+			regionalSelector.cache = nil
+			regionalFilters := filters
+			regionalFilters.Region = nil
+			regionalFilters.AvailabilityZone = nil
+			instanceTypes, err := regionalSelector.rawFilter(regionalFilters)
+			regionResults <- regionResult{region: region, instanceTypes: instanceTypes, err: err}
+		}(region)
+	}
+	wg.Wait()
+	close(regionResults)
+
+	results := &MultiRegionResults{
+		Regions:      map[string][]string{},
+		Intersection: []string{},
+	}
+	matchCounts := map[string]int{}
+	matchedInstanceTypes := map[string]*ec2.InstanceTypeInfo{}
+	for regionResult := range regionResults {
+		if regionResult.err != nil {
+			return nil, fmt.Errorf("Unable to filter instance types in %s: %w", regionResult.region, regionResult.err)
+		}
+		for _, instanceTypeInfo := range regionResult.instanceTypes {
+			matchCounts[*instanceTypeInfo.InstanceType]++
+			matchedInstanceTypes[*instanceTypeInfo.InstanceType] = instanceTypeInfo
+		}
+		results.Regions[regionResult.region] = instanceTypeNames(truncateInstanceTypeInfo(filters, regionResult.instanceTypes))
+	}
+	intersection := []*ec2.InstanceTypeInfo{}
+	for instanceTypeName, count := range matchCounts {
+		if count == len(results.Regions) {
+			intersection = append(intersection, matchedInstanceTypes[instanceTypeName])
+		}
+	}
+	results.Intersection = instanceTypeNames(truncateInstanceTypeInfo(filters, sortInstanceTypeInfo(intersection)))
+	return results, nil
+}
+
+// instanceTypeNames returns the names of the instance types in order
+func instanceTypeNames(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []string {
+	names := []string{}
+	for _, instanceTypeInfo := range instanceTypeInfoSlice {
+		names = append(names, *instanceTypeInfo.InstanceType)
+	}
+	return names
+}
+
+// EnabledRegions returns the sorted names of the regions which are enabled for the account, which can be passed to FilterAcrossRegions
+func (itf Selector) EnabledRegions() ([]string, error) {
+	regionsOutput, err := itf.EC2.DescribeRegions(&ec2.DescribeRegionsInput{})
+	if err != nil {
+		return nil, fmt.Errorf("Encountered an error when describing regions: %w", classifyAPIError(err))
+	}
+	regions := []string{}
+	for _, region := range regionsOutput.Regions {
+		regions = append(regions, aws.StringValue(region.RegionName))
+	}
+	sort.Strings(regions)
+	return regions, nil
+}
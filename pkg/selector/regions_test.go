@@ -0,0 +1,164 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+func TestFilterAcrossRegions(t *testing.T) {
+	regionalMocks := map[string]ec2iface.EC2API{
+		"us-east-1": setupMock(t, describeInstanceTypes, "t3_micro_and_p3_16xl.json"),
+		"us-east-2": setupMock(t, describeInstanceTypes, "t3_micro.json"),
+	}
+	itf := selector.NewWithOptions(nil, selector.WithRegionalEC2Clients(func(region string) ec2iface.EC2API {
+		return regionalMocks[region]
+	}))
+	results, err := itf.FilterAcrossRegions(selector.Filters{}, []string{"us-east-1", "us-east-2", "us-east-2"})
+	h.Ok(t, err)
+	h.Equals(t, []string{"p3.16xlarge", "t3.micro"}, results.Regions["us-east-1"])
+	h.Equals(t, []string{"t3.micro"}, results.Regions["us-east-2"])
+	h.Equals(t, []string{"t3.micro"}, results.Intersection)
+}
+
+func TestFilterAcrossRegions_Failure(t *testing.T) {
+	itf := selector.NewWithOptions(nil, selector.WithRegionalEC2Clients(func(region string) ec2iface.EC2API {
+		if region == "us-east-2" {
+			return mockedEC2{DescribeInstanceTypesErr: errors.New("error")}
+		}
+		return setupMock(t, describeInstanceTypes, "t3_micro.json")
+	}))
+	results, err := itf.FilterAcrossRegions(selector.Filters{}, []string{"us-east-1", "us-east-2"})
+	h.Nok(t, err)
+	h.Assert(t, results == nil, "Results should be nil")
+}
+
+func TestFilterAcrossRegions_NoRegionalClients(t *testing.T) {
+	itf := selector.Selector{
+		EC2: mockedEC2{},
+	}
+	_, err := itf.FilterAcrossRegions(selector.Filters{}, []string{"us-east-1"})
+	h.Nok(t, err)
+
+	itf = *selector.NewWithOptions(nil, selector.WithRegionalEC2Clients(func(region string) ec2iface.EC2API {
+		return mockedEC2{}
+	}))
+	_, err = itf.FilterAcrossRegions(selector.Filters{}, nil)
+	h.Nok(t, err)
+}
+
+func TestFilterAcrossRegions_DataProvider(t *testing.T) {
+	itf := *selector.NewWithOptions(nil,
+		selector.WithRegionalEC2Clients(func(region string) ec2iface.EC2API { return mockedEC2{} }),
+		selector.WithDataProvider(selector.NewStaticDataProvider(selector.Snapshot{})),
+	)
+	_, err := itf.FilterAcrossRegions(selector.Filters{}, []string{"us-east-1", "us-west-2"})
+	h.Nok(t, err)
+}
+
+func TestEnabledRegions(t *testing.T) {
+	itf := selector.Selector{
+		EC2: mockedEC2{
+			DescribeRegionsResp: ec2.DescribeRegionsOutput{
+				Regions: []*ec2.Region{
+					{RegionName: aws.String("us-west-2")},
+					{RegionName: aws.String("eu-west-1")},
+				},
+			},
+		},
+	}
+	regions, err := itf.EnabledRegions()
+	h.Ok(t, err)
+	h.Equals(t, []string{"eu-west-1", "us-west-2"}, regions)
+}
+
+func TestEnabledRegions_Failure(t *testing.T) {
+	itf := selector.Selector{
+		EC2: mockedEC2{DescribeRegionsErr: errors.New("error")},
+	}
+	_, err := itf.EnabledRegions()
+	h.Nok(t, err)
+}
+
+func TestResolveRegion_SessionConfig(t *testing.T) {
+	sess := session.Must(session.NewSession(aws.NewConfig().WithRegion("us-west-2")))
+	region, err := selector.ResolveRegion(sess)
+	h.Ok(t, err)
+	h.Equals(t, "us-west-2", region)
+}
+
+func TestResolveRegion_DefaultRegionEnv(t *testing.T) {
+	defer setEnv(t, "AWS_DEFAULT_REGION", "eu-west-1")()
+	sess := session.Must(session.NewSession())
+	sess.Config.Region = nil
+	region, err := selector.ResolveRegion(sess)
+	h.Ok(t, err)
+	h.Equals(t, "eu-west-1", region)
+	h.Equals(t, "eu-west-1", *sess.Config.Region)
+}
+
+func TestResolveRegion_InstanceMetadata(t *testing.T) {
+	defer setEnv(t, "AWS_DEFAULT_REGION", "")()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest/api/token":
+			w.Write([]byte("token"))
+		case "/latest/dynamic/instance-identity/document":
+			w.Write([]byte(`{"region": "ap-southeast-2"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	sess := session.Must(session.NewSession(aws.NewConfig().WithEndpoint(server.URL + "/latest")))
+	sess.Config.Region = nil
+	region, err := selector.ResolveRegion(sess)
+	h.Ok(t, err)
+	h.Equals(t, "ap-southeast-2", region)
+}
+
+func TestResolveRegion_Failure(t *testing.T) {
+	defer setEnv(t, "AWS_DEFAULT_REGION", "")()
+	defer setEnv(t, "AWS_EC2_METADATA_DISABLED", "true")()
+	sess := session.Must(session.NewSession())
+	sess.Config.Region = nil
+	_, err := selector.ResolveRegion(sess)
+	h.Nok(t, err)
+	h.Assert(t, strings.Contains(err.Error(), "AWS_DEFAULT_REGION"), "Error should list the sources which were tried")
+}
+
+// setEnv sets an environment variable and returns a func which restores the original value
+func setEnv(t *testing.T, key string, value string) func() {
+	original, ok := os.LookupEnv(key)
+	h.Ok(t, os.Setenv(key, value))
+	return func() {
+		if ok {
+			os.Setenv(key, original)
+		} else {
+			os.Unsetenv(key)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+const (
+	// maxRelaxationSteps is the number of times range filters are widened before giving up
+	maxRelaxationSteps = 5
+	// relaxationStepPercent is the percentage of each range bound that is added on every relaxation step
+	relaxationStepPercent = 10
+
+	maxInt = int(^uint(0) >> 1)
+)
+
+// FilterRelaxed accepts a Filters struct which is used to select the available instance types
+// matching the criteria within Filters. If no instance types match, range filters are progressively
+// widened by 10% of their bounds (up to 50%) and, as a last resort, the vcpus to memory ratio filter is removed.
+// The returned RelaxedResults reports each relaxation that was applied to find the matching instance types.
+func (itf Selector) FilterRelaxed(filters Filters) (*RelaxedResults, error) {
+	filters, instanceTypeInfoSlice, locationInstanceOfferings, err := itf.prepareFilters(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	relaxedResults := &RelaxedResults{Filters: filters, Relaxations: []string{}}
+	for step := 0; step <= maxRelaxationSteps+1; step++ {
+		relaxedFilters, relaxations := relaxFilters(filters, step)
+		if step > 0 && reflect.DeepEqual(relaxedFilters, relaxedResults.Filters) {
+			// nothing could be relaxed further on this step
+			continue
+		}
+		matchingInstanceTypes, _, err := itf.filterInstanceTypes(relaxedFilters, instanceTypeInfoSlice, locationInstanceOfferings)
+		if err != nil {
+			return nil, err
+		}
+		relaxedResults.Filters = relaxedFilters
+		relaxedResults.Relaxations = relaxations
+		relaxedResults.InstanceTypes = renameForService(filters.Service, itf.truncateResults(filters, matchingInstanceTypes))
+		if len(matchingInstanceTypes) != 0 {
+			break
+		}
+	}
+	for _, relaxation := range relaxedResults.Relaxations {
+		itf.debugf("relaxed filter: %s", relaxation)
+	}
+	return relaxedResults, nil
+}
+
+// relaxFilters returns a copy of filters with range filters widened for the relaxation step passed in
+// along with a description of each relaxation. Once the step exceeds maxRelaxationSteps, the
+// vcpus to memory ratio filter is also removed.
+func relaxFilters(filters Filters, step int) (Filters, []string) {
+	relaxedFilters := filters
+	relaxations := []string{}
+	if step == 0 {
+		return relaxedFilters, relaxations
+	}
+	rangeFilters := []struct {
+		name   string
+		filter **IntRangeFilter
+	}{
+		{vcpusRange, &relaxedFilters.VCpusRange},
+		{memoryRange, &relaxedFilters.MemoryRange},
+		{gpusRange, &relaxedFilters.GpusRange},
+		{gpuMemoryRange, &relaxedFilters.GpuMemoryRange},
+		{networkInterfaces, &relaxedFilters.NetworkInterfaces},
+		{networkPerformance, &relaxedFilters.NetworkPerformance},
+	}
+	widenPercent := int(math.Min(float64(step), maxRelaxationSteps)) * relaxationStepPercent
+	for _, rangeFilter := range rangeFilters {
+		if *rangeFilter.filter == nil {
+			continue
+		}
+		original := **rangeFilter.filter
+		widened := widenIntRange(original, widenPercent)
+		if widened == original {
+			continue
+		}
+		*rangeFilter.filter = &widened
+		relaxations = append(relaxations, fmt.Sprintf("%s widened from %d-%d to %d-%d", rangeFilter.name,
+			original.LowerBound, original.UpperBound, widened.LowerBound, widened.UpperBound))
+	}
+	if step > maxRelaxationSteps && filters.VCpusToMemoryRatio != nil {
+		relaxedFilters.VCpusToMemoryRatio = nil
+		relaxations = append(relaxations, fmt.Sprintf("%s of %.2f removed", vcpusToMemoryRatio, *filters.VCpusToMemoryRatio))
+	}
+	return relaxedFilters, relaxations
+}
+
+// widenIntRange lowers the lower bound and raises the upper bound of an IntRangeFilter by the percent passed in.
+// The lower bound will not go below 0 and an upper bound of infinity (maxInt) is left untouched.
+func widenIntRange(intRange IntRangeFilter, percent int) IntRangeFilter {
+	lowerDelta := int(math.Ceil(float64(intRange.LowerBound) * float64(percent) / 100))
+	upperDelta := math.Ceil(float64(intRange.UpperBound) * float64(percent) / 100)
+	widened := IntRangeFilter{
+		LowerBound: int(math.Max(0, float64(intRange.LowerBound-lowerDelta))),
+		UpperBound: intRange.UpperBound,
+	}
+	if float64(intRange.UpperBound)+upperDelta < float64(maxInt) {
+		widened.UpperBound = intRange.UpperBound + int(upperDelta)
+	}
+	return widened
+}
@@ -0,0 +1,96 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func TestFilterRelaxed_NoRelaxationNeeded(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypes, "t3_micro.json")
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	filters := selector.Filters{
+		VCpusRange: &selector.IntRangeFilter{LowerBound: 2, UpperBound: 2},
+	}
+	results, err := itf.FilterRelaxed(filters)
+	h.Ok(t, err)
+	h.Assert(t, len(results.InstanceTypes) == 1, "Should return 1 instance type without relaxing")
+	h.Assert(t, len(results.Relaxations) == 0, "Should not relax any filters")
+}
+
+func TestFilterRelaxed_WidensRange(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypes, "t3_micro_and_p3_16xl.json")
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	filters := selector.Filters{
+		MemoryRange: &selector.IntRangeFilter{LowerBound: 1100, UpperBound: 1100},
+	}
+	results, err := itf.FilterRelaxed(filters)
+	h.Ok(t, err)
+	h.Assert(t, len(results.InstanceTypes) == 1, "Should return 1 instance type after relaxing memory")
+	h.Assert(t, *results.InstanceTypes[0].InstanceType == "t3.micro", "Should return t3.micro, got %s instead", *results.InstanceTypes[0].InstanceType)
+	h.Assert(t, len(results.Relaxations) == 1, "Should relax only the memory filter")
+	h.Equals(t, selector.IntRangeFilter{LowerBound: 990, UpperBound: 1210}, *results.Filters.MemoryRange)
+	h.Equals(t, selector.IntRangeFilter{LowerBound: 1100, UpperBound: 1100}, *filters.MemoryRange)
+}
+
+func TestFilterRelaxed_RemovesRatio(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypes, "t3_micro.json")
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	filters := selector.Filters{
+		VCpusToMemoryRatio: aws.Float64(8.0),
+	}
+	results, err := itf.FilterRelaxed(filters)
+	h.Ok(t, err)
+	h.Assert(t, len(results.InstanceTypes) == 1, "Should return 1 instance type after removing the ratio filter")
+	h.Assert(t, results.Filters.VCpusToMemoryRatio == nil, "Relaxed filters should not include a ratio")
+	h.Assert(t, len(results.Relaxations) == 1, "Should only relax the ratio filter")
+}
+
+func TestFilterRelaxed_NoMatches(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypes, "t3_micro.json")
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	filters := selector.Filters{
+		VCpusRange: &selector.IntRangeFilter{LowerBound: 64, UpperBound: 64},
+		Fpga:       aws.Bool(true),
+	}
+	results, err := itf.FilterRelaxed(filters)
+	h.Ok(t, err)
+	h.Assert(t, len(results.InstanceTypes) == 0, "Should return 0 instance types since fpga is never relaxed")
+	h.Equals(t, selector.IntRangeFilter{LowerBound: 32, UpperBound: 96}, *results.Filters.VCpusRange)
+}
+
+func TestFilterRelaxed_Failure(t *testing.T) {
+	ec2Mock := mockedEC2{
+		DescribeInstanceTypesErr: errors.New("error"),
+	}
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	results, err := itf.FilterRelaxed(selector.Filters{})
+	h.Assert(t, results == nil, "Results should be nil")
+	h.Nok(t, err)
+}
@@ -21,11 +21,14 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector/comparators"
 	"github.com/aws/amazon-ec2-instance-selector/pkg/selector/outputs"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
 )
 
 var (
@@ -34,17 +37,25 @@ var (
 )
 
 const (
-	// regionNameRegex Matches strings like: us-east-1 or us-east-2
-	regionNameRegex = `^[a-z]{2,3}\-([a-z]{1,10}\-)?[a-z]{1,10}\-[1-9]`
-	// zoneIDRegex Matches strings like: use1-az1 or use2-az3
-	zoneIDRegex = `^[a-z]{3}[1-9]{1}\-az[1-9]$`
-	// zoneNameRegex Matches strings like: us-east-1a or us-east-2c
-	zoneNameRegex          = `^[a-z]{2,3}\-([a-z]{1,10}\-)?[a-z]{1,10}\-[1-9][a-z]$`
-	locationFilterKey      = "location"
-	zoneIDLocationType     = "availability-zone-id"
-	zoneNameLocationType   = "availability-zone"
-	regionNameLocationType = "region"
-	sdkName                = "instance-selector"
+	// regionNameRegex Matches strings like: us-east-1, us-gov-west-1, cn-northwest-1, or us-isob-east-1
+	regionNameRegex = `^[a-z]{2,3}\-([a-z]{1,10}\-)?[a-z]{1,10}\-[1-9][0-9]?$`
+	// zoneIDRegex Matches strings like: use1-az1, apne1-az4, usgw1-az2, or cnnw1-az3
+	zoneIDRegex = `^[a-z]{3,5}[1-9][0-9]?\-az[1-9][0-9]?$`
+	// zoneNameRegex Matches strings like: us-east-1a, us-gov-west-1b, or cn-north-1a
+	zoneNameRegex = `^[a-z]{2,3}\-([a-z]{1,10}\-)?[a-z]{1,10}\-[1-9][0-9]?[a-z]$`
+	// localZoneIDRegex Matches Local Zone ids like: usw2-lax1-az1
+	localZoneIDRegex = `^[a-z]{3,5}[1-9][0-9]?\-[a-z]{2,5}[1-9]\-az[1-9][0-9]?$`
+	// localZoneNameRegex Matches Local Zone names like: us-west-2-lax-1a
+	localZoneNameRegex = `^[a-z]{2,3}\-([a-z]{1,10}\-)?[a-z]{1,10}\-[1-9][0-9]?\-[a-z]{2,5}\-[1-9][a-z]$`
+	// wavelengthZoneIDRegex Matches Wavelength Zone ids like: use1-wl1-bos-wlz1
+	wavelengthZoneIDRegex = `^[a-z]{3,5}[1-9][0-9]?\-wl[1-9]\-[a-z]{2,5}\-wlz[1-9]$`
+	// wavelengthZoneNameRegex Matches Wavelength Zone names like: us-east-1-wl1-bos-wlz-1
+	wavelengthZoneNameRegex = `^[a-z]{2,3}\-([a-z]{1,10}\-)?[a-z]{1,10}\-[1-9][0-9]?\-wl[1-9]\-[a-z]{2,5}\-wlz\-[1-9]$`
+	locationFilterKey       = "location"
+	zoneIDLocationType      = "availability-zone-id"
+	zoneNameLocationType    = "availability-zone"
+	regionNameLocationType  = "region"
+	sdkName                 = "instance-selector"
 
 	// Filter Keys
 
@@ -55,6 +66,7 @@ const (
 	vcpusRange             = "vcpusRange"
 	memoryRange            = "memoryRange"
 	gpuMemoryRange         = "gpuMemoryRange"
+	gpuMemoryGiBRange      = "gpuMemoryGiBRange"
 	gpusRange              = "gpusRange"
 	placementGroupStrategy = "placementGroupStrategy"
 	hypervisor             = "hypervisor"
@@ -66,16 +78,97 @@ const (
 	currentGeneration      = "currentGeneration"
 	networkInterfaces      = "networkInterfaces"
 	networkPerformance     = "networkPerformance"
+	location               = "location"
+	instanceTypesFilter    = "instanceTypes"
+	serviceFilter          = "service"
+)
+
+const (
+	// defaultCacheKey names the cache files when the region of the EC2 client is not known
+	defaultCacheKey = "default"
 )
 
 // New creates an instance of Selector provided an aws session
 func New(sess *session.Session) *Selector {
+	return NewWithOptions(sess)
+}
+
+// NewWithOptions creates an instance of Selector provided an aws session and any number of Options.
+// The aws session may be nil when WithEC2Client is used.
+func NewWithOptions(sess *session.Session, opts ...Option) *Selector {
+	selectorOpts := selectorOptions{}
+	for _, opt := range opts {
+		opt(&selectorOpts)
+	}
+	itf := &Selector{
+		EC2:               selectorOpts.ec2Client,
+		DataProvider:      selectorOpts.dataProvider,
+		Logger:            selectorOpts.logger,
+		Progress:          selectorOpts.progress,
+		regionalEC2Client: selectorOpts.regionalEC2Client,
+	}
+	if sess != nil && (itf.EC2 == nil || itf.regionalEC2Client == nil) {
+		ec2Config := aws.NewConfig()
+		if selectorOpts.region != nil {
+			ec2Config = ec2Config.WithRegion(*selectorOpts.region)
+		}
+		if selectorOpts.retryer != nil {
+			ec2Config = request.WithRetryer(ec2Config, selectorOpts.retryer)
+		}
+		if selectorOpts.roleARN != nil {
+			ec2Config = ec2Config.WithCredentials(stscreds.NewCredentials(sess, *selectorOpts.roleARN, func(provider *stscreds.AssumeRoleProvider) {
+				provider.ExternalID = selectorOpts.externalID
+			}))
+		}
+		if itf.EC2 == nil {
+			clientConfig := ec2Config
+			if selectorOpts.endpoint != nil {
+				// the regional clients are created from ec2Config so they keep resolving the endpoint of their region
+				clientConfig = ec2Config.Copy().WithEndpoint(*selectorOpts.endpoint)
+			}
+			itf.EC2 = newEC2Client(sess, clientConfig, selectorOpts)
+		}
+		if itf.regionalEC2Client == nil {
+			itf.regionalEC2Client = func(region string) ec2iface.EC2API {
+				return newEC2Client(sess, ec2Config.Copy().WithRegion(region), selectorOpts)
+			}
+		}
+	}
+	if selectorOpts.cacheTTL > 0 {
+		cacheDir := selectorOpts.cacheDir
+		// cache files are named by region, so the offerings of an assumed role's account would be read by other accounts
+		if selectorOpts.roleARN != nil {
+			cacheDir = ""
+		}
+		itf.cache = newInstanceTypesCache(selectorOpts.cacheTTL, cacheDir, cacheKey(sess, selectorOpts))
+	}
+	return itf
+}
+
+// cacheKey returns the region the EC2 client is created in so that cache files of different regions do not collide
+func cacheKey(sess *session.Session, selectorOpts selectorOptions) string {
+	if selectorOpts.region != nil {
+		return *selectorOpts.region
+	}
+	if sess != nil && aws.StringValue(sess.Config.Region) != "" {
+		return *sess.Config.Region
+	}
+	return defaultCacheKey
+}
+
+// newEC2Client creates an EC2 client from the aws session with the instance-selector user agent
+// and any user agent segments and request handlers from the options
+func newEC2Client(sess *session.Session, ec2Config *aws.Config, selectorOpts selectorOptions) *ec2.EC2 {
+	ec2Client := ec2.New(sess, ec2Config)
 	userAgentTag := fmt.Sprintf("%s-v%s", sdkName, versionID)
-	userAgentHandler := request.MakeAddToUserAgentFreeFormHandler(userAgentTag)
-	sess.Handlers.Build.PushBack(userAgentHandler)
-	return &Selector{
-		EC2: ec2.New(sess),
+	ec2Client.Handlers.Build.PushBack(request.MakeAddToUserAgentFreeFormHandler(userAgentTag))
+	for _, userAgent := range selectorOpts.userAgents {
+		ec2Client.Handlers.Build.PushBack(request.MakeAddToUserAgentFreeFormHandler(userAgent))
+	}
+	for _, handlersFn := range selectorOpts.handlerFns {
+		handlersFn(&ec2Client.Handlers)
 	}
+	return ec2Client
 }
 
 // Filter accepts a Filters struct which is used to select the available instance types
@@ -92,8 +185,8 @@ func (itf Selector) FilterVerbose(filters Filters) ([]*ec2.InstanceTypeInfo, err
 	if err != nil {
 		return nil, err
 	}
-	instanceTypeInfoSlice = itf.truncateResults(filters.MaxResults, instanceTypeInfoSlice)
-	return instanceTypeInfoSlice, nil
+	instanceTypeInfoSlice = itf.truncateResults(filters, instanceTypeInfoSlice)
+	return renameForService(filters.Service, instanceTypeInfoSlice), nil
 }
 
 // FilterWithOutput accepts a Filters struct which is used to select the available instance types
@@ -103,99 +196,267 @@ func (itf Selector) FilterWithOutput(filters Filters, outputFn InstanceTypesOutp
 	if err != nil {
 		return nil, err
 	}
-	instanceTypeInfoSlice = itf.truncateResults(filters.MaxResults, instanceTypeInfoSlice)
+	instanceTypeInfoSlice = renameForService(filters.Service, itf.truncateResults(filters, instanceTypeInfoSlice))
 	output := outputFn.Output(instanceTypeInfoSlice)
 	return output, nil
 }
 
-func (itf Selector) truncateResults(maxResults *int, instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []*ec2.InstanceTypeInfo {
-	if maxResults == nil {
+// Count accepts a Filters struct which is used to select the available instance types
+// matching the criteria within Filters and returns the number of matching instance types.
+// MaxResults is ignored so that the total number of matches is returned.
+func (itf Selector) Count(filters Filters) (int, error) {
+	instanceTypeInfoSlice, err := itf.rawFilter(filters)
+	if err != nil {
+		return 0, err
+	}
+	return len(instanceTypeInfoSlice), nil
+}
+
+// truncateResults orders the instance types and keeps at most MaxResultsPerFamily of each family and MaxResults overall
+func (itf Selector) truncateResults(filters Filters, instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []*ec2.InstanceTypeInfo {
+	return truncateInstanceTypeInfo(filters, instanceTypeInfoSlice)
+}
+
+// truncateInstanceTypeInfo orders the instance types by the SortBy keys before they are truncated to MaxResultsPerFamily and MaxResults.
+// Without SortBy keys, the instance types are ranked by relevance so that the best candidates are kept rather than the first names
+// alphabetically, and the kept instance types are then returned in name order. The instance type info slice is not modified.
+func truncateInstanceTypeInfo(filters Filters, instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []*ec2.InstanceTypeInfo {
+	instanceTypeInfoSlice = append([]*ec2.InstanceTypeInfo{}, instanceTypeInfoSlice...)
+	if len(filters.SortBy) == 0 {
+		instanceTypeInfoSlice = rankByRelevance(instanceTypeInfoSlice)
+	} else {
+		// the sort keys were already checked when the filters were validated
+		instanceTypeInfoSlice, _ = sortInstanceTypeInfoBy(filters.SortBy, instanceTypeInfoSlice)
+	}
+	instanceTypeInfoSlice = truncateResultsPerFamily(filters.MaxResultsPerFamily, instanceTypeInfoSlice)
+	if maxResults := filters.MaxResults; maxResults != nil && *maxResults < len(instanceTypeInfoSlice) {
+		instanceTypeInfoSlice = instanceTypeInfoSlice[0:*maxResults]
+	}
+	if len(filters.SortBy) == 0 {
+		instanceTypeInfoSlice = sortInstanceTypeInfo(instanceTypeInfoSlice)
+	}
+	return instanceTypeInfoSlice
+}
+
+// truncateResultsPerFamily keeps at most maxResultsPerFamily instance types from each instance family
+// while preserving the order of the instance types
+func truncateResultsPerFamily(maxResultsPerFamily *int, instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []*ec2.InstanceTypeInfo {
+	if maxResultsPerFamily == nil {
 		return instanceTypeInfoSlice
 	}
-	upperIndex := *maxResults
-	if *maxResults > len(instanceTypeInfoSlice) {
-		upperIndex = len(instanceTypeInfoSlice)
+	familyCounts := map[string]int{}
+	truncated := []*ec2.InstanceTypeInfo{}
+	for _, instanceTypeInfo := range instanceTypeInfoSlice {
+		family := instanceFamily(*instanceTypeInfo.InstanceType)
+		if familyCounts[family] >= *maxResultsPerFamily {
+			continue
+		}
+		familyCounts[family]++
+		truncated = append(truncated, instanceTypeInfo)
 	}
-	return instanceTypeInfoSlice[0:upperIndex]
+	return truncated
+}
+
+// instanceFamily returns the family of an instance type, which is everything before the size (i.e. m5d for m5d.xlarge)
+func instanceFamily(instanceType string) string {
+	return strings.SplitN(instanceType, ".", 2)[0]
 }
 
 // rawFilter accepts a Filters struct which is used to select the available instance types
 // matching the criteria within Filters and returns the detailed specs of matching instance types
 func (itf Selector) rawFilter(filters Filters) ([]*ec2.InstanceTypeInfo, error) {
-	var location string
-	if filters.AvailabilityZone != nil {
-		location = *filters.AvailabilityZone
-	} else if filters.Region != nil {
-		location = *filters.Region
+	filters, instanceTypeInfoSlice, locationInstanceOfferings, err := itf.prepareFilters(filters)
+	if err != nil {
+		return nil, err
 	}
-	locationInstanceOfferings, err := itf.RetrieveInstanceTypesSupportedInLocation(location)
+	matchingInstanceTypes, _, err := itf.filterInstanceTypes(filters, instanceTypeInfoSlice, locationInstanceOfferings)
+	return matchingInstanceTypes, err
+}
+
+// Explain accepts a Filters struct which is used to select the available instance types
+// matching the criteria within Filters and returns the matching instance types along with
+// every filter which rejected each of the instance types that did not match
+func (itf Selector) Explain(filters Filters) (*Explanation, error) {
+	filters, instanceTypeInfoSlice, locationInstanceOfferings, err := itf.prepareFilters(filters)
 	if err != nil {
 		return nil, err
 	}
+	matchingInstanceTypes, rejections, err := itf.filterInstanceTypes(filters, instanceTypeInfoSlice, locationInstanceOfferings)
+	if err != nil {
+		return nil, err
+	}
+	return &Explanation{
+		InstanceTypes: renameForService(filters.Service, itf.truncateResults(filters, matchingInstanceTypes)),
+		Rejections:    rejections,
+	}, nil
+}
 
-	instanceTypesInput := &ec2.DescribeInstanceTypesInput{}
-	instanceTypeCandidates := map[string]*ec2.InstanceTypeInfo{}
-	// innerErr will hold any error while processing DescribeInstanceTypes pages
-	var innerErr error
-
-	err = itf.EC2.DescribeInstanceTypesPages(instanceTypesInput, func(page *ec2.DescribeInstanceTypesOutput, lastPage bool) bool {
-		for _, instanceTypeInfo := range page.InstanceTypes {
-			instanceTypeName := *instanceTypeInfo.InstanceType
-			instanceTypeCandidates[instanceTypeName] = instanceTypeInfo
-			isFpga := instanceTypeInfo.FpgaInfo != nil
-
-			// filterToInstanceSpecMappingPairs is a map of filter name [key] to filter pair [value].
-			// A filter pair includes user input filter value and instance spec value retrieved from DescribeInstanceTypes
-			filterToInstanceSpecMappingPairs := map[string]filterPair{
-				cpuArchitecture:        {filters.CPUArchitecture, instanceTypeInfo.ProcessorInfo.SupportedArchitectures},
-				usageClass:             {filters.UsageClass, instanceTypeInfo.SupportedUsageClasses},
-				rootDeviceType:         {filters.RootDeviceType, instanceTypeInfo.SupportedRootDeviceTypes},
-				hibernationSupported:   {filters.HibernationSupported, instanceTypeInfo.HibernationSupported},
-				vcpusRange:             {filters.VCpusRange, instanceTypeInfo.VCpuInfo.DefaultVCpus},
-				memoryRange:            {filters.MemoryRange, instanceTypeInfo.MemoryInfo.SizeInMiB},
-				gpuMemoryRange:         {filters.GpuMemoryRange, getTotalGpuMemory(instanceTypeInfo.GpuInfo)},
-				gpusRange:              {filters.GpusRange, getTotalGpusCount(instanceTypeInfo.GpuInfo)},
-				placementGroupStrategy: {filters.PlacementGroupStrategy, instanceTypeInfo.PlacementGroupInfo.SupportedStrategies},
-				hypervisor:             {filters.Hypervisor, instanceTypeInfo.Hypervisor},
-				baremetal:              {filters.BareMetal, instanceTypeInfo.BareMetal},
-				burstable:              {filters.Burstable, instanceTypeInfo.BurstablePerformanceSupported},
-				fpga:                   {filters.Fpga, &isFpga},
-				enaSupport:             {filters.EnaSupport, supportSyntaxToBool(instanceTypeInfo.NetworkInfo.EnaSupport)},
-				vcpusToMemoryRatio:     {filters.VCpusToMemoryRatio, calculateVCpusToMemoryRatio(instanceTypeInfo.VCpuInfo.DefaultVCpus, instanceTypeInfo.MemoryInfo.SizeInMiB)},
-				currentGeneration:      {filters.CurrentGeneration, instanceTypeInfo.CurrentGeneration},
-				networkInterfaces:      {filters.NetworkInterfaces, instanceTypeInfo.NetworkInfo.MaximumNetworkInterfaces},
-				networkPerformance:     {filters.NetworkPerformance, getNetworkPerformance(instanceTypeInfo.NetworkInfo.NetworkPerformance)},
-			}
+// prepareFilters validates the filters, retrieves the instance types and location offerings needed to execute the filters
+// and resolves any filters which are derived from that data, like BaseInstanceType
+func (itf Selector) prepareFilters(filters Filters) (Filters, []*ec2.InstanceTypeInfo, map[string]string, error) {
+	if err := filters.Validate(); err != nil {
+		return filters, nil, nil, err
+	}
+	if filters.GpuMemoryGiBRange != nil {
+		filters.GpuMemoryRange = &IntRangeFilter{
+			LowerBound: gibToMiB(filters.GpuMemoryGiBRange.LowerBound),
+			UpperBound: gibToMiB(filters.GpuMemoryGiBRange.UpperBound),
+		}
+		filters.GpuMemoryGiBRange = nil
+	}
+	locationInstanceOfferings, err := itf.RetrieveInstanceTypesSupportedInLocation(getLocation(filters))
+	if err != nil {
+		return filters, nil, nil, err
+	}
+	instanceTypeInfoSlice, err := itf.retrieveInstanceTypes(filters.RawEC2Filters)
+	if err != nil {
+		return filters, nil, nil, err
+	}
+	filters, err = itf.resolveBaseInstanceType(filters, instanceTypeInfoSlice)
+	if err != nil {
+		return filters, nil, nil, err
+	}
+	return filters, instanceTypeInfoSlice, locationInstanceOfferings, nil
+}
 
-			if !isSupportedInLocation(locationInstanceOfferings, instanceTypeName) {
-				delete(instanceTypeCandidates, instanceTypeName)
-			}
+// retrieveInstanceTypes pages through DescribeInstanceTypes and returns the specs of all instance types matching the raw EC2 filters.
+// The cache is only used when there are no raw EC2 filters since it holds every instance type.
+// The DataProvider is used instead of the EC2 API when it is set.
+func (itf Selector) retrieveInstanceTypes(rawEC2Filters []*ec2.Filter) ([]*ec2.InstanceTypeInfo, error) {
+	if itf.DataProvider != nil {
+		itf.debugf("retrieving instance types from the data provider")
+		return itf.DataProvider.InstanceTypes(rawEC2Filters)
+	}
+	useCache := itf.cache != nil && len(rawEC2Filters) == 0
+	if useCache {
+		if instanceTypeInfoSlice, ok := itf.cache.getInstanceTypes(); ok {
+			itf.debugf("using %d cached instance types", len(instanceTypeInfoSlice))
+			return instanceTypeInfoSlice, nil
+		}
+	}
+	instanceTypesInput := newInstanceTypesInput(rawEC2Filters)
+	instanceTypeInfoSlice := []*ec2.InstanceTypeInfo{}
+	pageCount := 0
 
-			var isInstanceSupported bool
-			isInstanceSupported, innerErr = itf.executeFilters(filterToInstanceSpecMappingPairs, instanceTypeName)
-			if innerErr != nil {
-				// stops paging through instance types
-				return false
-			}
-			if !isInstanceSupported {
-				delete(instanceTypeCandidates, instanceTypeName)
-			}
+	itf.debugf("calling DescribeInstanceTypes")
+	err := itf.EC2.DescribeInstanceTypesPages(instanceTypesInput, func(page *ec2.DescribeInstanceTypesOutput, lastPage bool) bool {
+		pageCount++
+		itf.debugf("processing DescribeInstanceTypes page %d with %d instance types", pageCount, len(page.InstanceTypes))
+		if itf.Progress != nil && itf.Progress.PageFetched != nil {
+			itf.Progress.PageFetched(pageCount, len(page.InstanceTypes))
 		}
+		instanceTypeInfoSlice = append(instanceTypeInfoSlice, page.InstanceTypes...)
 		// continue paging through instance types
 		return true
 	})
 	if err != nil {
-		return nil, err
+		return nil, classifyAPIError(err)
 	}
-	if innerErr != nil {
-		return nil, innerErr
+	itf.debugf("DescribeInstanceTypes returned %d instance types in %d pages", len(instanceTypeInfoSlice), pageCount)
+	if useCache {
+		itf.cache.setInstanceTypes(instanceTypeInfoSlice)
 	}
+	return instanceTypeInfoSlice, nil
+}
 
-	instanceTypeInfoSlice := []*ec2.InstanceTypeInfo{}
+// newInstanceTypesInput creates the DescribeInstanceTypesInput used to retrieve all instance types matching the raw EC2 filters
+func newInstanceTypesInput(rawEC2Filters []*ec2.Filter) *ec2.DescribeInstanceTypesInput {
+	instanceTypesInput := &ec2.DescribeInstanceTypesInput{}
+	if len(rawEC2Filters) != 0 {
+		instanceTypesInput.Filters = rawEC2Filters
+	}
+	return instanceTypesInput
+}
+
+// filterInstanceTypes executes the filters against each of the instance types passed in
+// and returns the detailed specs of matching instance types sorted by name along with
+// the filter rejections for each instance type that did not match
+func (itf Selector) filterInstanceTypes(filters Filters, instanceTypeInfoSlice []*ec2.InstanceTypeInfo, locationInstanceOfferings map[string]string) ([]*ec2.InstanceTypeInfo, map[string][]FilterRejection, error) {
+	instanceTypeCandidates := map[string]*ec2.InstanceTypeInfo{}
+	rejections := map[string][]FilterRejection{}
+	matchedCount := 0
+	for i, instanceTypeInfo := range instanceTypeInfoSlice {
+		instanceTypeName := *instanceTypeInfo.InstanceType
+		instanceTypeCandidates[instanceTypeName] = instanceTypeInfo
+		isFpga := instanceTypeInfo.FpgaInfo != nil
+
+		// filterToInstanceSpecMappingPairs is a map of filter name [key] to filter pair [value].
+		// A filter pair includes user input filter value and instance spec value retrieved from DescribeInstanceTypes
+		filterToInstanceSpecMappingPairs := map[string]filterPair{
+			cpuArchitecture:        {filters.CPUArchitecture, instanceTypeInfo.ProcessorInfo.SupportedArchitectures},
+			usageClass:             {filters.UsageClass, instanceTypeInfo.SupportedUsageClasses},
+			rootDeviceType:         {filters.RootDeviceType, instanceTypeInfo.SupportedRootDeviceTypes},
+			hibernationSupported:   {filters.HibernationSupported, instanceTypeInfo.HibernationSupported},
+			vcpusRange:             {filters.VCpusRange, instanceTypeInfo.VCpuInfo.DefaultVCpus},
+			memoryRange:            {filters.MemoryRange, instanceTypeInfo.MemoryInfo.SizeInMiB},
+			gpuMemoryRange:         {filters.GpuMemoryRange, getTotalGpuMemory(instanceTypeInfo.GpuInfo)},
+			gpusRange:              {filters.GpusRange, getTotalGpusCount(instanceTypeInfo.GpuInfo)},
+			placementGroupStrategy: {filters.PlacementGroupStrategy, instanceTypeInfo.PlacementGroupInfo.SupportedStrategies},
+			hypervisor:             {filters.Hypervisor, instanceTypeInfo.Hypervisor},
+			baremetal:              {filters.BareMetal, instanceTypeInfo.BareMetal},
+			burstable:              {filters.Burstable, instanceTypeInfo.BurstablePerformanceSupported},
+			fpga:                   {filters.Fpga, &isFpga},
+			enaSupport:             {filters.EnaSupport, supportSyntaxToBool(instanceTypeInfo.NetworkInfo.EnaSupport)},
+			vcpusToMemoryRatio:     {filters.VCpusToMemoryRatio, calculateVCpusToMemoryRatio(instanceTypeInfo.VCpuInfo.DefaultVCpus, instanceTypeInfo.MemoryInfo.SizeInMiB)},
+			currentGeneration:      {filters.CurrentGeneration, instanceTypeInfo.CurrentGeneration},
+			networkInterfaces:      {filters.NetworkInterfaces, instanceTypeInfo.NetworkInfo.MaximumNetworkInterfaces},
+			networkPerformance:     {filters.NetworkPerformance, getNetworkPerformance(instanceTypeInfo.NetworkInfo.NetworkPerformance)},
+			instanceTypesFilter:    {filters.InstanceTypes, instanceTypeInfo.InstanceType},
+		}
+
+		if !isSupportedInLocation(locationInstanceOfferings, instanceTypeName) {
+			itf.debugf("instance type %s eliminated: not offered in location %s", instanceTypeName, getLocation(filters))
+			rejections[instanceTypeName] = append(rejections[instanceTypeName], FilterRejection{
+				Filter:            location,
+				FilterValue:       getLocation(filters),
+				InstanceTypeValue: "not offered",
+			})
+			delete(instanceTypeCandidates, instanceTypeName)
+		}
+
+		if !isSupportedByService(filters.Service, instanceTypeInfo) {
+			itf.debugf("instance type %s eliminated: not supported by service %s", instanceTypeName, *filters.Service)
+			rejections[instanceTypeName] = append(rejections[instanceTypeName], FilterRejection{
+				Filter:            serviceFilter,
+				FilterValue:       *filters.Service,
+				InstanceTypeValue: "not supported",
+			})
+			delete(instanceTypeCandidates, instanceTypeName)
+		}
+
+		filterRejections, err := itf.executeFilters(filterToInstanceSpecMappingPairs, instanceTypeName)
+		if err != nil {
+			return nil, nil, err
+		}
+		filterRejections = append(filterRejections, itf.executeCustomFilters(instanceTypeInfo)...)
+		if len(filterRejections) != 0 {
+			rejections[instanceTypeName] = append(rejections[instanceTypeName], filterRejections...)
+			delete(instanceTypeCandidates, instanceTypeName)
+		}
+		if _, ok := instanceTypeCandidates[instanceTypeName]; ok {
+			matchedCount++
+		}
+		if itf.Progress != nil && itf.Progress.InstanceTypeEvaluated != nil {
+			itf.Progress.InstanceTypeEvaluated(i+1, matchedCount, len(instanceTypeInfoSlice))
+		}
+	}
+
+	matchingInstanceTypeInfoSlice := []*ec2.InstanceTypeInfo{}
 	for _, instanceTypeInfo := range instanceTypeCandidates {
-		instanceTypeInfoSlice = append(instanceTypeInfoSlice, instanceTypeInfo)
+		matchingInstanceTypeInfoSlice = append(matchingInstanceTypeInfoSlice, instanceTypeInfo)
 	}
-	return sortInstanceTypeInfo(instanceTypeInfoSlice), nil
+	itf.debugf("%d of %d instance types matched filters", len(matchingInstanceTypeInfoSlice), len(instanceTypeInfoSlice))
+	return sortInstanceTypeInfo(matchingInstanceTypeInfoSlice), rejections, nil
+}
+
+// getLocation returns the most specific location set in the filters, preferring the availability zone over the region
+func getLocation(filters Filters) string {
+	if filters.AvailabilityZone != nil {
+		return *filters.AvailabilityZone
+	} else if filters.Region != nil {
+		return *filters.Region
+	}
+	return ""
 }
 
 // sortInstanceTypeInfo will sort based on instance type info alpha-numerically
@@ -208,10 +469,18 @@ func sortInstanceTypeInfo(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []*ec2.
 	return instanceTypeInfoSlice
 }
 
-// executeFilters accepts a mapping of filter name to filter pairs which are iterated through
+// executeFilters accepts a mapping of filter name to filter pairs which are iterated through in name order
 // to determine if the instance type matches the filter values.
-func (itf Selector) executeFilters(filterToInstanceSpecMapping map[string]filterPair, instanceType string) (bool, error) {
-	for filterName, filterPair := range filterToInstanceSpecMapping {
+// A FilterRejection is returned for each filter the instance type does not match.
+func (itf Selector) executeFilters(filterToInstanceSpecMapping map[string]filterPair, instanceType string) ([]FilterRejection, error) {
+	filterNames := []string{}
+	for filterName := range filterToInstanceSpecMapping {
+		filterNames = append(filterNames, filterName)
+	}
+	sort.Strings(filterNames)
+	rejections := []FilterRejection{}
+	for _, filterName := range filterNames {
+		filterPair := filterToInstanceSpecMapping[filterName]
 		filterVal := filterPair.filterValue
 		instanceSpec := filterPair.instanceSpec
 		// if filter is nil, user did not specify a filter, so skip evaluation
@@ -223,57 +492,101 @@ func (itf Selector) executeFilters(filterToInstanceSpecMapping map[string]filter
 		filterDetailsMsg := fmt.Sprintf("filter (%s: %s => %s) corresponding to instance spec (%s => %s) for instance type %s", filterName, filterVal, filterType, instanceSpec, instanceSpecType, instanceType)
 		invalidInstanceSpecTypeMsg := fmt.Sprintf("Unable to process for %s", filterDetailsMsg)
 
+		var isSupported bool
 		// Determine appropriate filter comparator by switching on filter type
 		switch filter := filterVal.(type) {
-		case *string:
+		case []string:
 			switch iSpec := instanceSpec.(type) {
 			case []*string:
-				if !isSupportedFromStrings(iSpec, filter) {
-					return false, nil
-				}
+				isSupported = comparators.IsSupportedFromStrings(iSpec, filter)
 			case *string:
-				if !isSupportedFromString(iSpec, filter) {
-					return false, nil
-				}
+				isSupported = comparators.IsSupportedFromString(iSpec, filter)
 			default:
-				return false, fmt.Errorf(invalidInstanceSpecTypeMsg)
+				return nil, fmt.Errorf(invalidInstanceSpecTypeMsg)
 			}
 		case *bool:
 			switch iSpec := instanceSpec.(type) {
 			case *bool:
-				if !isSupportedWithBool(iSpec, filter) {
-					return false, nil
-				}
+				isSupported = comparators.IsSupportedWithBool(iSpec, filter)
 			default:
-				return false, fmt.Errorf(invalidInstanceSpecTypeMsg)
+				return nil, fmt.Errorf(invalidInstanceSpecTypeMsg)
 			}
 		case *IntRangeFilter:
 			switch iSpec := instanceSpec.(type) {
 			case *int64:
-				if !isSupportedWithRangeInt64(iSpec, filter) {
-					return false, nil
-				}
+				isSupported = comparators.IsSupportedWithRangeInt64(iSpec, toIntRange(filter))
 			case *int:
-				if !isSupportedWithRangeInt(iSpec, filter) {
-					return false, nil
-				}
+				isSupported = comparators.IsSupportedWithRangeInt(iSpec, toIntRange(filter))
 			default:
-				return false, fmt.Errorf(invalidInstanceSpecTypeMsg)
+				return nil, fmt.Errorf(invalidInstanceSpecTypeMsg)
 			}
 		case *float64:
 			switch iSpec := instanceSpec.(type) {
 			case *float64:
-				if !isSupportedWithFloat64(iSpec, filter) {
-					return false, nil
-				}
+				isSupported = comparators.IsSupportedWithFloat64(iSpec, filter)
 			default:
-				return false, fmt.Errorf(invalidInstanceSpecTypeMsg)
+				return nil, fmt.Errorf(invalidInstanceSpecTypeMsg)
 			}
 		default:
-			return false, fmt.Errorf("No filter handler found for %s", filterDetailsMsg)
+			return nil, fmt.Errorf("No filter handler found for %s", filterDetailsMsg)
+		}
+		if !isSupported {
+			itf.debugf("instance type %s eliminated by filter %s", instanceType, filterName)
+			rejections = append(rejections, FilterRejection{
+				Filter:            filterName,
+				FilterValue:       formatSpecValue(filterVal),
+				InstanceTypeValue: formatSpecValue(instanceSpec),
+			})
+		}
+	}
+	return rejections, nil
+}
+
+// RegisterCustomFilter adds a named predicate which is executed alongside the built-in filters on every instance type.
+// Instance types are only returned if every registered custom filter returns true.
+func (itf *Selector) RegisterCustomFilter(name string, fn CustomFilterFn) {
+	itf.customFilters = append(append([]customFilter{}, itf.customFilters...), customFilter{name: name, fn: fn})
+}
+
+// executeCustomFilters executes the registered custom filters against the instance type
+// and returns a FilterRejection for each custom filter the instance type does not match.
+func (itf Selector) executeCustomFilters(instanceTypeInfo *ec2.InstanceTypeInfo) []FilterRejection {
+	rejections := []FilterRejection{}
+	for _, customFilter := range itf.customFilters {
+		if !customFilter.fn(instanceTypeInfo) {
+			itf.debugf("instance type %s eliminated by custom filter %s", *instanceTypeInfo.InstanceType, customFilter.name)
+			rejections = append(rejections, FilterRejection{
+				Filter:            customFilter.name,
+				FilterValue:       "custom",
+				InstanceTypeValue: "rejected",
+			})
 		}
 	}
-	return true, nil
+	return rejections
+}
+
+// formatSpecValue dereferences filter and instance spec values into a human-readable string
+func formatSpecValue(value interface{}) string {
+	reflectValue := reflect.ValueOf(value)
+	switch reflectValue.Kind() {
+	case reflect.Invalid:
+		return "none"
+	case reflect.Ptr:
+		if reflectValue.IsNil() {
+			return "none"
+		}
+		if intRange, ok := value.(*IntRangeFilter); ok {
+			return fmt.Sprintf("%d-%d", intRange.LowerBound, intRange.UpperBound)
+		}
+		return formatSpecValue(reflectValue.Elem().Interface())
+	case reflect.Slice:
+		values := []string{}
+		for i := 0; i < reflectValue.Len(); i++ {
+			values = append(values, formatSpecValue(reflectValue.Index(i).Interface()))
+		}
+		return "[" + strings.Join(values, ", ") + "]"
+	}
+	return fmt.Sprintf("%v", value)
 }
 
 // RetrieveInstanceTypesSupportedInLocation returns a map of instance type -> AZ or Region for all instance types supported in the location passed in
@@ -283,7 +596,87 @@ func (itf Selector) RetrieveInstanceTypesSupportedInLocation(zone string) (map[s
 	if zone == "" {
 		return nil, nil
 	}
+	if itf.DataProvider != nil {
+		itf.debugf("retrieving instance type offerings for %s from the data provider", zone)
+		return itf.DataProvider.InstanceTypeOfferings(zone)
+	}
+	if itf.cache != nil {
+		if availableInstanceTypes, ok := itf.cache.getOfferings(zone); ok {
+			itf.debugf("using %d cached instance type offerings for %s", len(availableInstanceTypes), zone)
+			return availableInstanceTypes, nil
+		}
+	}
 	availableInstanceTypes := map[string]string{}
+	instanceTypeOfferingsInput, err := newInstanceTypeOfferingsInput(zone)
+	if err != nil {
+		return nil, err
+	}
+	itf.debugf("calling DescribeInstanceTypeOfferings for %s %s", *instanceTypeOfferingsInput.LocationType, zone)
+	err = itf.EC2.DescribeInstanceTypeOfferingsPages(instanceTypeOfferingsInput, func(page *ec2.DescribeInstanceTypeOfferingsOutput, lastPage bool) bool {
+		for _, instanceType := range page.InstanceTypeOfferings {
+			availableInstanceTypes[*instanceType.InstanceType] = *instanceType.Location
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Encountered an error when describing instance type offerings: %w", classifyAPIError(err))
+	}
+	itf.debugf("DescribeInstanceTypeOfferings returned %d instance types in %s %s", len(availableInstanceTypes), *instanceTypeOfferingsInput.LocationType, zone)
+	// a zone outside the client's region returns no offerings rather than an error, which looks like nothing matched
+	if len(availableInstanceTypes) == 0 && *instanceTypeOfferingsInput.LocationType != regionNameLocationType {
+		if err := itf.validateZoneInRegion(zone); err != nil {
+			return nil, err
+		}
+	}
+	if itf.cache != nil {
+		itf.cache.setOfferings(zone, availableInstanceTypes)
+	}
+	return availableInstanceTypes, nil
+}
+
+// RetrieveInstanceTypeZones returns the sorted availability zone names each instance type is offered in within the EC2 client's region,
+// so that zonal gaps, like a type missing from one zone, are visible. A nil map means the zones are not known, like with a data provider
+// which does not list zone offerings.
+func (itf Selector) RetrieveInstanceTypeZones() (map[string][]string, error) {
+	if itf.DataProvider != nil {
+		zoneProvider, ok := itf.DataProvider.(zoneOfferingsProvider)
+		if !ok {
+			return nil, nil
+		}
+		itf.debugf("retrieving instance type zones from the data provider")
+		return zoneProvider.InstanceTypeZones()
+	}
+	instanceTypeZones := map[string][]string{}
+	itf.debugf("calling DescribeInstanceTypeOfferings for every %s", zoneNameLocationType)
+	err := itf.EC2.DescribeInstanceTypeOfferingsPages(&ec2.DescribeInstanceTypeOfferingsInput{
+		LocationType: aws.String(zoneNameLocationType),
+	}, func(page *ec2.DescribeInstanceTypeOfferingsOutput, lastPage bool) bool {
+		for _, offering := range page.InstanceTypeOfferings {
+			instanceType := aws.StringValue(offering.InstanceType)
+			instanceTypeZones[instanceType] = append(instanceTypeZones[instanceType], aws.StringValue(offering.Location))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Encountered an error when describing instance type offerings: %w", classifyAPIError(err))
+	}
+	for _, zones := range instanceTypeZones {
+		sort.Strings(zones)
+	}
+	return instanceTypeZones, nil
+}
+
+// validateZoneInRegion returns an ErrInvalidLocation error if the zone name or id is not one of the zones of the EC2 client's region,
+// or if it is a zone which the account has not opted in to, like a Local Zone
+func (itf Selector) validateZoneInRegion(zone string) error {
+	itf.debugf("checking %s is a zone of the region", zone)
+	_, err := itf.ResolveZone(zone)
+	return err
+}
+
+// newInstanceTypeOfferingsInput creates the DescribeInstanceTypeOfferingsInput used to retrieve the instance types offered in the location.
+// The location type is determined by whether the location is a zone-id, a zone-name, or a region name.
+func newInstanceTypeOfferingsInput(zone string) (*ec2.DescribeInstanceTypeOfferingsInput, error) {
 	instanceTypeOfferingsInput := &ec2.DescribeInstanceTypeOfferingsInput{
 		Filters: []*ec2.Filter{
 			{
@@ -292,25 +685,61 @@ func (itf Selector) RetrieveInstanceTypesSupportedInLocation(zone string) (map[s
 			},
 		},
 	}
-	if isZoneID, _ := regexp.MatchString(zoneIDRegex, zone); isZoneID {
-		instanceTypeOfferingsInput.SetLocationType(zoneIDLocationType)
-	} else if isZoneName, _ := regexp.MatchString(zoneNameRegex, zone); isZoneName {
-		instanceTypeOfferingsInput.SetLocationType(zoneNameLocationType)
-	} else if isRegion, _ := regexp.MatchString(regionNameRegex, zone); isRegion {
-		instanceTypeOfferingsInput.SetLocationType(regionNameLocationType)
-	} else {
-		return nil, fmt.Errorf("The location passed in (%s) is not a valid zone-id, zone-name, or region name", zone)
+	locationType, err := getLocationType(zone)
+	if err != nil {
+		return nil, err
 	}
-	err := itf.EC2.DescribeInstanceTypeOfferingsPages(instanceTypeOfferingsInput, func(page *ec2.DescribeInstanceTypeOfferingsOutput, lastPage bool) bool {
-		for _, instanceType := range page.InstanceTypeOfferings {
-			availableInstanceTypes[*instanceType.InstanceType] = *instanceType.Location
+	instanceTypeOfferingsInput.SetLocationType(locationType)
+	return instanceTypeOfferingsInput, nil
+}
+
+// ValidateLocation returns an ErrInvalidLocation error if the location is not a valid zone-id, zone-name, or region name
+func ValidateLocation(location string) error {
+	_, err := getLocationType(location)
+	return err
+}
+
+// IsZone returns true if the location is the name or id of an availability zone, Local Zone, or Wavelength Zone rather than a region
+func IsZone(location string) bool {
+	locationType, err := getLocationType(location)
+	return err == nil && locationType != regionNameLocationType
+}
+
+// IsEdgeZone returns true if the location is the name or id of a Local Zone or Wavelength Zone,
+// which are offered per account opt-in and cannot be derived from the region
+func IsEdgeZone(location string) bool {
+	return matchesAny(location, localZoneIDRegex, localZoneNameRegex, wavelengthZoneIDRegex, wavelengthZoneNameRegex)
+}
+
+// getLocationType returns whether the location is a zone-id, a zone-name, or a region name.
+// Local Zones and Wavelength Zones are classified as zones since their offerings are retrieved the same way.
+func getLocationType(location string) (string, error) {
+	if matchesAny(location, zoneIDRegex, localZoneIDRegex, wavelengthZoneIDRegex) {
+		return zoneIDLocationType, nil
+	} else if matchesAny(location, zoneNameRegex, localZoneNameRegex, wavelengthZoneNameRegex) {
+		return zoneNameLocationType, nil
+	} else if isRegion, _ := regexp.MatchString(regionNameRegex, location); isRegion {
+		return regionNameLocationType, nil
+	}
+	return "", newClassifiedError(ErrInvalidLocation, "The location passed in (%s) is not a valid zone-id, zone-name, or region name", location)
+}
+
+// matchesAny returns true if the value matches any of the regexes
+func matchesAny(value string, regexes ...string) bool {
+	for _, regex := range regexes {
+		if matches, _ := regexp.MatchString(regex, value); matches {
+			return true
 		}
-		return true
-	})
-	if err != nil {
-		return nil, fmt.Errorf("Encountered an error when describing instance type offerings: %w", err)
 	}
-	return availableInstanceTypes, nil
+	return false
+}
+
+// debugf sends a debug log to the Logger if one is configured
+func (itf Selector) debugf(format string, args ...interface{}) {
+	if itf.Logger == nil {
+		return
+	}
+	itf.Logger.Debugf(format, args...)
 }
 
 func isSupportedInLocation(instanceOfferings map[string]string, instanceType string) bool {
@@ -20,11 +20,14 @@ import (
 	"io/ioutil"
 	"log"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
 	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
@@ -33,6 +36,7 @@ import (
 const (
 	describeInstanceTypes         = "DescribeInstanceTypes"
 	describeInstanceTypeOfferings = "DescribeInstanceTypeOfferings"
+	describeInstances             = "DescribeInstances"
 	mockFilesPath                 = "../../test/static"
 )
 
@@ -43,10 +47,23 @@ type ioFn = func(page *ec2.DescribeInstanceTypeOfferingsOutput, lastPage bool) b
 
 type mockedEC2 struct {
 	ec2iface.EC2API
-	DescribeInstanceTypesResp         ec2.DescribeInstanceTypesOutput
-	DescribeInstanceTypesErr          error
-	DescribeInstanceTypeOfferingsResp ec2.DescribeInstanceTypeOfferingsOutput
-	DescribeInstanceTypeOfferingsErr  error
+	DescribeInstanceTypesResp          ec2.DescribeInstanceTypesOutput
+	DescribeInstanceTypesErr           error
+	DescribeInstanceTypeOfferingsResp  ec2.DescribeInstanceTypeOfferingsOutput
+	DescribeInstanceTypeOfferingsErr   error
+	DescribeInstancesResp              ec2.DescribeInstancesOutput
+	DescribeInstancesErr               error
+	DescribeRegionsResp                ec2.DescribeRegionsOutput
+	DescribeRegionsErr                 error
+	DescribeAvailabilityZonesResp      ec2.DescribeAvailabilityZonesOutput
+	DescribeAvailabilityZonesErr       error
+	DescribeSpotPriceHistoryResp       ec2.DescribeSpotPriceHistoryOutput
+	DescribeSpotPriceHistoryErr        error
+	GetSpotPlacementScoresResp         ec2.GetSpotPlacementScoresOutput
+	GetSpotPlacementScoresErr          error
+	DescribeLaunchTemplateVersionsResp ec2.DescribeLaunchTemplateVersionsOutput
+	DescribeLaunchTemplateVersionsErr  error
+	CreateFleetErr                     error
 }
 
 func (m mockedEC2) DescribeInstanceTypesPages(input *ec2.DescribeInstanceTypesInput, fn itFn) error {
@@ -59,6 +76,39 @@ func (m mockedEC2) DescribeInstanceTypeOfferingsPages(input *ec2.DescribeInstanc
 	return m.DescribeInstanceTypeOfferingsErr
 }
 
+func (m mockedEC2) DescribeInstances(input *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
+	return &m.DescribeInstancesResp, m.DescribeInstancesErr
+}
+
+func (m mockedEC2) DescribeSpotPriceHistoryPages(input *ec2.DescribeSpotPriceHistoryInput, fn func(*ec2.DescribeSpotPriceHistoryOutput, bool) bool) error {
+	fn(&m.DescribeSpotPriceHistoryResp, true)
+	return m.DescribeSpotPriceHistoryErr
+}
+
+func (m mockedEC2) GetSpotPlacementScoresPages(input *ec2.GetSpotPlacementScoresInput, fn func(*ec2.GetSpotPlacementScoresOutput, bool) bool) error {
+	fn(&m.GetSpotPlacementScoresResp, true)
+	return m.GetSpotPlacementScoresErr
+}
+
+func (m mockedEC2) DescribeLaunchTemplateVersions(input *ec2.DescribeLaunchTemplateVersionsInput) (*ec2.DescribeLaunchTemplateVersionsOutput, error) {
+	return &m.DescribeLaunchTemplateVersionsResp, m.DescribeLaunchTemplateVersionsErr
+}
+
+func (m mockedEC2) CreateFleet(input *ec2.CreateFleetInput) (*ec2.CreateFleetOutput, error) {
+	if !aws.BoolValue(input.DryRun) {
+		return nil, errors.New("CreateFleet should only be called with DryRun")
+	}
+	return &ec2.CreateFleetOutput{}, m.CreateFleetErr
+}
+
+func (m mockedEC2) DescribeAvailabilityZones(input *ec2.DescribeAvailabilityZonesInput) (*ec2.DescribeAvailabilityZonesOutput, error) {
+	return &m.DescribeAvailabilityZonesResp, m.DescribeAvailabilityZonesErr
+}
+
+func (m mockedEC2) DescribeRegions(input *ec2.DescribeRegionsInput) (*ec2.DescribeRegionsOutput, error) {
+	return &m.DescribeRegionsResp, m.DescribeRegionsErr
+}
+
 // Tests
 
 func TestNew(t *testing.T) {
@@ -85,6 +135,13 @@ func setupMock(t *testing.T, api string, file string) mockedEC2 {
 		return mockedEC2{
 			DescribeInstanceTypeOfferingsResp: ditoo,
 		}
+	case describeInstances:
+		dio := ec2.DescribeInstancesOutput{}
+		err = json.Unmarshal(mockFile, &dio)
+		h.Assert(t, err == nil, "Error parsing mock json file contents"+mockFilename)
+		return mockedEC2{
+			DescribeInstancesResp: dio,
+		}
 	default:
 		h.Assert(t, false, "Unable to mock the provided API type "+api)
 	}
@@ -217,8 +274,8 @@ func TestFilter_MoreFilters(t *testing.T) {
 	filters := selector.Filters{
 		VCpusRange:      &selector.IntRangeFilter{LowerBound: 2, UpperBound: 2},
 		BareMetal:       aws.Bool(false),
-		CPUArchitecture: aws.String("x86_64"),
-		Hypervisor:      aws.String("nitro"),
+		CPUArchitecture: []string{"x86_64"},
+		Hypervisor:      []string{"nitro"},
 		EnaSupport:      aws.Bool(true),
 	}
 	results, err := itf.Filter(filters)
@@ -228,6 +285,25 @@ func TestFilter_MoreFilters(t *testing.T) {
 	h.Assert(t, results[0] == "t3.micro", "Should return t3.micro, got %s instead", results[0])
 }
 
+func TestFilter_MultipleStringValues(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypes, "25_instances.json")
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	filters := selector.Filters{
+		VCpusRange:      &selector.IntRangeFilter{LowerBound: 2, UpperBound: 2},
+		CPUArchitecture: []string{"x86_64", "arm64"},
+	}
+	results, err := itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"a1.large", "c1.medium", "c3.large", "c4.large", "c5.large"}, results)
+
+	filters.CPUArchitecture = []string{"arm64"}
+	results, err = itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"a1.large"}, results)
+}
+
 func TestFilter_TruncateToMaxResults(t *testing.T) {
 	ec2Mock := setupMock(t, describeInstanceTypes, "25_instances.json")
 	itf := selector.Selector{
@@ -257,6 +333,129 @@ func TestFilter_TruncateToMaxResults(t *testing.T) {
 	h.Assert(t, len(results) == 25, "Should return 25 instance types since max results is set to 30 but only 25 are returned in total")
 }
 
+func TestFilter_TruncateToMaxResultsPerFamily(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypes, "25_instances.json")
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	filters := selector.Filters{
+		VCpusRange:          &selector.IntRangeFilter{LowerBound: 0, UpperBound: 100},
+		MaxResultsPerFamily: aws.Int(1),
+	}
+	results, err := itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, 5, len(results))
+	families := map[string]bool{}
+	for _, instanceType := range results {
+		families[strings.Split(instanceType, ".")[0]] = true
+	}
+	h.Equals(t, 5, len(families))
+
+	filters.MaxResultsPerFamily = aws.Int(2)
+	filters.MaxResults = aws.Int(3)
+	results, err = itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, 3, len(results))
+}
+
+func TestFilter_InstanceTypes(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypes, "25_instances.json")
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	filters := selector.Filters{
+		VCpusRange:    &selector.IntRangeFilter{LowerBound: 2, UpperBound: 2},
+		InstanceTypes: []string{"c4.large", "c4.xlarge", "c5.large", "m5.large"},
+	}
+	results, err := itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"c4.large", "c5.large"}, results)
+}
+
+func TestFilter_Logger(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypes, "t3_micro_and_p3_16xl.json")
+	logs := []string{}
+	itf := selector.Selector{
+		EC2: ec2Mock,
+		Logger: selector.LoggerFn(func(format string, args ...interface{}) {
+			logs = append(logs, fmt.Sprintf(format, args...))
+		}),
+	}
+	filters := selector.Filters{
+		VCpusRange: &selector.IntRangeFilter{LowerBound: 2, UpperBound: 2},
+	}
+	results, err := itf.Filter(filters)
+	h.Ok(t, err)
+	h.Assert(t, len(results) == 1, "Should only return 1 instance type with 2 vcpus")
+	h.Assert(t, len(logs) > 0, "Logger should receive debug logs")
+	eliminationLogged := false
+	for _, logLine := range logs {
+		if strings.Contains(logLine, "p3.16xlarge eliminated by filter vcpusRange") {
+			eliminationLogged = true
+		}
+	}
+	h.Assert(t, eliminationLogged, "Logger should receive the filter which eliminated p3.16xlarge")
+}
+
+func TestExplain(t *testing.T) {
+	ec2Mock := mockedEC2{
+		DescribeInstanceTypesResp:         setupMock(t, describeInstanceTypes, "t3_micro_and_p3_16xl.json").DescribeInstanceTypesResp,
+		DescribeInstanceTypeOfferingsResp: setupMock(t, describeInstanceTypeOfferings, "us-east-2a_only_c5d12x.json").DescribeInstanceTypeOfferingsResp,
+	}
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	filters := selector.Filters{
+		VCpusRange:       &selector.IntRangeFilter{LowerBound: 2, UpperBound: 2},
+		AvailabilityZone: aws.String("us-east-2a"),
+	}
+	explanation, err := itf.Explain(filters)
+	h.Ok(t, err)
+	h.Assert(t, len(explanation.InstanceTypes) == 0, "Should return 0 matching instance types")
+	h.Assert(t, len(explanation.Rejections) == 2, "Should explain rejections for both instance types")
+	h.Equals(t, []selector.FilterRejection{
+		{Filter: "location", FilterValue: "us-east-2a", InstanceTypeValue: "not offered"},
+	}, explanation.Rejections["t3.micro"])
+	h.Equals(t, []selector.FilterRejection{
+		{Filter: "location", FilterValue: "us-east-2a", InstanceTypeValue: "not offered"},
+		{Filter: "vcpusRange", FilterValue: "2-2", InstanceTypeValue: "64"},
+	}, explanation.Rejections["p3.16xlarge"])
+}
+
+func TestExplain_Failure(t *testing.T) {
+	ec2Mock := mockedEC2{
+		DescribeInstanceTypesErr: errors.New("error"),
+	}
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	explanation, err := itf.Explain(selector.Filters{})
+	h.Assert(t, explanation == nil, "Explanation should be nil")
+	h.Nok(t, err)
+}
+
+func TestFilter_CustomFilter(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypes, "25_instances.json")
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	itf.RegisterCustomFilter("c5Only", func(instanceTypeInfo *ec2.InstanceTypeInfo) bool {
+		return strings.HasPrefix(*instanceTypeInfo.InstanceType, "c5.")
+	})
+	filters := selector.Filters{
+		VCpusRange: &selector.IntRangeFilter{LowerBound: 2, UpperBound: 2},
+	}
+	results, err := itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"c5.large"}, results)
+
+	explanation, err := itf.Explain(filters)
+	h.Ok(t, err)
+	h.Equals(t, []selector.FilterRejection{
+		{Filter: "c5Only", FilterValue: "custom", InstanceTypeValue: "rejected"},
+	}, explanation.Rejections["c4.large"])
+}
+
 func TestFilter_Failure(t *testing.T) {
 	ec2Mock := mockedEC2{
 		DescribeInstanceTypesErr: errors.New("error"),
@@ -303,6 +502,49 @@ func TestRetrieveInstanceTypesSupportedInAZ_WithRegion(t *testing.T) {
 	h.Assert(t, len(results) == 228, "Should return 228 entries in us-east-2 golden file w/ no resource filter applied")
 }
 
+func TestRetrieveInstanceTypesSupportedInLocation_ZoneNotInRegion(t *testing.T) {
+	itf := selector.Selector{
+		EC2: mockedEC2{
+			DescribeAvailabilityZonesResp: ec2.DescribeAvailabilityZonesOutput{
+				AvailabilityZones: []*ec2.AvailabilityZone{
+					{ZoneName: aws.String("us-east-2a"), ZoneId: aws.String("use2-az1"), RegionName: aws.String("us-east-2"), OptInStatus: aws.String("opt-in-not-required")},
+				},
+			},
+		},
+	}
+	_, err := itf.RetrieveInstanceTypesSupportedInLocation("us-west-2a")
+	h.Assert(t, errors.Is(err, selector.ErrInvalidLocation), "Should return ErrInvalidLocation")
+	h.Assert(t, strings.Contains(err.Error(), "us-east-2"), "The error should name the region of the client")
+
+	// a zone of the region with no offerings is not an error
+	results, err := itf.RetrieveInstanceTypesSupportedInLocation("use2-az1")
+	h.Ok(t, err)
+	h.Equals(t, 0, len(results))
+}
+
+func TestRetrieveInstanceTypesSupportedInLocation_ZoneNotOptedIn(t *testing.T) {
+	itf := selector.Selector{
+		EC2: mockedEC2{
+			DescribeAvailabilityZonesResp: ec2.DescribeAvailabilityZonesOutput{
+				AvailabilityZones: []*ec2.AvailabilityZone{
+					{ZoneName: aws.String("us-west-2-lax-1a"), GroupName: aws.String("us-west-2-lax-1"), RegionName: aws.String("us-west-2"), OptInStatus: aws.String("not-opted-in")},
+				},
+			},
+		},
+	}
+	_, err := itf.RetrieveInstanceTypesSupportedInLocation("us-west-2-lax-1a")
+	h.Assert(t, errors.Is(err, selector.ErrInvalidLocation), "Should return ErrInvalidLocation")
+	h.Assert(t, strings.Contains(err.Error(), "opted in"), "The error should explain the zone is not opted in")
+}
+
+func TestRetrieveInstanceTypesSupportedInLocation_DescribeAvailabilityZonesFailure(t *testing.T) {
+	itf := selector.Selector{
+		EC2: mockedEC2{DescribeAvailabilityZonesErr: errors.New("error")},
+	}
+	_, err := itf.RetrieveInstanceTypesSupportedInLocation("us-west-2a")
+	h.Nok(t, err)
+}
+
 func TestRetrieveInstanceTypesSupportedInAZ_WithBadZone(t *testing.T) {
 	ec2Mock := setupMock(t, describeInstanceTypeOfferings, "us-east-2a.json")
 	itf := selector.Selector{
@@ -325,3 +567,229 @@ func TestRetrieveInstanceTypesSupportedInAZ_Error(t *testing.T) {
 	h.Assert(t, err != nil, "Should return an error since ec2 api mock is configured to return an error")
 	h.Assert(t, results == nil, "Should return nil results due to error")
 }
+
+func TestRetrieveInstanceTypeZones(t *testing.T) {
+	itf := selector.Selector{
+		EC2: mockedEC2{
+			DescribeInstanceTypeOfferingsResp: ec2.DescribeInstanceTypeOfferingsOutput{
+				InstanceTypeOfferings: []*ec2.InstanceTypeOffering{
+					{InstanceType: aws.String("c5.large"), Location: aws.String("us-east-1b"), LocationType: aws.String("availability-zone")},
+					{InstanceType: aws.String("c5.large"), Location: aws.String("us-east-1a"), LocationType: aws.String("availability-zone")},
+					{InstanceType: aws.String("m5.large"), Location: aws.String("us-east-1e"), LocationType: aws.String("availability-zone")},
+				},
+			},
+		},
+	}
+	results, err := itf.RetrieveInstanceTypeZones()
+	h.Ok(t, err)
+	h.Equals(t, map[string][]string{"c5.large": {"us-east-1a", "us-east-1b"}, "m5.large": {"us-east-1e"}}, results)
+}
+
+func TestRetrieveInstanceTypeZones_Error(t *testing.T) {
+	itf := selector.Selector{
+		EC2: mockedEC2{DescribeInstanceTypeOfferingsErr: errors.New("error")},
+	}
+	results, err := itf.RetrieveInstanceTypeZones()
+	h.Nok(t, err)
+	h.Assert(t, results == nil, "Should return nil results due to error")
+}
+
+func TestInstanceSelector(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypes, "t3_micro.json")
+	var itf selector.InstanceSelector = selector.NewWithOptions(nil, selector.WithEC2Client(ec2Mock))
+	results, err := itf.Filter(selector.Filters{})
+	h.Ok(t, err)
+	h.Equals(t, []string{"t3.micro"}, results)
+}
+
+func TestCount(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypes, "25_instances.json")
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	count, err := itf.Count(selector.Filters{
+		MaxResults: aws.Int(1),
+	})
+	h.Ok(t, err)
+	h.Equals(t, 25, count)
+}
+
+func TestCount_Failure(t *testing.T) {
+	itf := selector.Selector{
+		EC2: mockedEC2{
+			DescribeInstanceTypesErr: errors.New("error"),
+		},
+	}
+	count, err := itf.Count(selector.Filters{})
+	h.Nok(t, err)
+	h.Equals(t, 0, count)
+}
+
+func TestFilter_ProgressHooks(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypes, "25_instances.json")
+	pages := 0
+	evaluated := 0
+	lastMatched := 0
+	itf := selector.NewWithOptions(nil, selector.WithEC2Client(ec2Mock), selector.WithProgressHooks(selector.ProgressHooks{
+		PageFetched: func(page int, instanceTypes int) {
+			pages = page
+			h.Equals(t, 25, instanceTypes)
+		},
+		InstanceTypeEvaluated: func(evaluatedCount int, matchedCount int, total int) {
+			evaluated++
+			h.Equals(t, evaluated, evaluatedCount)
+			h.Equals(t, 25, total)
+			h.Assert(t, matchedCount >= lastMatched, "Matched count should never decrease")
+			lastMatched = matchedCount
+		},
+	}))
+	results, err := itf.Filter(selector.Filters{
+		VCpusRange: &selector.IntRangeFilter{LowerBound: 16, UpperBound: 16},
+	})
+	h.Ok(t, err)
+	h.Equals(t, 1, pages)
+	h.Equals(t, 25, evaluated)
+	h.Equals(t, len(results), lastMatched)
+}
+
+func TestFilter_ErrThrottled(t *testing.T) {
+	itf := selector.Selector{
+		EC2: mockedEC2{
+			DescribeInstanceTypesErr: awserr.New("RequestLimitExceeded", "Request limit exceeded.", nil),
+		},
+	}
+	_, err := itf.Filter(selector.Filters{})
+	h.Assert(t, errors.Is(err, selector.ErrThrottled), "Should return ErrThrottled")
+}
+
+func TestFilter_ErrInvalidLocation(t *testing.T) {
+	itf := selector.Selector{
+		EC2: mockedEC2{},
+	}
+	_, err := itf.Filter(selector.Filters{
+		AvailabilityZone: aws.String("invalid"),
+	})
+	h.Assert(t, errors.Is(err, selector.ErrInvalidLocation), "Should return ErrInvalidLocation")
+}
+
+func TestValidateLocation(t *testing.T) {
+	for _, location := range []string{"us-east-1", "us-east-1a", "use1-az1", "us-west-2-lax-1a", "usw2-lax1-az1", "us-east-1-wl1-bos-wlz-1", "use1-wl1-bos-wlz1"} {
+		h.Ok(t, selector.ValidateLocation(location))
+	}
+	// GovCloud, China, and ISO partitions
+	for _, location := range []string{"us-gov-west-1", "us-gov-east-1a", "usgw1-az1", "cn-north-1", "cn-northwest-1b", "cnnw1-az2", "us-iso-east-1", "us-isob-east-1a", "eu-isoe-west-1", "apne1-az4", "use1-az10"} {
+		h.Ok(t, selector.ValidateLocation(location))
+	}
+	for _, location := range []string{"invalid", "us-east-1-lax", "us-east-1-wl1-bos-wlz"} {
+		err := selector.ValidateLocation(location)
+		h.Assert(t, errors.Is(err, selector.ErrInvalidLocation), "Should return ErrInvalidLocation for "+location)
+	}
+}
+
+func TestIsEdgeZone(t *testing.T) {
+	for _, location := range []string{"us-west-2-lax-1a", "usw2-lax1-az1", "us-east-1-wl1-bos-wlz-1", "use1-wl1-bos-wlz1"} {
+		h.Assert(t, selector.IsEdgeZone(location), location+" should be an edge zone")
+	}
+	for _, location := range []string{"us-west-2", "us-west-2a", "usw2-az1"} {
+		h.Assert(t, !selector.IsEdgeZone(location), location+" should not be an edge zone")
+	}
+}
+
+func TestIsZone(t *testing.T) {
+	for _, location := range []string{"us-west-2a", "usw2-az1", "us-west-2-lax-1a", "use1-wl1-bos-wlz1"} {
+		h.Assert(t, selector.IsZone(location), location+" should be a zone")
+	}
+	for _, location := range []string{"us-west-2", "us-gov-west-1", "blah"} {
+		h.Assert(t, !selector.IsZone(location), location+" should not be a zone")
+	}
+}
+
+// describeInstanceTypeOfferingsRecorder records the DescribeInstanceTypeOfferings input passed to the mock
+type describeInstanceTypeOfferingsRecorder struct {
+	mockedEC2
+	input **ec2.DescribeInstanceTypeOfferingsInput
+}
+
+func (m describeInstanceTypeOfferingsRecorder) DescribeInstanceTypeOfferingsPages(input *ec2.DescribeInstanceTypeOfferingsInput, fn ioFn) error {
+	*m.input = input
+	return m.mockedEC2.DescribeInstanceTypeOfferingsPages(input, fn)
+}
+
+func TestRetrieveInstanceTypesSupportedInLocation_LocationTypes(t *testing.T) {
+	for location, locationType := range map[string]string{
+		"us-west-2-lax-1a":        "availability-zone",
+		"usw2-lax1-az1":           "availability-zone-id",
+		"us-east-1-wl1-bos-wlz-1": "availability-zone",
+		"use1-wl1-bos-wlz1":       "availability-zone-id",
+		"us-gov-west-1a":          "availability-zone",
+		"usgw1-az1":               "availability-zone-id",
+		"cn-northwest-1":          "region",
+		"us-isob-east-1":          "region",
+	} {
+		var input *ec2.DescribeInstanceTypeOfferingsInput
+		itf := selector.Selector{
+			EC2: describeInstanceTypeOfferingsRecorder{mockedEC2: setupMock(t, describeInstanceTypeOfferings, "us-east-2a.json"), input: &input},
+		}
+		_, err := itf.RetrieveInstanceTypesSupportedInLocation(location)
+		h.Ok(t, err)
+		h.Equals(t, locationType, *input.LocationType)
+		h.Equals(t, location, *input.Filters[0].Values[0])
+	}
+}
+
+// describeInstanceTypesRecorder records the DescribeInstanceTypes input passed to the mock
+type describeInstanceTypesRecorder struct {
+	mockedEC2
+	input **ec2.DescribeInstanceTypesInput
+}
+
+func (m describeInstanceTypesRecorder) DescribeInstanceTypesPages(input *ec2.DescribeInstanceTypesInput, fn itFn) error {
+	*m.input = input
+	return m.mockedEC2.DescribeInstanceTypesPages(input, fn)
+}
+
+func TestFilter_RawEC2Filters(t *testing.T) {
+	var input *ec2.DescribeInstanceTypesInput
+	rawEC2Filters := []*ec2.Filter{
+		{
+			Name:   aws.String("processor-info.sustained-clock-speed-in-ghz"),
+			Values: []*string{aws.String("2.5")},
+		},
+	}
+	itf := selector.NewWithOptions(nil, selector.WithCache(time.Hour), selector.WithEC2Client(describeInstanceTypesRecorder{
+		mockedEC2: setupMock(t, describeInstanceTypes, "t3_micro.json"),
+		input:     &input,
+	}))
+	results, err := itf.Filter(selector.Filters{
+		RawEC2Filters: rawEC2Filters,
+	})
+	h.Ok(t, err)
+	h.Equals(t, []string{"t3.micro"}, results)
+	h.Equals(t, rawEC2Filters, input.Filters)
+
+	// raw EC2 filters should never be served from the cache
+	input = nil
+	_, err = itf.Filter(selector.Filters{
+		RawEC2Filters: rawEC2Filters,
+	})
+	h.Ok(t, err)
+	h.Assert(t, input != nil, "DescribeInstanceTypes should be called when raw EC2 filters are used")
+}
+
+func TestFilter_GpuMemoryGiBRange(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypes, "t3_micro_and_p3_16xl.json")
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	results, err := itf.Filter(selector.Filters{
+		GpuMemoryGiBRange: &selector.IntRangeFilter{LowerBound: 128, UpperBound: 128},
+	})
+	h.Ok(t, err)
+	h.Equals(t, []string{"p3.16xlarge"}, results)
+
+	_, err = itf.Filter(selector.Filters{
+		GpuMemoryRange:    &selector.IntRangeFilter{LowerBound: 131072, UpperBound: 131072},
+		GpuMemoryGiBRange: &selector.IntRangeFilter{LowerBound: 128, UpperBound: 128},
+	})
+	h.Assert(t, errors.Is(err, selector.ErrInvalidFilters), "Should not allow both gpu memory filters")
+}
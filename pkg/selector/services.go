@@ -0,0 +1,276 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector/comparators"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// Service names which can be set in Filters.Service
+const (
+	// ServiceEC2 returns every matching instance type with its EC2 name, which is the default
+	ServiceEC2 = "ec2"
+	// ServiceSageMaker returns matching instance types available for SageMaker training or inference as ml.* instance types
+	ServiceSageMaker = "sagemaker"
+	// ServiceSageMakerTraining returns matching instance types available for SageMaker training jobs as ml.* instance types
+	ServiceSageMakerTraining = "sagemaker-training"
+	// ServiceSageMakerInference returns matching instance types available for SageMaker inference endpoints as ml.* instance types
+	ServiceSageMakerInference = "sagemaker-inference"
+	// ServiceECS returns matching instance types which can run the ECS-optimized AMIs
+	ServiceECS = "ecs"
+	// ServiceRDS returns matching instance types with an RDS equivalent as db.* instance classes
+	ServiceRDS = "rds"
+	// ServiceElastiCache returns matching instance types with an ElastiCache equivalent as cache.* node types
+	ServiceElastiCache = "elasticache"
+)
+
+const (
+	sageMakerInstanceTypePrefix = "ml."
+	rdsInstanceClassPrefix      = "db."
+	elastiCacheNodeTypePrefix   = "cache."
+	// renameProbeInstanceType is named by a service to find out whether it renames instance types
+	renameProbeInstanceType = "m5.large"
+)
+
+// service describes which EC2 instance types another AWS service can run on and what the service calls them
+type service struct {
+	supports         func(instanceTypeInfo *ec2.InstanceTypeInfo) bool
+	instanceTypeName func(instanceType string) string
+}
+
+// sageMakerTrainingInstanceTypes are the EC2 instance types with an ml.* equivalent for SageMaker training jobs
+var sageMakerTrainingInstanceTypes = newInstanceTypeSet(
+	"m4.xlarge", "m4.2xlarge", "m4.4xlarge", "m4.10xlarge", "m4.16xlarge",
+	"m5.large", "m5.xlarge", "m5.2xlarge", "m5.4xlarge", "m5.12xlarge", "m5.24xlarge",
+	"c4.xlarge", "c4.2xlarge", "c4.4xlarge", "c4.8xlarge",
+	"c5.xlarge", "c5.2xlarge", "c5.4xlarge", "c5.9xlarge", "c5.18xlarge",
+	"p2.xlarge", "p2.8xlarge", "p2.16xlarge",
+	"p3.2xlarge", "p3.8xlarge", "p3.16xlarge", "p3dn.24xlarge",
+	"g4dn.xlarge", "g4dn.2xlarge", "g4dn.4xlarge", "g4dn.8xlarge", "g4dn.12xlarge", "g4dn.16xlarge",
+)
+
+// sageMakerInferenceInstanceTypes are the EC2 instance types with an ml.* equivalent for SageMaker inference endpoints
+var sageMakerInferenceInstanceTypes = newInstanceTypeSet(
+	"t2.medium", "t2.large", "t2.xlarge", "t2.2xlarge",
+	"m4.xlarge", "m4.2xlarge", "m4.4xlarge", "m4.10xlarge", "m4.16xlarge",
+	"m5.large", "m5.xlarge", "m5.2xlarge", "m5.4xlarge", "m5.12xlarge", "m5.24xlarge",
+	"m5d.large", "m5d.xlarge", "m5d.2xlarge", "m5d.4xlarge", "m5d.12xlarge", "m5d.24xlarge",
+	"c4.large", "c4.xlarge", "c4.2xlarge", "c4.4xlarge", "c4.8xlarge",
+	"c5.large", "c5.xlarge", "c5.2xlarge", "c5.4xlarge", "c5.9xlarge", "c5.18xlarge",
+	"c5d.large", "c5d.xlarge", "c5d.2xlarge", "c5d.4xlarge", "c5d.9xlarge", "c5d.18xlarge",
+	"r5.large", "r5.xlarge", "r5.2xlarge", "r5.4xlarge", "r5.12xlarge", "r5.24xlarge",
+	"r5d.large", "r5d.xlarge", "r5d.2xlarge", "r5d.4xlarge", "r5d.12xlarge", "r5d.24xlarge",
+	"p2.xlarge", "p2.8xlarge", "p2.16xlarge",
+	"p3.2xlarge", "p3.8xlarge", "p3.16xlarge",
+	"g4dn.xlarge", "g4dn.2xlarge", "g4dn.4xlarge", "g4dn.8xlarge", "g4dn.12xlarge", "g4dn.16xlarge",
+	"inf1.xlarge", "inf1.2xlarge", "inf1.6xlarge", "inf1.24xlarge",
+)
+
+// rdsInstanceClasses are the EC2 instance types with an RDS db.* instance class equivalent.
+// Not every instance class is available for every database engine.
+var rdsInstanceClasses = newInstanceTypeSet(
+	"t2.micro", "t2.small", "t2.medium", "t2.large", "t2.xlarge", "t2.2xlarge",
+	"t3.micro", "t3.small", "t3.medium", "t3.large", "t3.xlarge", "t3.2xlarge",
+	"t4g.micro", "t4g.small", "t4g.medium", "t4g.large", "t4g.xlarge", "t4g.2xlarge",
+	"m4.large", "m4.xlarge", "m4.2xlarge", "m4.4xlarge", "m4.10xlarge", "m4.16xlarge",
+	"m5.large", "m5.xlarge", "m5.2xlarge", "m5.4xlarge", "m5.8xlarge", "m5.12xlarge", "m5.16xlarge", "m5.24xlarge",
+	"m5d.large", "m5d.xlarge", "m5d.2xlarge", "m5d.4xlarge", "m5d.8xlarge", "m5d.12xlarge", "m5d.16xlarge", "m5d.24xlarge",
+	"m6g.large", "m6g.xlarge", "m6g.2xlarge", "m6g.4xlarge", "m6g.8xlarge", "m6g.12xlarge", "m6g.16xlarge",
+	"m6gd.large", "m6gd.xlarge", "m6gd.2xlarge", "m6gd.4xlarge", "m6gd.8xlarge", "m6gd.12xlarge", "m6gd.16xlarge",
+	"m6i.large", "m6i.xlarge", "m6i.2xlarge", "m6i.4xlarge", "m6i.8xlarge", "m6i.12xlarge", "m6i.16xlarge", "m6i.24xlarge", "m6i.32xlarge",
+	"r4.large", "r4.xlarge", "r4.2xlarge", "r4.4xlarge", "r4.8xlarge", "r4.16xlarge",
+	"r5.large", "r5.xlarge", "r5.2xlarge", "r5.4xlarge", "r5.8xlarge", "r5.12xlarge", "r5.16xlarge", "r5.24xlarge",
+	"r5b.large", "r5b.xlarge", "r5b.2xlarge", "r5b.4xlarge", "r5b.8xlarge", "r5b.12xlarge", "r5b.16xlarge", "r5b.24xlarge",
+	"r5d.large", "r5d.xlarge", "r5d.2xlarge", "r5d.4xlarge", "r5d.8xlarge", "r5d.12xlarge", "r5d.16xlarge", "r5d.24xlarge",
+	"r6g.large", "r6g.xlarge", "r6g.2xlarge", "r6g.4xlarge", "r6g.8xlarge", "r6g.12xlarge", "r6g.16xlarge",
+	"r6gd.large", "r6gd.xlarge", "r6gd.2xlarge", "r6gd.4xlarge", "r6gd.8xlarge", "r6gd.12xlarge", "r6gd.16xlarge",
+	"r6i.large", "r6i.xlarge", "r6i.2xlarge", "r6i.4xlarge", "r6i.8xlarge", "r6i.12xlarge", "r6i.16xlarge", "r6i.24xlarge", "r6i.32xlarge",
+	"x1.16xlarge", "x1.32xlarge",
+	"x1e.xlarge", "x1e.2xlarge", "x1e.4xlarge", "x1e.8xlarge", "x1e.16xlarge", "x1e.32xlarge",
+	"x2g.large", "x2g.xlarge", "x2g.2xlarge", "x2g.4xlarge", "x2g.8xlarge", "x2g.12xlarge", "x2g.16xlarge",
+	"z1d.large", "z1d.xlarge", "z1d.2xlarge", "z1d.3xlarge", "z1d.6xlarge", "z1d.12xlarge",
+)
+
+// elastiCacheNodeTypes are the EC2 instance types with an ElastiCache cache.* node type equivalent
+var elastiCacheNodeTypes = newInstanceTypeSet(
+	"t2.micro", "t2.small", "t2.medium",
+	"t3.micro", "t3.small", "t3.medium",
+	"t4g.micro", "t4g.small", "t4g.medium",
+	"m4.large", "m4.xlarge", "m4.2xlarge", "m4.4xlarge", "m4.10xlarge",
+	"m5.large", "m5.xlarge", "m5.2xlarge", "m5.4xlarge", "m5.12xlarge", "m5.24xlarge",
+	"m6g.large", "m6g.xlarge", "m6g.2xlarge", "m6g.4xlarge", "m6g.8xlarge", "m6g.12xlarge", "m6g.16xlarge",
+	"r4.large", "r4.xlarge", "r4.2xlarge", "r4.4xlarge", "r4.8xlarge", "r4.16xlarge",
+	"r5.large", "r5.xlarge", "r5.2xlarge", "r5.4xlarge", "r5.12xlarge", "r5.24xlarge",
+	"r6g.large", "r6g.xlarge", "r6g.2xlarge", "r6g.4xlarge", "r6g.8xlarge", "r6g.12xlarge", "r6g.16xlarge",
+	"r6gd.xlarge", "r6gd.2xlarge", "r6gd.4xlarge", "r6gd.8xlarge", "r6gd.12xlarge", "r6gd.16xlarge",
+)
+
+// ecsUnsupportedInstanceFamilies are the instance families which no ECS-optimized AMI is built for
+var ecsUnsupportedInstanceFamilies = newInstanceTypeSet("mac1")
+
+// ecsENITrunkingUnsupportedInstanceFamilies are the Nitro instance families which do not support ECS ENI trunking
+var ecsENITrunkingUnsupportedInstanceFamilies = newInstanceTypeSet(
+	"c5n", "d3", "d3en", "g3", "g3s", "g4dn", "i3", "i3en", "inf1", "mac1",
+	"m5dn", "m5n", "m5zn", "r5b", "r5dn", "r5n", "u-6tb1", "u-9tb1", "u-12tb1", "z1d",
+)
+
+// services maps a service name to the instance types it supports and how it names them
+var services = map[string]service{
+	ServiceEC2: {
+		supports:         func(*ec2.InstanceTypeInfo) bool { return true },
+		instanceTypeName: func(instanceType string) string { return instanceType },
+	},
+	ServiceSageMaker: {
+		supports: func(instanceTypeInfo *ec2.InstanceTypeInfo) bool {
+			instanceType := aws.StringValue(instanceTypeInfo.InstanceType)
+			return sageMakerTrainingInstanceTypes[instanceType] || sageMakerInferenceInstanceTypes[instanceType]
+		},
+		instanceTypeName: sageMakerInstanceTypeName,
+	},
+	ServiceSageMakerTraining: {
+		supports: func(instanceTypeInfo *ec2.InstanceTypeInfo) bool {
+			return sageMakerTrainingInstanceTypes[aws.StringValue(instanceTypeInfo.InstanceType)]
+		},
+		instanceTypeName: sageMakerInstanceTypeName,
+	},
+	ServiceSageMakerInference: {
+		supports: func(instanceTypeInfo *ec2.InstanceTypeInfo) bool {
+			return sageMakerInferenceInstanceTypes[aws.StringValue(instanceTypeInfo.InstanceType)]
+		},
+		instanceTypeName: sageMakerInstanceTypeName,
+	},
+	ServiceECS: {
+		supports:         supportsECSOptimizedAMI,
+		instanceTypeName: func(instanceType string) string { return instanceType },
+	},
+	ServiceRDS: {
+		supports: func(instanceTypeInfo *ec2.InstanceTypeInfo) bool {
+			return rdsInstanceClasses[aws.StringValue(instanceTypeInfo.InstanceType)]
+		},
+		instanceTypeName: func(instanceType string) string { return rdsInstanceClassPrefix + instanceType },
+	},
+	ServiceElastiCache: {
+		supports: func(instanceTypeInfo *ec2.InstanceTypeInfo) bool {
+			return elastiCacheNodeTypes[aws.StringValue(instanceTypeInfo.InstanceType)]
+		},
+		instanceTypeName: func(instanceType string) string { return elastiCacheNodeTypePrefix + instanceType },
+	},
+}
+
+// ServiceNames returns the sorted names of all services which can be set in Filters.Service
+func ServiceNames() []string {
+	names := []string{}
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RenamesInstanceTypes returns true if the service names instance types differently than EC2, like ml.m5.large for SageMaker,
+// so that its results cannot be passed to EC2 APIs. Unknown services return false.
+func RenamesInstanceTypes(serviceName string) bool {
+	svc, ok := services[serviceName]
+	return ok && svc.instanceTypeName(renameProbeInstanceType) != renameProbeInstanceType
+}
+
+// lookupService returns the service with the name, defaulting to EC2 when no service is set
+func lookupService(name *string) (service, error) {
+	if name == nil {
+		return services[ServiceEC2], nil
+	}
+	svc, ok := services[*name]
+	if !ok {
+		return service{}, newClassifiedError(ErrInvalidFilters, "Invalid filter %s: %s is not a supported service. Valid services are: %s", serviceFilter, *name, strings.Join(ServiceNames(), ", "))
+	}
+	return svc, nil
+}
+
+// isSupportedByService returns true if the service supports the EC2 instance type or no service is set.
+// Unknown services are rejected when the filters are validated.
+func isSupportedByService(serviceName *string, instanceTypeInfo *ec2.InstanceTypeInfo) bool {
+	svc, err := lookupService(serviceName)
+	if err != nil {
+		return false
+	}
+	return svc.supports(instanceTypeInfo)
+}
+
+// serviceInstanceTypeName returns the name the service uses for the EC2 instance type, like ml.m5.large for SageMaker
+func serviceInstanceTypeName(serviceName *string, instanceType string) string {
+	svc, err := lookupService(serviceName)
+	if err != nil {
+		return instanceType
+	}
+	return svc.instanceTypeName(instanceType)
+}
+
+// renameForService returns copies of the instance type specs named the way the service names them.
+// The specs are copied so that cached specs keep their EC2 names.
+func renameForService(serviceName *string, instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []*ec2.InstanceTypeInfo {
+	if serviceName == nil || *serviceName == ServiceEC2 {
+		return instanceTypeInfoSlice
+	}
+	renamed := []*ec2.InstanceTypeInfo{}
+	for _, instanceTypeInfo := range instanceTypeInfoSlice {
+		instanceTypeInfoCopy := *instanceTypeInfo
+		instanceTypeInfoCopy.InstanceType = aws.String(serviceInstanceTypeName(serviceName, *instanceTypeInfo.InstanceType))
+		renamed = append(renamed, &instanceTypeInfoCopy)
+	}
+	return renamed
+}
+
+// supportsECSOptimizedAMI returns true if one of the ECS-optimized AMIs can run on the instance type.
+// The AMIs are EBS backed and only built for the x86_64 and arm64 architectures.
+func supportsECSOptimizedAMI(instanceTypeInfo *ec2.InstanceTypeInfo) bool {
+	if ecsUnsupportedInstanceFamilies[instanceFamily(aws.StringValue(instanceTypeInfo.InstanceType))] {
+		return false
+	}
+	if !comparators.IsSupportedFromStrings(instanceTypeInfo.SupportedRootDeviceTypes, []string{ec2.RootDeviceTypeEbs}) {
+		return false
+	}
+	if instanceTypeInfo.ProcessorInfo == nil {
+		return false
+	}
+	return comparators.IsSupportedFromStrings(instanceTypeInfo.ProcessorInfo.SupportedArchitectures, []string{ec2.ArchitectureTypeX8664, ec2.ArchitectureTypeArm64})
+}
+
+// SupportsENITrunking returns true if ECS can attach a trunk network interface to container instances of the instance type,
+// which raises the number of awsvpc network mode tasks each instance can run. Only Nitro instance types, including bare metal
+// instance types which have no hypervisor, support ENI trunking.
+func SupportsENITrunking(instanceTypeInfo *ec2.InstanceTypeInfo) bool {
+	if aws.StringValue(instanceTypeInfo.Hypervisor) != ec2.InstanceTypeHypervisorNitro && !aws.BoolValue(instanceTypeInfo.BareMetal) {
+		return false
+	}
+	return !ecsENITrunkingUnsupportedInstanceFamilies[instanceFamily(aws.StringValue(instanceTypeInfo.InstanceType))]
+}
+
+// sageMakerInstanceTypeName returns the SageMaker name of an EC2 instance type, like ml.m5.large for m5.large
+func sageMakerInstanceTypeName(instanceType string) string {
+	return sageMakerInstanceTypePrefix + instanceType
+}
+
+// newInstanceTypeSet returns a set of the instance types
+func newInstanceTypeSet(instanceTypes ...string) map[string]bool {
+	set := map[string]bool{}
+	for _, instanceType := range instanceTypes {
+		set[instanceType] = true
+	}
+	return set
+}
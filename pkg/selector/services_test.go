@@ -0,0 +1,168 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func newServiceInstanceTypeInfo(instanceType string, hypervisor string, rootDeviceTypes []string, architectures []string) *ec2.InstanceTypeInfo {
+	return &ec2.InstanceTypeInfo{
+		InstanceType:             aws.String(instanceType),
+		Hypervisor:               aws.String(hypervisor),
+		BareMetal:                aws.Bool(false),
+		SupportedRootDeviceTypes: aws.StringSlice(rootDeviceTypes),
+		ProcessorInfo:            &ec2.ProcessorInfo{SupportedArchitectures: aws.StringSlice(architectures)},
+		VCpuInfo:                 &ec2.VCpuInfo{DefaultVCpus: aws.Int64(2)},
+		MemoryInfo:               &ec2.MemoryInfo{SizeInMiB: aws.Int64(4096)},
+		NetworkInfo:              &ec2.NetworkInfo{},
+		PlacementGroupInfo:       &ec2.PlacementGroupInfo{},
+	}
+}
+
+func TestServiceNames(t *testing.T) {
+	h.Equals(t, []string{"ec2", "ecs", "elasticache", "rds", "sagemaker", "sagemaker-inference", "sagemaker-training"}, selector.ServiceNames())
+}
+
+func TestRenamesInstanceTypes(t *testing.T) {
+	for _, service := range []string{selector.ServiceSageMaker, selector.ServiceRDS, selector.ServiceElastiCache} {
+		h.Assert(t, selector.RenamesInstanceTypes(service), "%s should rename instance types", service)
+	}
+	for _, service := range []string{selector.ServiceEC2, selector.ServiceECS, "unknown"} {
+		h.Assert(t, !selector.RenamesInstanceTypes(service), "%s should not rename instance types", service)
+	}
+}
+
+func TestFilter_ServiceSageMaker(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypes, "25_instances.json")
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	filters := selector.Filters{
+		VCpusRange: &selector.IntRangeFilter{LowerBound: 2, UpperBound: 4},
+	}
+
+	filters.Service = aws.String(selector.ServiceSageMakerTraining)
+	results, err := itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"ml.c4.xlarge"}, results)
+
+	filters.Service = aws.String(selector.ServiceSageMakerInference)
+	results, err = itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"ml.c4.large", "ml.c4.xlarge", "ml.c5.large"}, results)
+
+	filters.Service = aws.String(selector.ServiceSageMaker)
+	results, err = itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"ml.c4.large", "ml.c4.xlarge", "ml.c5.large"}, results)
+}
+
+func TestFilterVerbose_ServiceDoesNotRenameSpecs(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypes, "t3_micro_and_p3_16xl.json")
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	results, err := itf.FilterVerbose(selector.Filters{Service: aws.String(selector.ServiceSageMaker)})
+	h.Ok(t, err)
+	h.Assert(t, len(results) == 1, "Should only return p3.16xlarge for SageMaker but returned %d instance types", len(results))
+	h.Equals(t, "ml.p3.16xlarge", *results[0].InstanceType)
+
+	results, err = itf.FilterVerbose(selector.Filters{Service: aws.String(selector.ServiceEC2)})
+	h.Ok(t, err)
+	h.Assert(t, len(results) == 2, "Should return both instance types for EC2 but returned %d instance types", len(results))
+	for _, instanceTypeInfo := range results {
+		h.Assert(t, *instanceTypeInfo.InstanceType != "ml.p3.16xlarge", "Should not rename the specs returned by DescribeInstanceTypes")
+	}
+}
+
+func TestExplain_Service(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypes, "t3_micro_and_p3_16xl.json")
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	explanation, err := itf.Explain(selector.Filters{Service: aws.String(selector.ServiceSageMakerTraining)})
+	h.Ok(t, err)
+	h.Equals(t, []selector.FilterRejection{{Filter: "service", FilterValue: "sagemaker-training", InstanceTypeValue: "not supported"}}, explanation.Rejections["t3.micro"])
+}
+
+func TestFilter_ServiceInvalid(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypes, "t3_micro.json")
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	_, err := itf.Filter(selector.Filters{Service: aws.String("lambda")})
+	h.Nok(t, err)
+	h.Assert(t, errors.Is(err, selector.ErrInvalidFilters), "Should return ErrInvalidFilters for an unknown service")
+}
+
+func TestFilter_ServiceECS(t *testing.T) {
+	ec2Mock := mockedEC2{
+		DescribeInstanceTypesResp: ec2.DescribeInstanceTypesOutput{
+			InstanceTypes: []*ec2.InstanceTypeInfo{
+				newServiceInstanceTypeInfo("c5.large", "nitro", []string{"ebs"}, []string{"x86_64"}),
+				newServiceInstanceTypeInfo("m6g.large", "nitro", []string{"ebs"}, []string{"arm64"}),
+				newServiceInstanceTypeInfo("mac1.metal", "", []string{"ebs"}, []string{"x86_64_mac"}),
+				newServiceInstanceTypeInfo("m1.small", "xen", []string{"instance-store"}, []string{"i386", "x86_64"}),
+				newServiceInstanceTypeInfo("t1.micro", "xen", []string{"ebs"}, []string{"i386"}),
+			},
+		},
+	}
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	results, err := itf.Filter(selector.Filters{Service: aws.String(selector.ServiceECS)})
+	h.Ok(t, err)
+	h.Equals(t, []string{"c5.large", "m6g.large"}, results)
+}
+
+func TestSupportsENITrunking(t *testing.T) {
+	h.Assert(t, selector.SupportsENITrunking(newServiceInstanceTypeInfo("c5.large", "nitro", []string{"ebs"}, []string{"x86_64"})), "c5.large should support ENI trunking")
+	h.Assert(t, !selector.SupportsENITrunking(newServiceInstanceTypeInfo("c5n.large", "nitro", []string{"ebs"}, []string{"x86_64"})), "c5n.large should not support ENI trunking")
+	h.Assert(t, !selector.SupportsENITrunking(newServiceInstanceTypeInfo("c4.large", "xen", []string{"ebs"}, []string{"x86_64"})), "Xen instance types should not support ENI trunking")
+	metal := newServiceInstanceTypeInfo("a1.metal", "", []string{"ebs"}, []string{"arm64"})
+	metal.Hypervisor = nil
+	metal.BareMetal = aws.Bool(true)
+	h.Assert(t, selector.SupportsENITrunking(metal), "Bare metal instance types run on Nitro and should support ENI trunking")
+}
+
+func TestFilter_ServiceRDSAndElastiCache(t *testing.T) {
+	ec2Mock := mockedEC2{
+		DescribeInstanceTypesResp: ec2.DescribeInstanceTypesOutput{
+			InstanceTypes: []*ec2.InstanceTypeInfo{
+				newServiceInstanceTypeInfo("c5.large", "nitro", []string{"ebs"}, []string{"x86_64"}),
+				newServiceInstanceTypeInfo("m5.large", "nitro", []string{"ebs"}, []string{"x86_64"}),
+				newServiceInstanceTypeInfo("r5.8xlarge", "nitro", []string{"ebs"}, []string{"x86_64"}),
+				newServiceInstanceTypeInfo("r6g.large", "nitro", []string{"ebs"}, []string{"arm64"}),
+				newServiceInstanceTypeInfo("t3.nano", "nitro", []string{"ebs"}, []string{"x86_64"}),
+			},
+		},
+	}
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	results, err := itf.Filter(selector.Filters{Service: aws.String(selector.ServiceRDS)})
+	h.Ok(t, err)
+	h.Equals(t, []string{"db.m5.large", "db.r5.8xlarge", "db.r6g.large"}, results)
+
+	results, err = itf.Filter(selector.Filters{Service: aws.String(selector.ServiceElastiCache)})
+	h.Ok(t, err)
+	h.Equals(t, []string{"cache.m5.large", "cache.r6g.large"}, results)
+}
@@ -0,0 +1,177 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// Sort keys which can be passed in the SortBy filter
+const (
+	// SortKeyInstanceType sorts by instance type name
+	SortKeyInstanceType = "instance-type"
+	// SortKeyVCpus sorts by the default number of vcpus
+	SortKeyVCpus = "vcpus"
+	// SortKeyMemory sorts by memory in MiB
+	SortKeyMemory = "memory"
+	// SortKeyGpus sorts by the total number of GPUs
+	SortKeyGpus = "gpus"
+	// SortKeyGpuMemory sorts by the total GPU memory in MiB
+	SortKeyGpuMemory = "gpu-memory"
+	// SortKeyNetworkInterfaces sorts by the maximum number of network interfaces
+	SortKeyNetworkInterfaces = "network-interfaces"
+	// SortKeyNetworkPerformance sorts by network performance in Gbps
+	SortKeyNetworkPerformance = "network-performance"
+)
+
+// Sort directions which can be appended to a sort key after a colon (i.e. memory:desc)
+const (
+	SortAscending  = "asc"
+	SortDescending = "desc"
+)
+
+// sortKeys maps a sort key to a func returning the value of the instance type to sort by.
+// The instance type key is compared by name so it does not need a value.
+var sortKeys = map[string]func(instanceTypeInfo *ec2.InstanceTypeInfo) int64{
+	SortKeyInstanceType: nil,
+	SortKeyVCpus:        defaultVCpus,
+	SortKeyMemory:       memoryMiB,
+	SortKeyGpus: func(instanceTypeInfo *ec2.InstanceTypeInfo) int64 {
+		return aws.Int64Value(getTotalGpusCount(instanceTypeInfo.GpuInfo))
+	},
+	SortKeyGpuMemory: func(instanceTypeInfo *ec2.InstanceTypeInfo) int64 {
+		return aws.Int64Value(getTotalGpuMemory(instanceTypeInfo.GpuInfo))
+	},
+	SortKeyNetworkInterfaces:  maxNetworkInterfaces,
+	SortKeyNetworkPerformance: networkPerformanceGbps,
+}
+
+// sortKey is a parsed SortBy entry
+type sortKey struct {
+	name       string
+	descending bool
+}
+
+// SortKeyNames returns the sorted names of all sort keys
+func SortKeyNames() []string {
+	names := []string{}
+	for name := range sortKeys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseSortBy parses sort keys with an optional direction suffix, like memory:desc
+func parseSortBy(sortBy []string) ([]sortKey, error) {
+	keys := []sortKey{}
+	for _, entry := range sortBy {
+		name, direction := entry, SortAscending
+		if i := strings.LastIndex(entry, ":"); i != -1 {
+			name, direction = entry[:i], entry[i+1:]
+		}
+		if _, ok := sortKeys[name]; !ok {
+			return nil, newClassifiedError(ErrInvalidFilters, "Invalid filter sortBy: unknown sort key %q, the supported sort keys are: [%s]", name, strings.Join(SortKeyNames(), ", "))
+		}
+		if direction != SortAscending && direction != SortDescending {
+			return nil, newClassifiedError(ErrInvalidFilters, "Invalid filter sortBy: unknown sort direction %q for %s, the supported directions are: [%s, %s]", direction, name, SortAscending, SortDescending)
+		}
+		keys = append(keys, sortKey{name: name, descending: direction == SortDescending})
+	}
+	return keys, nil
+}
+
+// sortInstanceTypeInfoBy sorts the instance types by each of the sort keys in order.
+// The sort is stable so instance types with equal values keep their existing order.
+func sortInstanceTypeInfoBy(sortBy []string, instanceTypeInfoSlice []*ec2.InstanceTypeInfo) ([]*ec2.InstanceTypeInfo, error) {
+	keys, err := parseSortBy(sortBy)
+	if err != nil || len(keys) == 0 {
+		return instanceTypeInfoSlice, err
+	}
+	sort.SliceStable(instanceTypeInfoSlice, func(i, j int) bool {
+		for _, key := range keys {
+			a, b := instanceTypeInfoSlice[i], instanceTypeInfoSlice[j]
+			if key.descending {
+				a, b = b, a
+			}
+			if key.name == SortKeyInstanceType {
+				if nameA, nameB := aws.StringValue(a.InstanceType), aws.StringValue(b.InstanceType); nameA != nameB {
+					return nameA < nameB
+				}
+				continue
+			}
+			if valueA, valueB := sortKeys[key.name](a), sortKeys[key.name](b); valueA != valueB {
+				return valueA < valueB
+			}
+		}
+		return false
+	})
+	return instanceTypeInfoSlice, nil
+}
+
+// rankByRelevance orders the instance types so that the best candidates for the filters come first when no SortBy keys are set:
+// current generation instance types before previous generation ones, then the fewest vcpus and the least memory since the smallest
+// instance types which satisfy the filters are usually the cheapest, and finally by name so that the ranking is stable.
+func rankByRelevance(instanceTypeInfoSlice []*ec2.InstanceTypeInfo) []*ec2.InstanceTypeInfo {
+	sort.SliceStable(instanceTypeInfoSlice, func(i, j int) bool {
+		a, b := instanceTypeInfoSlice[i], instanceTypeInfoSlice[j]
+		if currentA, currentB := aws.BoolValue(a.CurrentGeneration), aws.BoolValue(b.CurrentGeneration); currentA != currentB {
+			return currentA
+		}
+		if vcpusA, vcpusB := defaultVCpus(a), defaultVCpus(b); vcpusA != vcpusB {
+			return vcpusA < vcpusB
+		}
+		if memoryA, memoryB := memoryMiB(a), memoryMiB(b); memoryA != memoryB {
+			return memoryA < memoryB
+		}
+		return aws.StringValue(a.InstanceType) < aws.StringValue(b.InstanceType)
+	})
+	return instanceTypeInfoSlice
+}
+
+// defaultVCpus returns the default number of vcpus of the instance type, or 0 if it is not known
+func defaultVCpus(instanceTypeInfo *ec2.InstanceTypeInfo) int64 {
+	if instanceTypeInfo.VCpuInfo == nil {
+		return 0
+	}
+	return aws.Int64Value(instanceTypeInfo.VCpuInfo.DefaultVCpus)
+}
+
+// memoryMiB returns the memory of the instance type in MiB, or 0 if it is not known
+func memoryMiB(instanceTypeInfo *ec2.InstanceTypeInfo) int64 {
+	if instanceTypeInfo.MemoryInfo == nil {
+		return 0
+	}
+	return aws.Int64Value(instanceTypeInfo.MemoryInfo.SizeInMiB)
+}
+
+// maxNetworkInterfaces returns the maximum number of network interfaces of the instance type, or 0 if it is not known
+func maxNetworkInterfaces(instanceTypeInfo *ec2.InstanceTypeInfo) int64 {
+	if instanceTypeInfo.NetworkInfo == nil {
+		return 0
+	}
+	return aws.Int64Value(instanceTypeInfo.NetworkInfo.MaximumNetworkInterfaces)
+}
+
+// networkPerformanceGbps returns the network performance of the instance type in Gbps, or -1 if it is not known
+func networkPerformanceGbps(instanceTypeInfo *ec2.InstanceTypeInfo) int64 {
+	if instanceTypeInfo.NetworkInfo == nil {
+		return -1
+	}
+	return int64(aws.IntValue(getNetworkPerformance(instanceTypeInfo.NetworkInfo.NetworkPerformance)))
+}
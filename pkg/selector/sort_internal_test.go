@@ -0,0 +1,45 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"testing"
+
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestSortInstanceTypeInfoBy_MissingInfo(t *testing.T) {
+	for _, sortBy := range SortKeyNames() {
+		instanceTypes := []*ec2.InstanceTypeInfo{
+			{InstanceType: aws.String("z1.unknown")},
+			{
+				InstanceType: aws.String("a1.large"),
+				VCpuInfo:     &ec2.VCpuInfo{DefaultVCpus: aws.Int64(2)},
+				MemoryInfo:   &ec2.MemoryInfo{SizeInMiB: aws.Int64(4096)},
+				GpuInfo:      &ec2.GpuInfo{Gpus: []*ec2.GpuDeviceInfo{{Count: aws.Int64(1), MemoryInfo: &ec2.GpuDeviceMemoryInfo{SizeInMiB: aws.Int64(8192)}}}},
+				NetworkInfo:  &ec2.NetworkInfo{MaximumNetworkInterfaces: aws.Int64(3), NetworkPerformance: aws.String("Up to 10 Gigabit")},
+			},
+		}
+		sorted, err := sortInstanceTypeInfoBy([]string{sortBy}, instanceTypes)
+		h.Ok(t, err)
+		// instance types with missing info sort as the lowest value
+		expected := "z1.unknown"
+		if sortBy == SortKeyInstanceType {
+			expected = "a1.large"
+		}
+		h.Equals(t, expected, aws.StringValue(sorted[0].InstanceType))
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func TestFilter_SortBy(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypes, "25_instances.json")
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	filters := selector.Filters{
+		CPUArchitecture: []string{"arm64"},
+		SortBy:          []string{"memory:desc"},
+		MaxResults:      aws.Int(2),
+	}
+	results, err := itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"a1.4xlarge", "a1.metal"}, results)
+
+	filters.SortBy = []string{"vcpus:desc", "instance-type:desc"}
+	results, err = itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"a1.metal", "a1.4xlarge"}, results)
+
+	filters.SortBy = []string{"vcpus"}
+	results, err = itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"a1.medium", "a1.large"}, results)
+}
+
+func TestFilter_MaxResultsRanksByRelevance(t *testing.T) {
+	itf := selector.Selector{
+		EC2: setupMock(t, describeInstanceTypes, "25_instances.json"),
+	}
+	// previous generation c1.xlarge and c3.2xlarge are first alphabetically but are ranked after the current generation instance types
+	results, err := itf.Filter(selector.Filters{
+		VCpusRange:      &selector.IntRangeFilter{LowerBound: 8, UpperBound: 8},
+		CPUArchitecture: []string{"x86_64"},
+		MaxResults:      aws.Int(2),
+	})
+	h.Ok(t, err)
+	h.Equals(t, []string{"c4.2xlarge", "c5.2xlarge"}, results)
+
+	// the fewest vcpus are kept first and returned in name order
+	results, err = itf.Filter(selector.Filters{
+		CPUArchitecture: []string{"arm64"},
+		MaxResults:      aws.Int(2),
+	})
+	h.Ok(t, err)
+	h.Equals(t, []string{"a1.large", "a1.medium"}, results)
+}
+
+func TestFilter_SortByInvalid(t *testing.T) {
+	itf := selector.Selector{
+		EC2: setupMock(t, describeInstanceTypes, "25_instances.json"),
+	}
+	for _, sortBy := range [][]string{{"price"}, {"memory:up"}} {
+		_, err := itf.Filter(selector.Filters{SortBy: sortBy})
+		h.Assert(t, errors.Is(err, selector.ErrInvalidFilters), "Sort by %v should be invalid", sortBy)
+	}
+}
+
+func TestSortKeyNames(t *testing.T) {
+	names := selector.SortKeyNames()
+	h.Assert(t, len(names) > 1, "Should return the sort keys")
+	h.Equals(t, selector.SortKeyGpuMemory, names[0])
+}
@@ -0,0 +1,105 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package specs provides functions to parse EC2 instance type spec strings into numeric values.
+// These are the same normalizations the selector filters use.
+package specs
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	supported = "supported"
+	required  = "required"
+	ebsOnly   = "ebs only"
+)
+
+var (
+	networkPerformanceRegex = regexp.MustCompile(`(?i)(up to )?([0-9]+(?:\.[0-9]+)?) Gigabit`)
+	storageRegex            = regexp.MustCompile(`(?i)^\s*([0-9]+)\s*x\s*([0-9]+)\s*(?:GB)?\s*(.*?)\s*$`)
+)
+
+// NetworkPerformance is the parsed form of an EC2 network performance string like "Up to 25 Gigabit"
+type NetworkPerformance struct {
+	// Gbps is the bandwidth in gigabits per second
+	Gbps float64
+	// UpTo is true when the bandwidth is a burst ceiling rather than a baseline (i.e. "Up to 10 Gigabit")
+	UpTo bool
+}
+
+// Storage is the parsed form of an EC2 instance storage description like "2 x 900 NVMe SSD"
+type Storage struct {
+	// Disks is the number of disks
+	Disks int
+	// SizeGB is the size of each disk in GB
+	SizeGB int
+	// Type is the remaining disk description, like "NVMe SSD" or "HDD"
+	Type string
+}
+
+// TotalSizeGB returns the combined size of all disks in GB
+func (s Storage) TotalSizeGB() int {
+	return s.Disks * s.SizeGB
+}
+
+// ParseNetworkPerformance parses an EC2 network performance string like "10 Gigabit", "Up to 25 Gigabit", or "12.5 Gigabit".
+// Qualitative values like "Low", "Moderate", or "High" cannot be converted to a bandwidth and return an error.
+func ParseNetworkPerformance(networkPerformance string) (NetworkPerformance, error) {
+	matches := networkPerformanceRegex.FindStringSubmatch(networkPerformance)
+	if matches == nil {
+		return NetworkPerformance{}, fmt.Errorf("unable to parse network performance %q", networkPerformance)
+	}
+	gbps, err := strconv.ParseFloat(matches[2], 64)
+	if err != nil {
+		return NetworkPerformance{}, fmt.Errorf("unable to parse network performance %q: %w", networkPerformance, err)
+	}
+	return NetworkPerformance{
+		Gbps: gbps,
+		UpTo: matches[1] != "",
+	}, nil
+}
+
+// ParseStorage parses an EC2 instance storage description like "2 x 900 NVMe SSD" or "1 x 1900 GB SSD".
+// "EBS only" is parsed to a Storage with no disks.
+func ParseStorage(storage string) (Storage, error) {
+	if strings.ToLower(strings.TrimSpace(storage)) == ebsOnly {
+		return Storage{}, nil
+	}
+	matches := storageRegex.FindStringSubmatch(storage)
+	if matches == nil {
+		return Storage{}, fmt.Errorf("unable to parse storage %q. A valid example is 2 x 900 NVMe SSD", storage)
+	}
+	disks, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return Storage{}, fmt.Errorf("unable to parse storage %q: %w", storage, err)
+	}
+	sizeGB, err := strconv.Atoi(matches[2])
+	if err != nil {
+		return Storage{}, fmt.Errorf("unable to parse storage %q: %w", storage, err)
+	}
+	return Storage{
+		Disks:  disks,
+		SizeGB: sizeGB,
+		Type:   matches[3],
+	}, nil
+}
+
+// IsSupported returns true if an EC2 support string is "supported" or "required" (case-insensitive)
+func IsSupported(support string) bool {
+	support = strings.ToLower(support)
+	return support == supported || support == required
+}
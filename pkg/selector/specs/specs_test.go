@@ -0,0 +1,82 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package specs_test
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector/specs"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+)
+
+// Tests
+
+func TestParseNetworkPerformance(t *testing.T) {
+	perf, err := specs.ParseNetworkPerformance("10 Gigabit")
+	h.Ok(t, err)
+	h.Equals(t, specs.NetworkPerformance{Gbps: 10}, perf)
+
+	perf, err = specs.ParseNetworkPerformance("Up to 25 Gigabit")
+	h.Ok(t, err)
+	h.Equals(t, specs.NetworkPerformance{Gbps: 25, UpTo: true}, perf)
+
+	perf, err = specs.ParseNetworkPerformance("12.5 Gigabit")
+	h.Ok(t, err)
+	h.Equals(t, specs.NetworkPerformance{Gbps: 12.5}, perf)
+
+	perf, err = specs.ParseNetworkPerformance("100 Gigabit abcd")
+	h.Ok(t, err)
+	h.Equals(t, specs.NetworkPerformance{Gbps: 100}, perf)
+}
+
+func TestParseNetworkPerformance_Invalid(t *testing.T) {
+	for _, networkPerformance := range []string{"High", "Moderate", "", "abcd", "Gigabit"} {
+		_, err := specs.ParseNetworkPerformance(networkPerformance)
+		h.Nok(t, err)
+	}
+}
+
+func TestParseStorage(t *testing.T) {
+	storage, err := specs.ParseStorage("2 x 900 NVMe SSD")
+	h.Ok(t, err)
+	h.Equals(t, specs.Storage{Disks: 2, SizeGB: 900, Type: "NVMe SSD"}, storage)
+	h.Equals(t, 1800, storage.TotalSizeGB())
+
+	storage, err = specs.ParseStorage("1 x 1900 GB SSD")
+	h.Ok(t, err)
+	h.Equals(t, specs.Storage{Disks: 1, SizeGB: 1900, Type: "SSD"}, storage)
+
+	storage, err = specs.ParseStorage("24x2000 HDD")
+	h.Ok(t, err)
+	h.Equals(t, specs.Storage{Disks: 24, SizeGB: 2000, Type: "HDD"}, storage)
+
+	storage, err = specs.ParseStorage("EBS only")
+	h.Ok(t, err)
+	h.Equals(t, 0, storage.TotalSizeGB())
+}
+
+func TestParseStorage_Invalid(t *testing.T) {
+	for _, storage := range []string{"", "abcd", "2 x SSD", "x 900 SSD"} {
+		_, err := specs.ParseStorage(storage)
+		h.Nok(t, err)
+	}
+}
+
+func TestIsSupported(t *testing.T) {
+	h.Assert(t, specs.IsSupported("supported"), "supported should be supported")
+	h.Assert(t, specs.IsSupported("required"), "required should be supported")
+	h.Assert(t, specs.IsSupported("SuPpOrTeD"), "support strings should be case-insensitive")
+	h.Assert(t, !specs.IsSupported("unsupported"), "unsupported should not be supported")
+	h.Assert(t, !specs.IsSupported(""), "an empty string should not be supported")
+}
@@ -0,0 +1,164 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+const (
+	// spotProductDescription is the platform spot prices are retrieved for
+	spotProductDescription = "Linux/UNIX"
+	// spotPriceHistoryBatchSize is the number of instance types spot prices are retrieved for in each DescribeSpotPriceHistory request
+	spotPriceHistoryBatchSize = 100
+)
+
+// RecommendSpotPlacement returns, for each instance type matching the filters, the availability zones offering it sorted by their
+// current Linux spot price along with the cheapest zones to run spot capacity in. Up to zones availability zones are recommended
+// for each instance type so that capacity can be spread across zones. Zones without a current spot price are not recommended.
+// Only the zone of the AvailabilityZone filter is evaluated when it is set. Spot prices are only available from the EC2 API,
+// so an error is returned for a Selector with a DataProvider.
+func (itf Selector) RecommendSpotPlacement(filters Filters, zones int) ([]SpotPlacement, error) {
+	if zones <= 0 {
+		return nil, newClassifiedError(ErrInvalidFilters, "The number of recommended zones must be greater than 0 but was %d", zones)
+	}
+	if itf.DataProvider != nil {
+		return nil, fmt.Errorf("Recommending spot placement cannot be done with a data provider since spot prices are only available from the EC2 API")
+	}
+	candidateZone := ""
+	if zone := aws.StringValue(filters.AvailabilityZone); IsZone(zone) {
+		resolvedZone, err := itf.ResolveZone(zone)
+		if err != nil {
+			return nil, err
+		}
+		candidateZone = resolvedZone.Name
+	}
+	// spot prices are looked up by the EC2 name of the instance types, even if they are for another service
+	instanceTypeInfoSlice, err := itf.rawFilter(filters)
+	if err != nil {
+		return nil, err
+	}
+	instanceTypeInfoSlice = itf.truncateResults(filters, instanceTypeInfoSlice)
+	instanceTypes := []string{}
+	for _, instanceTypeInfo := range instanceTypeInfoSlice {
+		instanceTypes = append(instanceTypes, aws.StringValue(instanceTypeInfo.InstanceType))
+	}
+	offeredZones, err := itf.RetrieveInstanceTypeZones()
+	if err != nil {
+		return nil, err
+	}
+	spotPrices, err := itf.retrieveSpotPrices(instanceTypes)
+	if err != nil {
+		return nil, err
+	}
+	placements := []SpotPlacement{}
+	for _, instanceType := range instanceTypes {
+		placement := SpotPlacement{InstanceType: instanceType, ZonePrices: []SpotZonePrice{}, RecommendedZones: []string{}}
+		for _, zone := range offeredZones[instanceType] {
+			if candidateZone != "" && zone != candidateZone {
+				continue
+			}
+			if price, ok := spotPrices[instanceType][zone]; ok {
+				placement.ZonePrices = append(placement.ZonePrices, SpotZonePrice{AvailabilityZone: zone, PricePerHour: price})
+			}
+		}
+		sort.SliceStable(placement.ZonePrices, func(i, j int) bool {
+			if placement.ZonePrices[i].PricePerHour != placement.ZonePrices[j].PricePerHour {
+				return placement.ZonePrices[i].PricePerHour < placement.ZonePrices[j].PricePerHour
+			}
+			return placement.ZonePrices[i].AvailabilityZone < placement.ZonePrices[j].AvailabilityZone
+		})
+		for i := 0; i < len(placement.ZonePrices) && i < zones; i++ {
+			placement.RecommendedZones = append(placement.RecommendedZones, placement.ZonePrices[i].AvailabilityZone)
+		}
+		placements = append(placements, placement)
+	}
+	return placements, nil
+}
+
+// lowestSpotPrices returns the lowest current Linux spot price per hour in USD of each instance type in the zone name,
+// or in any zone of the region when zone is empty. Instance types without a current spot price are omitted.
+func (itf Selector) lowestSpotPrices(instanceTypes []string, zone string) (map[string]float64, error) {
+	spotPrices, err := itf.retrieveSpotPrices(instanceTypes)
+	if err != nil {
+		return nil, err
+	}
+	lowestPrices := map[string]float64{}
+	for instanceType, zonePrices := range spotPrices {
+		for zonePricesZone, price := range zonePrices {
+			if zone != "" && zonePricesZone != zone {
+				continue
+			}
+			if lowestPrice, ok := lowestPrices[instanceType]; !ok || price < lowestPrice {
+				lowestPrices[instanceType] = price
+			}
+		}
+	}
+	return lowestPrices, nil
+}
+
+// retrieveSpotPrices returns a map of instance type -> availability zone -> the current Linux spot price per hour in USD
+func (itf Selector) retrieveSpotPrices(instanceTypes []string) (map[string]map[string]float64, error) {
+	spotPrices := map[string]map[string]float64{}
+	timestamps := map[string]map[string]time.Time{}
+	now := time.Now()
+	for start := 0; start < len(instanceTypes); start += spotPriceHistoryBatchSize {
+		end := start + spotPriceHistoryBatchSize
+		if end > len(instanceTypes) {
+			end = len(instanceTypes)
+		}
+		// a start time of now returns the price in effect in each zone rather than the full history
+		spotPriceHistoryInput := &ec2.DescribeSpotPriceHistoryInput{
+			InstanceTypes:       aws.StringSlice(instanceTypes[start:end]),
+			ProductDescriptions: []*string{aws.String(spotProductDescription)},
+			StartTime:           aws.Time(now),
+		}
+		itf.debugf("calling DescribeSpotPriceHistory for %d instance types", end-start)
+		var parseErr error
+		err := itf.EC2.DescribeSpotPriceHistoryPages(spotPriceHistoryInput, func(page *ec2.DescribeSpotPriceHistoryOutput, lastPage bool) bool {
+			for _, spotPrice := range page.SpotPriceHistory {
+				instanceType := aws.StringValue(spotPrice.InstanceType)
+				zone := aws.StringValue(spotPrice.AvailabilityZone)
+				price, err := strconv.ParseFloat(aws.StringValue(spotPrice.SpotPrice), 64)
+				if err != nil {
+					parseErr = fmt.Errorf("Unable to parse the spot price of %s in %s: %w", instanceType, zone, err)
+					return false
+				}
+				if _, ok := spotPrices[instanceType]; !ok {
+					spotPrices[instanceType] = map[string]float64{}
+					timestamps[instanceType] = map[string]time.Time{}
+				}
+				// only the most recent price of each zone is kept
+				if timestamp, ok := timestamps[instanceType][zone]; !ok || aws.TimeValue(spotPrice.Timestamp).After(timestamp) {
+					spotPrices[instanceType][zone] = price
+					timestamps[instanceType][zone] = aws.TimeValue(spotPrice.Timestamp)
+				}
+			}
+			return true
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Encountered an error when describing spot price history: %w", classifyAPIError(err))
+		}
+		if parseErr != nil {
+			return nil, parseErr
+		}
+	}
+	return spotPrices, nil
+}
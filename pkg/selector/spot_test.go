@@ -0,0 +1,103 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// Helpers
+
+func setupSpotMock(t *testing.T) mockedEC2 {
+	ec2Mock := setupMock(t, describeInstanceTypes, "t3_micro_and_p3_16xl.json")
+	ec2Mock.DescribeInstanceTypeOfferingsResp = ec2.DescribeInstanceTypeOfferingsOutput{
+		InstanceTypeOfferings: []*ec2.InstanceTypeOffering{
+			{InstanceType: aws.String("t3.micro"), Location: aws.String("us-east-2c")},
+			{InstanceType: aws.String("t3.micro"), Location: aws.String("us-east-2a")},
+			{InstanceType: aws.String("t3.micro"), Location: aws.String("us-east-2b")},
+			{InstanceType: aws.String("p3.16xlarge"), Location: aws.String("us-east-2a")},
+		},
+	}
+	now := time.Now()
+	ec2Mock.DescribeSpotPriceHistoryResp = ec2.DescribeSpotPriceHistoryOutput{
+		SpotPriceHistory: []*ec2.SpotPrice{
+			{InstanceType: aws.String("t3.micro"), AvailabilityZone: aws.String("us-east-2a"), SpotPrice: aws.String("0.0040"), Timestamp: aws.Time(now)},
+			{InstanceType: aws.String("t3.micro"), AvailabilityZone: aws.String("us-east-2b"), SpotPrice: aws.String("0.0031"), Timestamp: aws.Time(now)},
+			// an older price should be replaced by the most recent one
+			{InstanceType: aws.String("t3.micro"), AvailabilityZone: aws.String("us-east-2c"), SpotPrice: aws.String("0.0035"), Timestamp: aws.Time(now)},
+			{InstanceType: aws.String("t3.micro"), AvailabilityZone: aws.String("us-east-2c"), SpotPrice: aws.String("0.0010"), Timestamp: aws.Time(now.Add(-time.Hour))},
+			// zones which do not offer the instance type are not recommended
+			{InstanceType: aws.String("p3.16xlarge"), AvailabilityZone: aws.String("us-east-2b"), SpotPrice: aws.String("7.3"), Timestamp: aws.Time(now)},
+		},
+	}
+	return ec2Mock
+}
+
+// Tests
+
+func TestRecommendSpotPlacement(t *testing.T) {
+	itf := selector.Selector{EC2: setupSpotMock(t)}
+	placements, err := itf.RecommendSpotPlacement(selector.Filters{}, 2)
+	h.Ok(t, err)
+	h.Equals(t, 2, len(placements))
+	h.Equals(t, "p3.16xlarge", placements[0].InstanceType)
+	h.Equals(t, []string{}, placements[0].RecommendedZones)
+	h.Equals(t, "t3.micro", placements[1].InstanceType)
+	h.Equals(t, []string{"us-east-2b", "us-east-2c"}, placements[1].RecommendedZones)
+	h.Equals(t, []selector.SpotZonePrice{
+		{AvailabilityZone: "us-east-2b", PricePerHour: 0.0031},
+		{AvailabilityZone: "us-east-2c", PricePerHour: 0.0035},
+		{AvailabilityZone: "us-east-2a", PricePerHour: 0.004},
+	}, placements[1].ZonePrices)
+}
+
+func TestRecommendSpotPlacement_AvailabilityZone(t *testing.T) {
+	ec2Mock := setupSpotMock(t)
+	ec2Mock.DescribeAvailabilityZonesResp = usEast2Zones()
+	itf := selector.Selector{EC2: ec2Mock}
+	placements, err := itf.RecommendSpotPlacement(selector.Filters{AvailabilityZone: aws.String("use2-az2")}, 2)
+	h.Ok(t, err)
+	h.Equals(t, "t3.micro", placements[1].InstanceType)
+	h.Equals(t, []string{"us-east-2b"}, placements[1].RecommendedZones)
+	h.Equals(t, []selector.SpotZonePrice{{AvailabilityZone: "us-east-2b", PricePerHour: 0.0031}}, placements[1].ZonePrices)
+}
+
+func TestRecommendSpotPlacement_Errors(t *testing.T) {
+	itf := selector.Selector{EC2: setupSpotMock(t)}
+	_, err := itf.RecommendSpotPlacement(selector.Filters{}, 0)
+	h.Assert(t, errors.Is(err, selector.ErrInvalidFilters), "Should return ErrInvalidFilters when no zones are requested")
+
+	ec2Mock := setupSpotMock(t)
+	ec2Mock.DescribeSpotPriceHistoryErr = errors.New("error")
+	itf = selector.Selector{EC2: ec2Mock}
+	_, err = itf.RecommendSpotPlacement(selector.Filters{}, 1)
+	h.Nok(t, err)
+
+	ec2Mock = setupSpotMock(t)
+	ec2Mock.DescribeSpotPriceHistoryResp.SpotPriceHistory[0].SpotPrice = aws.String("not a price")
+	itf = selector.Selector{EC2: ec2Mock}
+	_, err = itf.RecommendSpotPlacement(selector.Filters{}, 1)
+	h.Nok(t, err)
+
+	itf = selector.Selector{EC2: setupSpotMock(t), DataProvider: selector.NewStaticDataProvider(selector.Snapshot{})}
+	_, err = itf.RecommendSpotPlacement(selector.Filters{}, 1)
+	h.Nok(t, err)
+}
@@ -14,6 +14,8 @@
 package selector
 
 import (
+	"time"
+
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
 )
@@ -32,16 +34,245 @@ func (fn InstanceTypesOutputFn) Output(instanceTypes []*ec2.InstanceTypeInfo) []
 	return fn(instanceTypes)
 }
 
+// Logger can be implemented to receive debug logs from the selector such as API calls, page counts, and filter eliminations.
+// Loggers like zap's SugaredLogger and logrus satisfy this interface directly.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+}
+
+// LoggerFn is the func type definition for Logger
+type LoggerFn func(format string, args ...interface{})
+
+// Debugf implements Logger interface on LoggerFn
+// This allows funcs like log.Printf to be used as a Logger
+func (fn LoggerFn) Debugf(format string, args ...interface{}) {
+	fn(format, args...)
+}
+
+// ProgressHooks are optional callbacks which are invoked while filtering so that progress can be rendered or emitted as metrics.
+// Any hook which is nil is skipped.
+type ProgressHooks struct {
+	// PageFetched is called after each page of DescribeInstanceTypes is retrieved with the page number and the number of instance types in the page
+	PageFetched func(page int, instanceTypes int)
+	// InstanceTypeEvaluated is called after each instance type is evaluated against the filters
+	// with the number of instance types evaluated and matched so far and the total number of instance types being evaluated
+	InstanceTypeEvaluated func(evaluated int, matched int, total int)
+}
+
+// CustomFilterFn is a predicate which returns true if an instance type should be included in results
+type CustomFilterFn func(instanceTypeInfo *ec2.InstanceTypeInfo) bool
+
+// customFilter holds a CustomFilterFn and the name used to report it in explanations and logs
+type customFilter struct {
+	name string
+	fn   CustomFilterFn
+}
+
+// InstanceSelector is implemented by Selector and can be used to swap or mock the instance type selection logic
+type InstanceSelector interface {
+	Filter(filters Filters) ([]string, error)
+	FilterVerbose(filters Filters) ([]*ec2.InstanceTypeInfo, error)
+	FilterWithOutput(filters Filters, outputFn InstanceTypesOutput) ([]string, error)
+	FilterDetailed(filters Filters) ([]InstanceTypeDetails, error)
+	FilterRelaxed(filters Filters) (*RelaxedResults, error)
+	FilterAcrossRegions(filters Filters, regions []string) (*MultiRegionResults, error)
+}
+
+// Selector implements InstanceSelector
+var _ InstanceSelector = Selector{}
+
 // Selector is used to filter instance type resource specs
 type Selector struct {
 	EC2 ec2iface.EC2API
+	// DataProvider is optional and supplies the instance type specs and offerings instead of EC2. If nil, they are retrieved from EC2.
+	DataProvider InstanceDataProvider
+	// Logger is optional and receives debug logs while filtering. If nil, nothing is logged.
+	Logger Logger
+	// Progress is optional and receives progress callbacks while filtering. If nil, no callbacks are made.
+	Progress      *ProgressHooks
+	customFilters []customFilter
+	cache         *instanceTypesCache
+	// regionalEC2Client creates the EC2 client used for each region queried by FilterAcrossRegions
+	regionalEC2Client func(region string) ec2iface.EC2API
 }
 
 // IntRangeFilter holds an upper and lower bound int
 // The lower and upper bound are used to range filter resource specs
 type IntRangeFilter struct {
-	UpperBound int
-	LowerBound int
+	UpperBound int `json:"upperBound"`
+	LowerBound int `json:"lowerBound"`
+}
+
+// RelaxedResults holds the instance types matched by FilterRelaxed along with any relaxations applied to the filters
+type RelaxedResults struct {
+	// InstanceTypes are the detailed specs of the instance types which matched Filters
+	InstanceTypes []*ec2.InstanceTypeInfo
+	// Filters are the filters, including any relaxations, which were used to match InstanceTypes
+	Filters Filters
+	// Relaxations describe each change made to the original filters. Empty if the original filters matched.
+	Relaxations []string
+}
+
+// InstanceTypesChange describes how the instance types matching the same filters changed between two evaluations
+type InstanceTypesChange struct {
+	// Added are the instance types which match now but did not match before, like a newly offered instance type
+	Added []string
+	// Removed are the instance types which matched before but do not match now
+	Removed []string
+	// PriceChanges are the instance types which matched both times but changed price. Only populated when pricing information is available.
+	PriceChanges []PriceChange
+}
+
+// PriceChange holds the previous and current hourly price of an instance type
+type PriceChange struct {
+	InstanceType         string
+	PreviousPricePerHour float64
+	PricePerHour         float64
+}
+
+// APICallPlan holds the EC2 API requests that filtering would issue, as returned by DryRun
+type APICallPlan struct {
+	// DescribeInstanceTypeOfferings is the request for the instance types offered in the location filter. Nil if no location is filtered.
+	DescribeInstanceTypeOfferings *ec2.DescribeInstanceTypeOfferingsInput `json:"describeInstanceTypeOfferings,omitempty"`
+	// DescribeInstanceTypes is the request for the specs of the instance types, including any raw EC2 filters
+	DescribeInstanceTypes *ec2.DescribeInstanceTypesInput `json:"describeInstanceTypes"`
+}
+
+// FilterRejection describes a filter which rejected an instance type
+type FilterRejection struct {
+	// Filter is the name of the filter which rejected the instance type
+	Filter string
+	// FilterValue is the value of the filter which was not satisfied
+	FilterValue string
+	// InstanceTypeValue is the instance type's spec value which was compared against the filter value
+	InstanceTypeValue string
+}
+
+// InstanceTypeDetails is a summary of the specs of an instance type matched by FilterDetailed
+type InstanceTypeDetails struct {
+	InstanceType       string   `json:"instanceType"`
+	VCpus              int      `json:"vcpus"`
+	MemoryMiB          int      `json:"memoryMiB"`
+	CPUArchitectures   []string `json:"cpuArchitectures"`
+	Gpus               int      `json:"gpus"`
+	GpuMemoryMiB       int      `json:"gpuMemoryMiB"`
+	NetworkPerformance string   `json:"networkPerformance"`
+	NetworkInterfaces  int      `json:"networkInterfaces"`
+	Hypervisor         string   `json:"hypervisor,omitempty"`
+	CurrentGeneration  bool     `json:"currentGeneration"`
+	Burstable          bool     `json:"burstable"`
+	BareMetal          bool     `json:"bareMetal"`
+	// Locations are the availability zones or region where the instance type is offered. Only populated when a location filter is set.
+	Locations []string `json:"locations,omitempty"`
+	// Zone is the availability zone of the location filter with both its name and zone ID. Only populated when the location filter is a zone.
+	Zone *Zone `json:"zone,omitempty"`
+	// PricePerHour is the lowest current Linux spot price per hour in USD of the instance type in the zone of the location filter,
+	// or in any zone of the region. Nil when the instance type has no current spot price or the prices could not be retrieved.
+	PricePerHour *float64 `json:"pricePerHour,omitempty"`
+}
+
+// InstanceTypeLookup holds the full specs of a single instance type returned by GetInstanceTypeDetails along with where it is offered
+type InstanceTypeLookup struct {
+	// InstanceTypeInfo is the detailed specs of the instance type
+	InstanceTypeInfo *ec2.InstanceTypeInfo
+	// AvailabilityZones are the availability zones in the region where the instance type is offered
+	AvailabilityZones []string
+	// AvailabilityZoneIDs maps each of the AvailabilityZones to its zone ID, which identifies the same physical zone in every account
+	AvailabilityZoneIDs map[string]string
+}
+
+// Zone is an availability zone identified by both its account-specific name and its zone ID
+type Zone struct {
+	// Name is the zone name, like us-east-1a, which maps to a different physical zone in each account
+	Name string `json:"name"`
+	// ID is the zone ID, like use1-az6, which identifies the same physical zone in every account
+	ID string `json:"id"`
+	// Region is the region of the zone
+	Region string `json:"region,omitempty"`
+	// GroupName is the zone group, like us-west-2-lax-1 for a Local Zone
+	GroupName string `json:"groupName,omitempty"`
+	// OptInStatus is whether the account is opted in to the zone
+	OptInStatus string `json:"optInStatus,omitempty"`
+}
+
+// SpotPlacement holds the spot placement guidance for an instance type returned by RecommendSpotPlacement
+type SpotPlacement struct {
+	InstanceType string
+	// RecommendedZones are the availability zones with the lowest current spot price, cheapest first
+	RecommendedZones []string
+	// ZonePrices are the current spot prices in each availability zone offering the instance type, cheapest first
+	ZonePrices []SpotZonePrice
+}
+
+// SpotZonePrice is the current spot price of an instance type in an availability zone
+type SpotZonePrice struct {
+	AvailabilityZone string
+	// PricePerHour is the Linux spot price per hour in USD
+	PricePerHour float64
+}
+
+// SpecComparison holds the values of a single spec for the two instance types compared by Compare
+type SpecComparison struct {
+	Spec      string
+	A         string
+	B         string
+	Different bool
+}
+
+// Comparison holds the field-by-field comparison of the specs of two instance types
+type Comparison struct {
+	InstanceTypeA string
+	InstanceTypeB string
+	Specs         []SpecComparison
+}
+
+// MultiRegionResults holds the instance types matched by FilterAcrossRegions in each region
+type MultiRegionResults struct {
+	// Regions maps each region to the names of the instance types which matched the filters in that region
+	Regions map[string][]string
+	// Intersection holds the names of the instance types which matched the filters in every region
+	Intersection []string
+}
+
+// RegionRecommendation holds how suitable a region is for the filters and target capacity, returned by RecommendRegions
+type RegionRecommendation struct {
+	Region string
+	// SpotPlacementScore is the Spot Placement Score of the region from 1 to 10, where 10 is the most likely to fulfill the
+	// target capacity with spot instances of the matching instance types
+	SpotPlacementScore int64
+	// InstanceTypes is the number of instance types which matched the filters in the region
+	InstanceTypes int
+	// LowestSpotPricePerHour is the lowest current Linux spot price in USD of the matching instance types in any zone of the region,
+	// or nil if there is no spot price for them
+	LowestSpotPricePerHour *float64
+}
+
+// Explanation holds the instance types matched by Explain along with the reasons every other instance type was rejected
+type Explanation struct {
+	// InstanceTypes are the detailed specs of the instance types which matched the filters
+	InstanceTypes []*ec2.InstanceTypeInfo
+	// Rejections maps each instance type which did not match to the filters which rejected it
+	Rejections map[string][]FilterRejection
+}
+
+// Diversification holds the instance types suggested by Diversify to diversify an existing list of instance types
+type Diversification struct {
+	// Current are the existing instance types the spec envelope was derived from
+	Current []string
+	// Filters are the envelope of the current instance types merged with the filters passed to Diversify
+	Filters Filters
+	// InstanceTypes are the detailed specs of the instance types within the envelope which are not already current
+	InstanceTypes []*ec2.InstanceTypeInfo
+}
+
+// AuditResults holds which of the candidate instance types passed to Audit matched the filters
+type AuditResults struct {
+	// Passed are the candidate instance types which matched the filters, sorted by name
+	Passed []string
+	// Failed maps each candidate instance type which did not match to the filters which rejected it
+	Failed map[string][]FilterRejection
+	// NotFound are the candidate instance types which are not returned by DescribeInstanceTypes, like a misspelled name
+	NotFound []string
 }
 
 // filterPair holds a tuple of the passed in filter value and the instance resource spec value
@@ -56,74 +287,142 @@ type Filters struct {
 	// Instance type capacity can vary between availability zones.
 	// Will accept zone name or id
 	// Example: us-east-1a, us-east-1b, us-east-2a, etc. OR use1-az1, use2-az2, etc.
-	AvailabilityZone *string
+	AvailabilityZone *string `json:"availabilityZone,omitempty"`
 
 	// BareMetal is used to only return bare metal instance type results
-	BareMetal *bool
+	BareMetal *bool `json:"baremetal,omitempty"`
+
+	// BaseInstanceType is an instance type used as a reference to find similarly spec'd instance types.
+	// Unset vcpus, memory, cpu architecture, gpus, and network performance filters are derived from its specs.
+	// Example: m5.xlarge
+	BaseInstanceType *string `json:"baseInstanceType,omitempty"`
 
 	// Burstable is used to only return burstable instance type results like the t* series
-	Burstable *bool
+	Burstable *bool `json:"burstable,omitempty"`
 
 	// CPUArchitecture of the EC2 instance type
-	// Possible values are: x86_64 or arm64
-	CPUArchitecture *string
+	// Instance types supporting any of the architectures are returned
+	// Possible values are: x86_64, i386, or arm64
+	CPUArchitecture []string `json:"cpuArchitecture,omitempty"`
 
 	// CurrentGeneration returns the latest generation of instance types
-	CurrentGeneration *bool
+	CurrentGeneration *bool `json:"currentGeneration,omitempty"`
 
 	// EnaSupport returns instances that can support an Elastic Network Adapter.
-	EnaSupport *bool
+	EnaSupport *bool `json:"enaSupport,omitempty"`
 
 	// FPGA is used to only return FPGA instance type results
-	Fpga *bool
+	Fpga *bool `json:"fpga,omitempty"`
 
 	// GpusRange filter is a range of acceptable GPU count available to an EC2 instance type
-	GpusRange *IntRangeFilter
+	GpusRange *IntRangeFilter `json:"gpusRange,omitempty"`
 
 	// GpuMemoryRange filter is a range of acceptable GPU memory available to an EC2 instance type in aggreagte across all GPUs.
-	GpuMemoryRange *IntRangeFilter
+	GpuMemoryRange *IntRangeFilter `json:"gpuMemoryRange,omitempty"`
+
+	// GpuMemoryGiBRange filter is the same as GpuMemoryRange but expressed in GiB like GPU datasheets.
+	// It cannot be used with GpuMemoryRange.
+	GpuMemoryGiBRange *IntRangeFilter `json:"gpuMemoryGiBRange,omitempty"`
 
 	// HibernationSupported denotes whether EC2 hibernate is supported
 	// Possible values are: true or false
-	HibernationSupported *bool
+	HibernationSupported *bool `json:"hibernationSupported,omitempty"`
 
 	// Hypervisor is used to return only a specific hypervisor backed instance type
-	// Possibly values are: xen or nitro
-	Hypervisor *string
+	// Instance types using any of the hypervisors are returned
+	// Possible values are: xen or nitro
+	Hypervisor []string `json:"hypervisor,omitempty"`
+
+	// InstanceTypes restricts the results to the listed instance types, like an ASG override list
+	InstanceTypes []string `json:"instanceTypes,omitempty"`
+
+	// MaxResults is the maximum number of instance types to return that match the filter criteria.
+	// The first instance types by SortBy are kept. Without SortBy, current generation instance types with the fewest vcpus
+	// and least memory are kept and returned in name order.
+	MaxResults *int `json:"maxResults,omitempty"`
 
-	// MaxResults is the maximum number of instance types to return that match the filter criteria
-	MaxResults *int
+	// MaxResultsPerFamily is the maximum number of instance types to return from each instance family, like m5 or c5d.
+	// It is applied before MaxResults so that results can be spread across families.
+	MaxResultsPerFamily *int `json:"maxResultsPerFamily,omitempty"`
 
 	// MemoryRange filter is a range of acceptable DRAM memory in Mebibytes (MiB) for the instance type
-	MemoryRange *IntRangeFilter
+	MemoryRange *IntRangeFilter `json:"memoryRange,omitempty"`
 
 	// NetworkInterfaces filter is a range of the number of ENI attachments an instance type can support
-	NetworkInterfaces *IntRangeFilter
+	NetworkInterfaces *IntRangeFilter `json:"networkInterfaces,omitempty"`
 
 	// NetworkPerformance filter is a range of network bandwidth an instance type can support
-	NetworkPerformance *IntRangeFilter
+	NetworkPerformance *IntRangeFilter `json:"networkPerformance,omitempty"`
 
 	// PlacementGroupStrategy is used to return instance types based on its support
 	// for a specific placement group strategy
+	// Instance types supporting any of the strategies are returned
 	// Possible values are: cluster, spread, or partition
-	PlacementGroupStrategy *string
+	PlacementGroupStrategy []string `json:"placementGroupStrategy,omitempty"`
+
+	// RawEC2Filters are EC2 API filters which are passed as is to DescribeInstanceTypes
+	// This allows filtering on any instance type attribute supported by the EC2 API, even if it does not have a filter here
+	// Example: [{Name: "processor-info.sustained-clock-speed-in-ghz", Values: ["3.1"]}]
+	RawEC2Filters []*ec2.Filter `json:"rawEC2Filters,omitempty"`
 
 	// Region is the AWS Region where instances will be provisioned.
 	// Instance type availability can vary between AWS Regions.
 	// Example: us-east-1, us-east-2, eu-west-1, etc.
-	Region *string
+	Region *string `json:"region,omitempty"`
 
 	// RootDeviceType is the backing device of the root storage volume
+	// Instance types supporting any of the root device types are returned
 	// Possible values are: instance-store or ebs
-	RootDeviceType *string
+	RootDeviceType []string `json:"rootDeviceType,omitempty"`
+
+	// Service restricts the results to instance types which another AWS service supports and names them the way the service does
+	// Possible values are: ec2 (the default), ecs, elasticache, rds, sagemaker, sagemaker-training, or sagemaker-inference
+	// Example: sagemaker-training returns ml.p3.2xlarge instead of p3.2xlarge and rds returns db.r5.large instead of r5.large
+	Service *string `json:"service,omitempty"`
+
+	// SortBy is a list of sort keys applied in order before results are truncated to MaxResults
+	// A direction can be appended to each key after a colon, otherwise instance types are sorted in ascending order
+	// Example: ["memory:desc", "vcpus"]
+	SortBy []string `json:"sortBy,omitempty"`
 
 	// UsageClass of the instance EC2 instance type
+	// Instance types supporting any of the usage classes are returned
 	// Possible values are: spot or on-demand
-	UsageClass *string
+	UsageClass []string `json:"usageClass,omitempty"`
 
 	// VCpusRange filter is a range of acceptable VCpus for the instance type
-	VCpusRange *IntRangeFilter
+	VCpusRange *IntRangeFilter `json:"vcpusRange,omitempty"`
 
 	// VcpusToMemoryRatio is a ratio of vcpus to memory expressed as a floating point
-	VCpusToMemoryRatio *float64
+	VCpusToMemoryRatio *float64 `json:"vcpusToMemoryRatio,omitempty"`
+}
+
+// CacheStats counts the lookups of EC2 API responses in the cache of a Selector created with WithCache
+type CacheStats struct {
+	// Hits is the number of lookups served from the cache
+	Hits uint64
+	// Misses is the number of lookups which called the EC2 API because the response was not cached or had expired
+	Misses uint64
+}
+
+// CacheMetadata describes a cache file persisted to a cache dir by a Selector created with WithCacheDir
+type CacheMetadata struct {
+	// Path is the path of the cache file
+	Path string
+	// Region is the region the cached data was retrieved from, or "default" if the region was not known
+	Region string
+	// Partition is the AWS partition of the region, like aws or aws-cn, or empty if the region is not known
+	Partition string
+	// Data is the kind of data cached, either instance-types or offerings
+	Data string
+	// Location is the region or availability zone of cached offerings
+	Location string
+	// InstanceTypes is the number of instance types in the cached data
+	InstanceTypes int
+	// Refreshed is when the data was retrieved from the EC2 API. It is zero for cache files written before it was recorded.
+	Refreshed time.Time
+	// Expiry is when the cached data will be retrieved from the EC2 API again
+	Expiry time.Time
+	// Expired is true if the cached data has expired and will not be used
+	Expired bool
 }
@@ -0,0 +1,122 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector/comparators"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+const (
+	// instanceTypeNameRegex matches instance type names like c5.4xlarge or m5ad.large and captures the series, generation, attributes, and size
+	instanceTypeNameRegex = `^([a-z]+)(\d+)([a-z-]*)\.(.+)$`
+	// processorAttributes are the attributes which only describe the processor manufacturer (amd, graviton, and intel)
+	processorAttributes = "agi"
+)
+
+// instanceTypeName holds the parts of an instance type name
+type instanceTypeName struct {
+	instanceType string
+	series       string
+	generation   int
+	capabilities string
+	size         string
+}
+
+// SuggestNewerGeneration returns the instance types of the same size in newer generations of the same series
+// as the instance type passed in (i.e. c5.4xlarge -> c6i.4xlarge, c6a.4xlarge). Newer generations must offer the same
+// capabilities, like local NVMe storage (d) or enhanced networking (n), but may use a different processor manufacturer.
+// Only instance types supporting one of the cpuArchitectures are suggested. If cpuArchitectures is empty,
+// the cpu architectures of the instance type passed in are used. Suggestions are sorted from the oldest to the newest generation.
+func (itf Selector) SuggestNewerGeneration(instanceType string, cpuArchitectures []string) ([]string, error) {
+	currentName, err := parseInstanceTypeName(instanceType)
+	if err != nil {
+		return nil, err
+	}
+	instanceTypeInfoSlice, err := itf.retrieveInstanceTypes(nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(cpuArchitectures) == 0 {
+		for _, instanceTypeInfo := range instanceTypeInfoSlice {
+			if aws.StringValue(instanceTypeInfo.InstanceType) == instanceType && instanceTypeInfo.ProcessorInfo != nil {
+				cpuArchitectures = aws.StringValueSlice(instanceTypeInfo.ProcessorInfo.SupportedArchitectures)
+			}
+		}
+		if len(cpuArchitectures) == 0 {
+			return nil, newClassifiedError(ErrNotFound, "The instance type %s was not found", instanceType)
+		}
+	}
+	suggestions := []instanceTypeName{}
+	for _, instanceTypeInfo := range instanceTypeInfoSlice {
+		candidateName, err := parseInstanceTypeName(aws.StringValue(instanceTypeInfo.InstanceType))
+		if err != nil {
+			continue
+		}
+		if candidateName.series != currentName.series || candidateName.size != currentName.size ||
+			candidateName.capabilities != currentName.capabilities || candidateName.generation <= currentName.generation {
+			continue
+		}
+		if instanceTypeInfo.ProcessorInfo == nil || !comparators.IsSupportedFromStrings(instanceTypeInfo.ProcessorInfo.SupportedArchitectures, cpuArchitectures) {
+			continue
+		}
+		suggestions = append(suggestions, candidateName)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].generation != suggestions[j].generation {
+			return suggestions[i].generation < suggestions[j].generation
+		}
+		return suggestions[i].instanceType < suggestions[j].instanceType
+	})
+	if len(suggestions) == 0 {
+		return nil, newClassifiedError(ErrNoMatches, "No newer generation instance types were found for %s", instanceType)
+	}
+	suggestedInstanceTypes := []string{}
+	for _, suggestion := range suggestions {
+		suggestedInstanceTypes = append(suggestedInstanceTypes, suggestion.instanceType)
+	}
+	return suggestedInstanceTypes, nil
+}
+
+// parseInstanceTypeName splits an instance type name into its series, generation, capabilities, and size.
+// Processor attributes are removed from the capabilities so that instance types with different processors can be matched.
+func parseInstanceTypeName(instanceType string) (instanceTypeName, error) {
+	matches := regexp.MustCompile(instanceTypeNameRegex).FindStringSubmatch(instanceType)
+	if matches == nil {
+		return instanceTypeName{}, fmt.Errorf("The instance type %s is not a valid instance type name", instanceType)
+	}
+	generation, err := strconv.Atoi(matches[2])
+	if err != nil {
+		return instanceTypeName{}, fmt.Errorf("The instance type %s is not a valid instance type name", instanceType)
+	}
+	capabilities := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(processorAttributes, r) {
+			return -1
+		}
+		return r
+	}, matches[3])
+	return instanceTypeName{
+		instanceType: instanceType,
+		series:       matches[1],
+		generation:   generation,
+		capabilities: capabilities,
+		size:         matches[4],
+	}, nil
+}
@@ -0,0 +1,52 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+)
+
+func TestSuggestNewerGeneration(t *testing.T) {
+	itf := selector.Selector{
+		EC2: setupMock(t, describeInstanceTypes, "25_instances.json"),
+	}
+	suggestions, err := itf.SuggestNewerGeneration("c3.4xlarge", nil)
+	h.Ok(t, err)
+	h.Equals(t, []string{"c4.4xlarge", "c5.4xlarge"}, suggestions)
+
+	_, err = itf.SuggestNewerGeneration("c5.4xlarge", nil)
+	h.Assert(t, errors.Is(err, selector.ErrNoMatches), "Should return ErrNoMatches when there are no newer generations")
+}
+
+func TestSuggestNewerGeneration_CPUArchitecture(t *testing.T) {
+	itf := selector.Selector{
+		EC2: setupMock(t, describeInstanceTypes, "25_instances.json"),
+	}
+	_, err := itf.SuggestNewerGeneration("c3.4xlarge", []string{"arm64"})
+	h.Assert(t, errors.Is(err, selector.ErrNoMatches), "Should return ErrNoMatches when there are no arm64 newer generations")
+}
+
+func TestSuggestNewerGeneration_Invalid(t *testing.T) {
+	itf := selector.Selector{
+		EC2: setupMock(t, describeInstanceTypes, "25_instances.json"),
+	}
+	_, err := itf.SuggestNewerGeneration("c3", nil)
+	h.Nok(t, err)
+	_, err = itf.SuggestNewerGeneration("m5.xlarge", nil)
+	h.Assert(t, errors.Is(err, selector.ErrNotFound), "Should return ErrNotFound for an instance type which does not exist")
+}
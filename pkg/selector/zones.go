@@ -0,0 +1,81 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// AvailabilityZones returns the availability zones of the EC2 client's region with both their name and zone ID, sorted by name.
+// Zone names are mapped to physical zones independently for each account, so us-east-1a in one account can be a different zone
+// than us-east-1a in another. Zone IDs, like use1-az1, identify the same physical zone in every account.
+// Zones which the account has not opted in to, like Local Zones, are included with their opt-in status.
+func (itf Selector) AvailabilityZones() ([]Zone, error) {
+	itf.debugf("calling DescribeAvailabilityZones")
+	zonesOutput, err := itf.EC2.DescribeAvailabilityZones(&ec2.DescribeAvailabilityZonesInput{
+		AllAvailabilityZones: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Encountered an error when describing availability zones: %w", classifyAPIError(err))
+	}
+	zones := []Zone{}
+	for _, zoneInfo := range zonesOutput.AvailabilityZones {
+		zones = append(zones, Zone{
+			Name:        aws.StringValue(zoneInfo.ZoneName),
+			ID:          aws.StringValue(zoneInfo.ZoneId),
+			Region:      aws.StringValue(zoneInfo.RegionName),
+			GroupName:   aws.StringValue(zoneInfo.GroupName),
+			OptInStatus: aws.StringValue(zoneInfo.OptInStatus),
+		})
+	}
+	sort.Slice(zones, func(i, j int) bool {
+		return zones[i].Name < zones[j].Name
+	})
+	return zones, nil
+}
+
+// ResolveZone returns the name and zone ID of the availability zone passed as either a zone name (us-east-1a) or a zone ID (use1-az1)
+// as they are mapped for the account of the EC2 client. An ErrInvalidLocation error is returned if the zone is not in the region
+// or if the account has not opted in to it.
+func (itf Selector) ResolveZone(zone string) (*Zone, error) {
+	zones, err := itf.AvailabilityZones()
+	if err != nil {
+		return nil, err
+	}
+	region := ""
+	for _, zoneInfo := range zones {
+		region = zoneInfo.Region
+		if zoneInfo.Name != zone && zoneInfo.ID != zone {
+			continue
+		}
+		if zoneInfo.OptInStatus == ec2.AvailabilityZoneOptInStatusNotOptedIn {
+			return nil, newClassifiedError(ErrInvalidLocation, "The zone %s is not opted in to for the account. Opt in to the %s zone group to use it", zone, zoneInfo.GroupName)
+		}
+		resolved := zoneInfo
+		return &resolved, nil
+	}
+	return nil, newClassifiedError(ErrInvalidLocation, "The zone %s is not in the region %s. Set the region to the zone's region", zone, region)
+}
+
+// String returns the zone name followed by its zone ID, like us-east-1a (use1-az6)
+func (z Zone) String() string {
+	if z.ID == "" {
+		return z.Name
+	}
+	return fmt.Sprintf("%s (%s)", z.Name, z.ID)
+}
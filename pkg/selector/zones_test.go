@@ -0,0 +1,70 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func usEast2Zones() ec2.DescribeAvailabilityZonesOutput {
+	return ec2.DescribeAvailabilityZonesOutput{
+		AvailabilityZones: []*ec2.AvailabilityZone{
+			{ZoneName: aws.String("us-east-2b"), ZoneId: aws.String("use2-az2"), RegionName: aws.String("us-east-2"), GroupName: aws.String("us-east-2"), OptInStatus: aws.String("opt-in-not-required")},
+			{ZoneName: aws.String("us-east-2a"), ZoneId: aws.String("use2-az1"), RegionName: aws.String("us-east-2"), GroupName: aws.String("us-east-2"), OptInStatus: aws.String("opt-in-not-required")},
+		},
+	}
+}
+
+func TestAvailabilityZones(t *testing.T) {
+	itf := selector.Selector{
+		EC2: mockedEC2{DescribeAvailabilityZonesResp: usEast2Zones()},
+	}
+	zones, err := itf.AvailabilityZones()
+	h.Ok(t, err)
+	h.Equals(t, 2, len(zones))
+	h.Equals(t, "us-east-2a", zones[0].Name)
+	h.Equals(t, "use2-az1", zones[0].ID)
+	h.Equals(t, "us-east-2a (use2-az1)", zones[0].String())
+}
+
+func TestAvailabilityZones_Error(t *testing.T) {
+	itf := selector.Selector{
+		EC2: mockedEC2{DescribeAvailabilityZonesErr: errors.New("error")},
+	}
+	zones, err := itf.AvailabilityZones()
+	h.Nok(t, err)
+	h.Assert(t, zones == nil, "Zones should be nil")
+}
+
+func TestResolveZone(t *testing.T) {
+	itf := selector.Selector{
+		EC2: mockedEC2{DescribeAvailabilityZonesResp: usEast2Zones()},
+	}
+	zone, err := itf.ResolveZone("us-east-2b")
+	h.Ok(t, err)
+	h.Equals(t, "use2-az2", zone.ID)
+
+	zone, err = itf.ResolveZone("use2-az1")
+	h.Ok(t, err)
+	h.Equals(t, "us-east-2a", zone.Name)
+
+	_, err = itf.ResolveZone("us-east-1a")
+	h.Assert(t, errors.Is(err, selector.ErrInvalidLocation), "Should return ErrInvalidLocation")
+}
@@ -0,0 +1,147 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	metricsNamespace = "ec2_instance_selector"
+	// otherPolicy is the policy label value of the policies which were not passed to NewMetrics
+	otherPolicy = "other"
+)
+
+// Metrics holds the Prometheus metrics of the server
+type Metrics struct {
+	registry             *prometheus.Registry
+	requests             *prometheus.CounterVec
+	requestDuration      *prometheus.HistogramVec
+	throttles            *prometheus.CounterVec
+	matchedInstanceTypes *prometheus.GaugeVec
+	policies             map[string]bool
+}
+
+// NewMetrics creates the server metrics in a new registry.
+// cacheStats is called on each scrape to report the cache hits and misses of the selector.
+// The matching instance types are reported by the policy names of requests only for the policies,
+// and as "other" for any other policy so that clients cannot create an unbounded number of series.
+func NewMetrics(cacheStats func() selector.CacheStats, policies []string) *Metrics {
+	m := &Metrics{
+		policies: map[string]bool{},
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "grpc_requests_total",
+			Help:      "Number of gRPC requests by method and status code",
+		}, []string{"method", "code"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "grpc_request_duration_seconds",
+			Help:      "Latency of gRPC requests by method",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+		throttles: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "ec2_api_throttles_total",
+			Help:      "Number of EC2 API calls which were throttled by operation",
+		}, []string{"operation"}),
+		matchedInstanceTypes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "matched_instance_types",
+			Help:      "Number of instance types which matched the filters of the last Filter request of each policy",
+		}, []string{"policy"}),
+	}
+	for _, policy := range policies {
+		m.policies[policy] = true
+	}
+	m.registry.MustRegister(
+		m.requests,
+		m.requestDuration,
+		m.throttles,
+		m.matchedInstanceTypes,
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "cache_hits_total",
+			Help:      "Number of EC2 API responses served from the cache",
+		}, func() float64 { return float64(cacheStats().Hits) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "cache_misses_total",
+			Help:      "Number of EC2 API responses which were not cached or had expired",
+		}, func() float64 { return float64(cacheStats().Misses) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "cache_hit_ratio",
+			Help:      "Ratio of EC2 API responses served from the cache since the server started",
+		}, func() float64 { return cacheHitRatio(cacheStats()) }),
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Handler returns an HTTP handler serving the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// InstrumentHandlers counts the throttled attempts of the requests made by an AWS SDK client, like the EC2 client of the selector
+func (m *Metrics) InstrumentHandlers(handlers *request.Handlers) {
+	handlers.CompleteAttempt.PushBack(func(r *request.Request) {
+		if r.Error != nil && request.IsErrorThrottle(r.Error) {
+			m.throttles.WithLabelValues(r.Operation.Name).Inc()
+		}
+	})
+}
+
+// UnaryServerInterceptor returns a gRPC interceptor which records the count and latency of requests
+func (m *Metrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.requestDuration.WithLabelValues(info.FullMethod).Observe(time.Since(start).Seconds())
+		m.requests.WithLabelValues(info.FullMethod, status.Code(err).String()).Inc()
+		return resp, err
+	}
+}
+
+// recordMatches records the number of instance types which matched the filters of a policy
+func (m *Metrics) recordMatches(policy string, matches int) {
+	if m == nil || policy == "" {
+		return
+	}
+	if !m.policies[policy] {
+		policy = otherPolicy
+	}
+	m.matchedInstanceTypes.WithLabelValues(policy).Set(float64(matches))
+}
+
+// cacheHitRatio returns the ratio of hits to lookups, or 0 if there have not been any lookups
+func cacheHitRatio(stats selector.CacheStats) float64 {
+	lookups := stats.Hits + stats.Misses
+	if lookups == 0 {
+		return 0
+	}
+	return float64(stats.Hits) / float64(lookups)
+}
@@ -0,0 +1,100 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	"github.com/aws/amazon-ec2-instance-selector/pkg/server"
+	"github.com/aws/amazon-ec2-instance-selector/pkg/server/selectorpb"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Helpers
+
+// scrapeMetrics returns the metrics in the Prometheus exposition format
+func scrapeMetrics(t *testing.T, metrics *server.Metrics) string {
+	httpServer := httptest.NewServer(metrics.Handler())
+	defer httpServer.Close()
+	resp, err := httpServer.Client().Get(httpServer.URL)
+	h.Ok(t, err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	h.Ok(t, err)
+	return string(body)
+}
+
+// Tests
+
+func TestMetrics(t *testing.T) {
+	metrics := server.NewMetrics(func() selector.CacheStats { return selector.CacheStats{Hits: 3, Misses: 1} }, []string{"web", "batch"})
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := server.NewInstrumentedGRPCServer(&mockedInstanceSelector{
+		details: []selector.InstanceTypeDetails{{InstanceType: "m5.large"}, {InstanceType: "m5a.large"}},
+	}, metrics)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+	conn, err := grpc.Dial("bufconn", grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	h.Ok(t, err)
+	defer conn.Close()
+	client := selectorpb.NewInstanceSelectorClient(conn)
+	_, err = client.Filter(context.Background(), &selectorpb.FilterRequest{Filters: "maxResults: 2", Policy: "web"})
+	h.Ok(t, err)
+	_, err = client.Filter(context.Background(), &selectorpb.FilterRequest{Filters: "unknownFilter: true"})
+	h.Nok(t, err)
+	for _, policy := range []string{"unknown-1", "unknown-2"} {
+		_, err = client.Filter(context.Background(), &selectorpb.FilterRequest{Filters: "maxResults: 2", Policy: policy})
+		h.Ok(t, err)
+	}
+
+	scraped := scrapeMetrics(t, metrics)
+	for _, expected := range []string{
+		`ec2_instance_selector_grpc_requests_total{code="OK",method="/ec2instanceselector.v1.InstanceSelector/Filter"} 3`,
+		`ec2_instance_selector_grpc_requests_total{code="InvalidArgument",method="/ec2instanceselector.v1.InstanceSelector/Filter"} 1`,
+		`ec2_instance_selector_grpc_request_duration_seconds_count{method="/ec2instanceselector.v1.InstanceSelector/Filter"} 4`,
+		`ec2_instance_selector_matched_instance_types{policy="web"} 2`,
+		`ec2_instance_selector_matched_instance_types{policy="other"} 2`,
+		`ec2_instance_selector_cache_hits_total 3`,
+		`ec2_instance_selector_cache_misses_total 1`,
+		`ec2_instance_selector_cache_hit_ratio 0.75`,
+	} {
+		h.Assert(t, strings.Contains(scraped, expected), "Metrics should contain %s but were:\n%s", expected, scraped)
+	}
+	h.Assert(t, !strings.Contains(scraped, "unknown-1"), "Policies which were not passed to NewMetrics should be reported as other")
+}
+
+func TestMetrics_InstrumentHandlers(t *testing.T) {
+	metrics := server.NewMetrics(func() selector.CacheStats { return selector.CacheStats{} }, nil)
+	handlers := request.Handlers{}
+	metrics.InstrumentHandlers(&handlers)
+	handlers.CompleteAttempt.Run(&request.Request{Operation: &request.Operation{Name: "DescribeInstanceTypes"}, Error: awserr.New("Throttling", "Rate exceeded", nil)})
+	handlers.CompleteAttempt.Run(&request.Request{Operation: &request.Operation{Name: "DescribeInstanceTypes"}, Error: awserr.New("UnauthorizedOperation", "", nil)})
+	handlers.CompleteAttempt.Run(&request.Request{Operation: &request.Operation{Name: "DescribeInstanceTypeOfferings"}})
+
+	scraped := scrapeMetrics(t, metrics)
+	h.Assert(t, strings.Contains(scraped, `ec2_instance_selector_ec2_api_throttles_total{operation="DescribeInstanceTypes"} 1`), "Throttles should be counted but were:\n%s", scraped)
+	h.Assert(t, strings.Contains(scraped, `ec2_instance_selector_cache_hit_ratio 0`), "Cache hit ratio should be 0 without lookups")
+}
@@ -0,0 +1,179 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package selectorpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// InstanceSelectorClient is the client API for InstanceSelector service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type InstanceSelectorClient interface {
+	// Filter returns the instance types matching the filters
+	Filter(ctx context.Context, in *FilterRequest, opts ...grpc.CallOption) (*FilterResponse, error)
+	// Describe returns the full specs of an instance type and the availability zones offering it
+	Describe(ctx context.Context, in *DescribeRequest, opts ...grpc.CallOption) (*DescribeResponse, error)
+	// Compare returns a spec-by-spec comparison of two instance types
+	Compare(ctx context.Context, in *CompareRequest, opts ...grpc.CallOption) (*CompareResponse, error)
+}
+
+type instanceSelectorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewInstanceSelectorClient(cc grpc.ClientConnInterface) InstanceSelectorClient {
+	return &instanceSelectorClient{cc}
+}
+
+func (c *instanceSelectorClient) Filter(ctx context.Context, in *FilterRequest, opts ...grpc.CallOption) (*FilterResponse, error) {
+	out := new(FilterResponse)
+	err := c.cc.Invoke(ctx, "/ec2instanceselector.v1.InstanceSelector/Filter", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *instanceSelectorClient) Describe(ctx context.Context, in *DescribeRequest, opts ...grpc.CallOption) (*DescribeResponse, error) {
+	out := new(DescribeResponse)
+	err := c.cc.Invoke(ctx, "/ec2instanceselector.v1.InstanceSelector/Describe", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *instanceSelectorClient) Compare(ctx context.Context, in *CompareRequest, opts ...grpc.CallOption) (*CompareResponse, error) {
+	out := new(CompareResponse)
+	err := c.cc.Invoke(ctx, "/ec2instanceselector.v1.InstanceSelector/Compare", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// InstanceSelectorServer is the server API for InstanceSelector service.
+// All implementations must embed UnimplementedInstanceSelectorServer
+// for forward compatibility
+type InstanceSelectorServer interface {
+	// Filter returns the instance types matching the filters
+	Filter(context.Context, *FilterRequest) (*FilterResponse, error)
+	// Describe returns the full specs of an instance type and the availability zones offering it
+	Describe(context.Context, *DescribeRequest) (*DescribeResponse, error)
+	// Compare returns a spec-by-spec comparison of two instance types
+	Compare(context.Context, *CompareRequest) (*CompareResponse, error)
+	mustEmbedUnimplementedInstanceSelectorServer()
+}
+
+// UnimplementedInstanceSelectorServer must be embedded to have forward compatible implementations.
+type UnimplementedInstanceSelectorServer struct {
+}
+
+func (UnimplementedInstanceSelectorServer) Filter(context.Context, *FilterRequest) (*FilterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Filter not implemented")
+}
+func (UnimplementedInstanceSelectorServer) Describe(context.Context, *DescribeRequest) (*DescribeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Describe not implemented")
+}
+func (UnimplementedInstanceSelectorServer) Compare(context.Context, *CompareRequest) (*CompareResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Compare not implemented")
+}
+func (UnimplementedInstanceSelectorServer) mustEmbedUnimplementedInstanceSelectorServer() {}
+
+// UnsafeInstanceSelectorServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to InstanceSelectorServer will
+// result in compilation errors.
+type UnsafeInstanceSelectorServer interface {
+	mustEmbedUnimplementedInstanceSelectorServer()
+}
+
+func RegisterInstanceSelectorServer(s grpc.ServiceRegistrar, srv InstanceSelectorServer) {
+	s.RegisterService(&InstanceSelector_ServiceDesc, srv)
+}
+
+func _InstanceSelector_Filter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FilterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InstanceSelectorServer).Filter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ec2instanceselector.v1.InstanceSelector/Filter",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InstanceSelectorServer).Filter(ctx, req.(*FilterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InstanceSelector_Describe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DescribeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InstanceSelectorServer).Describe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ec2instanceselector.v1.InstanceSelector/Describe",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InstanceSelectorServer).Describe(ctx, req.(*DescribeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InstanceSelector_Compare_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompareRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InstanceSelectorServer).Compare(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ec2instanceselector.v1.InstanceSelector/Compare",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InstanceSelectorServer).Compare(ctx, req.(*CompareRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// InstanceSelector_ServiceDesc is the grpc.ServiceDesc for InstanceSelector service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var InstanceSelector_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ec2instanceselector.v1.InstanceSelector",
+	HandlerType: (*InstanceSelectorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Filter",
+			Handler:    _InstanceSelector_Filter_Handler,
+		},
+		{
+			MethodName: "Describe",
+			Handler:    _InstanceSelector_Describe_Handler,
+		},
+		{
+			MethodName: "Compare",
+			Handler:    _InstanceSelector_Compare_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/server/selectorpb/selector.proto",
+}
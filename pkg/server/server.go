@@ -0,0 +1,148 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package server provides a gRPC server for filtering, describing, and comparing instance types
+// so that non-Go consumers can use the selector without shelling out to the CLI.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	"github.com/aws/amazon-ec2-instance-selector/pkg/server/selectorpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// InstanceSelector is the subset of selector.Selector used by the server
+type InstanceSelector interface {
+	FilterDetailed(filters selector.Filters) ([]selector.InstanceTypeDetails, error)
+	GetInstanceTypeDetails(instanceType string) (*selector.InstanceTypeLookup, error)
+	Compare(instanceTypeA string, instanceTypeB string) (*selector.Comparison, error)
+}
+
+// Server implements the InstanceSelector gRPC service
+type Server struct {
+	selectorpb.UnimplementedInstanceSelectorServer
+	instanceSelector InstanceSelector
+	// metrics is optional and records the instance types matched by each policy
+	metrics *Metrics
+}
+
+// New creates a Server which serves requests with the instanceSelector
+func New(instanceSelector InstanceSelector) *Server {
+	return &Server{instanceSelector: instanceSelector}
+}
+
+// NewGRPCServer creates a gRPC server with the InstanceSelector service registered
+func NewGRPCServer(instanceSelector InstanceSelector, opts ...grpc.ServerOption) *grpc.Server {
+	grpcServer := grpc.NewServer(opts...)
+	selectorpb.RegisterInstanceSelectorServer(grpcServer, New(instanceSelector))
+	return grpcServer
+}
+
+// NewInstrumentedGRPCServer creates a gRPC server with the InstanceSelector service registered which records its requests in the metrics
+func NewInstrumentedGRPCServer(instanceSelector InstanceSelector, metrics *Metrics, opts ...grpc.ServerOption) *grpc.Server {
+	grpcServer := grpc.NewServer(append(opts, grpc.UnaryInterceptor(metrics.UnaryServerInterceptor()))...)
+	selectorpb.RegisterInstanceSelectorServer(grpcServer, &Server{instanceSelector: instanceSelector, metrics: metrics})
+	return grpcServer
+}
+
+// Filter returns the instance types matching the filters in the request
+func (s *Server) Filter(ctx context.Context, req *selectorpb.FilterRequest) (*selectorpb.FilterResponse, error) {
+	filters, err := selector.ParseFilters([]byte(req.Filters))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Unable to parse filters: %v", err)
+	}
+	instanceTypeDetails, err := s.instanceSelector.FilterDetailed(filters)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	s.metrics.recordMatches(req.Policy, len(instanceTypeDetails))
+	resp := &selectorpb.FilterResponse{InstanceTypes: []*selectorpb.InstanceTypeDetails{}}
+	for _, details := range instanceTypeDetails {
+		resp.InstanceTypes = append(resp.InstanceTypes, &selectorpb.InstanceTypeDetails{
+			InstanceType:       details.InstanceType,
+			Vcpus:              int32(details.VCpus),
+			MemoryMib:          int32(details.MemoryMiB),
+			CpuArchitectures:   details.CPUArchitectures,
+			Gpus:               int32(details.Gpus),
+			GpuMemoryMib:       int32(details.GpuMemoryMiB),
+			NetworkPerformance: details.NetworkPerformance,
+			NetworkInterfaces:  int32(details.NetworkInterfaces),
+			Hypervisor:         details.Hypervisor,
+			CurrentGeneration:  details.CurrentGeneration,
+			Burstable:          details.Burstable,
+			BareMetal:          details.BareMetal,
+			Locations:          details.Locations,
+		})
+	}
+	return resp, nil
+}
+
+// Describe returns the full specs of the instance type in the request and the availability zones offering it
+func (s *Server) Describe(ctx context.Context, req *selectorpb.DescribeRequest) (*selectorpb.DescribeResponse, error) {
+	lookup, err := s.instanceSelector.GetInstanceTypeDetails(req.InstanceType)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	instanceTypeInfoJSON, err := json.Marshal(lookup.InstanceTypeInfo)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Unable to convert instance type info to JSON: %v", err)
+	}
+	return &selectorpb.DescribeResponse{
+		InstanceTypeInfo:  string(instanceTypeInfoJSON),
+		AvailabilityZones: lookup.AvailabilityZones,
+	}, nil
+}
+
+// Compare returns a spec-by-spec comparison of the two instance types in the request
+func (s *Server) Compare(ctx context.Context, req *selectorpb.CompareRequest) (*selectorpb.CompareResponse, error) {
+	comparison, err := s.instanceSelector.Compare(req.InstanceTypeA, req.InstanceTypeB)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	resp := &selectorpb.CompareResponse{
+		InstanceTypeA: comparison.InstanceTypeA,
+		InstanceTypeB: comparison.InstanceTypeB,
+		Specs:         []*selectorpb.SpecComparison{},
+	}
+	for _, spec := range comparison.Specs {
+		resp.Specs = append(resp.Specs, &selectorpb.SpecComparison{
+			Spec:      spec.Spec,
+			A:         spec.A,
+			B:         spec.B,
+			Different: spec.Different,
+		})
+	}
+	return resp, nil
+}
+
+// statusFromError converts the sentinel errors returned by the selector into gRPC status codes
+func statusFromError(err error) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, selector.ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, selector.ErrInvalidFilters), errors.Is(err, selector.ErrInvalidLocation):
+		code = codes.InvalidArgument
+	case errors.Is(err, selector.ErrThrottled):
+		code = codes.ResourceExhausted
+	case errors.Is(err, selector.ErrUnauthorized):
+		code = codes.PermissionDenied
+	}
+	return status.Error(code, err.Error())
+}
@@ -0,0 +1,142 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	"github.com/aws/amazon-ec2-instance-selector/pkg/server"
+	"github.com/aws/amazon-ec2-instance-selector/pkg/server/selectorpb"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Helpers
+
+type mockedInstanceSelector struct {
+	filters selector.Filters
+	details []selector.InstanceTypeDetails
+	lookup  *selector.InstanceTypeLookup
+	err     error
+}
+
+func (m *mockedInstanceSelector) FilterDetailed(filters selector.Filters) ([]selector.InstanceTypeDetails, error) {
+	m.filters = filters
+	return m.details, m.err
+}
+
+func (m *mockedInstanceSelector) GetInstanceTypeDetails(instanceType string) (*selector.InstanceTypeLookup, error) {
+	return m.lookup, m.err
+}
+
+func (m *mockedInstanceSelector) Compare(instanceTypeA string, instanceTypeB string) (*selector.Comparison, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &selector.Comparison{
+		InstanceTypeA: instanceTypeA,
+		InstanceTypeB: instanceTypeB,
+		Specs:         []selector.SpecComparison{{Spec: "vcpus", A: "2", B: "4", Different: true}},
+	}, nil
+}
+
+// dialServer starts a gRPC server over an in-memory listener and returns a client connected to it
+func dialServer(t *testing.T, instanceSelector server.InstanceSelector) (selectorpb.InstanceSelectorClient, func()) {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := server.NewGRPCServer(instanceSelector)
+	go grpcServer.Serve(listener)
+	conn, err := grpc.Dial("bufconn", grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	h.Ok(t, err)
+	return selectorpb.NewInstanceSelectorClient(conn), func() {
+		conn.Close()
+		grpcServer.Stop()
+	}
+}
+
+// Tests
+
+func TestFilter(t *testing.T) {
+	instanceSelector := &mockedInstanceSelector{
+		details: []selector.InstanceTypeDetails{{InstanceType: "t3.micro", VCpus: 2, MemoryMiB: 1024, CPUArchitectures: []string{"x86_64"}, Burstable: true}},
+	}
+	client, stop := dialServer(t, instanceSelector)
+	defer stop()
+	resp, err := client.Filter(context.Background(), &selectorpb.FilterRequest{Filters: "vcpusRange: {lowerBound: 2, upperBound: 2}"})
+	h.Ok(t, err)
+	h.Equals(t, 2, instanceSelector.filters.VCpusRange.LowerBound)
+	h.Equals(t, 1, len(resp.InstanceTypes))
+	h.Equals(t, "t3.micro", resp.InstanceTypes[0].InstanceType)
+	h.Equals(t, int32(1024), resp.InstanceTypes[0].MemoryMib)
+	h.Assert(t, resp.InstanceTypes[0].Burstable, "t3.micro should be burstable")
+}
+
+func TestFilter_InvalidFilters(t *testing.T) {
+	client, stop := dialServer(t, &mockedInstanceSelector{})
+	defer stop()
+	_, err := client.Filter(context.Background(), &selectorpb.FilterRequest{Filters: "unknownFilter: true"})
+	h.Equals(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestDescribe(t *testing.T) {
+	instanceSelector := &mockedInstanceSelector{
+		lookup: &selector.InstanceTypeLookup{
+			InstanceTypeInfo:  &ec2.InstanceTypeInfo{InstanceType: aws.String("m5.large")},
+			AvailabilityZones: []string{"us-east-1a", "us-east-1b"},
+		},
+	}
+	client, stop := dialServer(t, instanceSelector)
+	defer stop()
+	resp, err := client.Describe(context.Background(), &selectorpb.DescribeRequest{InstanceType: "m5.large"})
+	h.Ok(t, err)
+	h.Assert(t, strings.Contains(resp.InstanceTypeInfo, `"InstanceType":"m5.large"`), "Should return the instance type info as JSON: %s", resp.InstanceTypeInfo)
+	h.Equals(t, []string{"us-east-1a", "us-east-1b"}, resp.AvailabilityZones)
+}
+
+func TestCompare(t *testing.T) {
+	client, stop := dialServer(t, &mockedInstanceSelector{})
+	defer stop()
+	resp, err := client.Compare(context.Background(), &selectorpb.CompareRequest{InstanceTypeA: "m5.large", InstanceTypeB: "m5.xlarge"})
+	h.Ok(t, err)
+	h.Equals(t, "m5.large", resp.InstanceTypeA)
+	h.Equals(t, 1, len(resp.Specs))
+	h.Assert(t, resp.Specs[0].Different, "vcpus should be different")
+}
+
+func TestErrorCodes(t *testing.T) {
+	errorCodes := map[error]codes.Code{
+		selector.ErrNotFound:        codes.NotFound,
+		selector.ErrInvalidLocation: codes.InvalidArgument,
+		selector.ErrThrottled:       codes.ResourceExhausted,
+		selector.ErrUnauthorized:    codes.PermissionDenied,
+		fmt.Errorf("error"):         codes.Internal,
+	}
+	for selectorErr, code := range errorCodes {
+		client, stop := dialServer(t, &mockedInstanceSelector{err: fmt.Errorf("wrapped: %w", selectorErr)})
+		_, err := client.Describe(context.Background(), &selectorpb.DescribeRequest{InstanceType: "m5.large"})
+		stop()
+		h.Equals(t, code, status.Code(err))
+	}
+}
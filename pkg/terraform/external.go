@@ -0,0 +1,81 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package terraform implements the protocol of the Terraform external data source
+// so that Terraform modules can filter instance types at plan time.
+// See https://registry.terraform.io/providers/hashicorp/external/latest/docs/data-sources/data_source
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+)
+
+// Query keys
+const (
+	// QueryFilters is a YAML or JSON document of filters in the same format as the --filters-file flag
+	QueryFilters = "filters"
+	// QuerySeparator joins the matching instance types in the result (default ",")
+	QuerySeparator = "separator"
+)
+
+// Result keys
+const (
+	// ResultInstanceTypes is the matching instance types joined by the separator
+	ResultInstanceTypes = "instance_types"
+	// ResultCount is the number of matching instance types
+	ResultCount = "count"
+)
+
+const defaultSeparator = ","
+
+// InstanceSelector is the subset of selector.Selector used by the external data source
+type InstanceSelector interface {
+	Filter(filters selector.Filters) ([]string, error)
+}
+
+// Run reads the query JSON object from in, filters instance types, and writes the result JSON object to out.
+// The external data source protocol only allows string values so the instance types are joined into one string
+// which can be split in Terraform with split(",", data.external.instance_types.result.instance_types).
+func Run(instanceSelector InstanceSelector, in io.Reader, out io.Writer) error {
+	query := map[string]string{}
+	if err := json.NewDecoder(in).Decode(&query); err != nil {
+		return fmt.Errorf("Unable to parse the query, it must be a JSON object of strings: %w", err)
+	}
+	for key := range query {
+		if key != QueryFilters && key != QuerySeparator {
+			return fmt.Errorf("Unknown query key %s, the supported keys are: [%s]", key, strings.Join([]string{QueryFilters, QuerySeparator}, ", "))
+		}
+	}
+	filters, err := selector.ParseFilters([]byte(query[QueryFilters]))
+	if err != nil {
+		return fmt.Errorf("Unable to parse the %s query: %w", QueryFilters, err)
+	}
+	separator, ok := query[QuerySeparator]
+	if !ok {
+		separator = defaultSeparator
+	}
+	instanceTypes, err := instanceSelector.Filter(filters)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(out).Encode(map[string]string{
+		ResultInstanceTypes: strings.Join(instanceTypes, separator),
+		ResultCount:         strconv.Itoa(len(instanceTypes)),
+	})
+}
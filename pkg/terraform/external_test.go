@@ -0,0 +1,70 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package terraform_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	"github.com/aws/amazon-ec2-instance-selector/pkg/terraform"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+)
+
+// Helpers
+
+type mockedInstanceSelector struct {
+	instanceTypes []string
+	err           error
+	filters       selector.Filters
+}
+
+func (m *mockedInstanceSelector) Filter(filters selector.Filters) ([]string, error) {
+	m.filters = filters
+	return m.instanceTypes, m.err
+}
+
+// Tests
+
+func TestRun(t *testing.T) {
+	instanceSelector := &mockedInstanceSelector{instanceTypes: []string{"m5.large", "m5a.large"}}
+	out := bytes.Buffer{}
+	err := terraform.Run(instanceSelector, strings.NewReader(`{"filters": "{\"vcpusRange\": {\"lowerBound\": 2, \"upperBound\": 2}}"}`), &out)
+	h.Ok(t, err)
+	h.Equals(t, `{"count":"2","instance_types":"m5.large,m5a.large"}`+"\n", out.String())
+	h.Equals(t, selector.IntRangeFilter{LowerBound: 2, UpperBound: 2}, *instanceSelector.filters.VCpusRange)
+}
+
+func TestRun_Separator(t *testing.T) {
+	out := bytes.Buffer{}
+	err := terraform.Run(&mockedInstanceSelector{instanceTypes: []string{"m5.large", "m5a.large"}}, strings.NewReader(`{"separator": " "}`), &out)
+	h.Ok(t, err)
+	h.Equals(t, `{"count":"2","instance_types":"m5.large m5a.large"}`+"\n", out.String())
+}
+
+func TestRun_InvalidQuery(t *testing.T) {
+	for _, query := range []string{`not json`, `{"filters": {"vcpus": 2}}`, `{"vcpus": "2"}`, `{"filters": "unknownFilter: true"}`} {
+		err := terraform.Run(&mockedInstanceSelector{}, strings.NewReader(query), &bytes.Buffer{})
+		h.Nok(t, err)
+	}
+}
+
+func TestRun_FilterError(t *testing.T) {
+	out := bytes.Buffer{}
+	err := terraform.Run(&mockedInstanceSelector{err: errors.New("throttled")}, strings.NewReader(`{}`), &out)
+	h.Nok(t, err)
+	h.Equals(t, "", out.String())
+}
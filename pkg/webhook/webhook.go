@@ -0,0 +1,208 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package webhook provides a Kubernetes validating admission webhook which rejects manifests referencing instance types
+// that do not match an organization's filters, like Karpenter NodePools or Auto Scaling group custom resources.
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/karpenter"
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+)
+
+const (
+	admissionAPIVersion = "admission.k8s.io/v1"
+	admissionKind       = "AdmissionReview"
+	// instanceTypeField and instanceTypesField are the field names which reference instance types in manifests other than
+	// Karpenter's, like the overrides of an Auto Scaling group or the instance types of an EKS nodegroup
+	instanceTypeField  = "instanceType"
+	instanceTypesField = "instanceTypes"
+)
+
+// InstanceSelector is the subset of selector.Selector used by the webhook
+type InstanceSelector interface {
+	Audit(filters selector.Filters, candidates []string) (*selector.AuditResults, error)
+}
+
+// AdmissionReview is the admission.k8s.io/v1 AdmissionReview sent by the Kubernetes API server and returned with a response
+type AdmissionReview struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Request    *AdmissionRequest  `json:"request,omitempty"`
+	Response   *AdmissionResponse `json:"response,omitempty"`
+}
+
+// AdmissionRequest holds the fields of an admission request used by the webhook
+type AdmissionRequest struct {
+	UID       string           `json:"uid"`
+	Kind      GroupVersionKind `json:"kind"`
+	Name      string           `json:"name,omitempty"`
+	Namespace string           `json:"namespace,omitempty"`
+	Operation string           `json:"operation"`
+	Object    json.RawMessage  `json:"object,omitempty"`
+}
+
+// GroupVersionKind identifies the kind of the object being admitted
+type GroupVersionKind struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+// AdmissionResponse allows or rejects the object of an AdmissionRequest
+type AdmissionResponse struct {
+	UID     string  `json:"uid"`
+	Allowed bool    `json:"allowed"`
+	Result  *Status `json:"status,omitempty"`
+}
+
+// Status explains why an object was rejected
+type Status struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+// Webhook validates admission requests against the filters
+type Webhook struct {
+	instanceSelector InstanceSelector
+	filters          selector.Filters
+}
+
+// New creates a Webhook which rejects objects referencing instance types that do not match the filters
+func New(instanceSelector InstanceSelector, filters selector.Filters) *Webhook {
+	return &Webhook{
+		instanceSelector: instanceSelector,
+		filters:          filters,
+	}
+}
+
+// ServeHTTP decodes an AdmissionReview, validates its request, and writes the AdmissionReview with the response
+func (w *Webhook) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	review := AdmissionReview{}
+	if err := json.NewDecoder(req.Body).Decode(&review); err != nil || review.Request == nil {
+		http.Error(rw, fmt.Sprintf("Unable to decode the %s", admissionKind), http.StatusBadRequest)
+		return
+	}
+	response := AdmissionReview{
+		APIVersion: admissionAPIVersion,
+		Kind:       admissionKind,
+		Response:   w.Review(review.Request),
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(response); err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Review allows the request's object if every instance type it references matches the filters.
+// Objects which do not reference any instance types are allowed.
+func (w *Webhook) Review(request *AdmissionRequest) *AdmissionResponse {
+	response := &AdmissionResponse{UID: request.UID}
+	instanceTypes, err := InstanceTypes(request.Kind.Kind, request.Object)
+	if err != nil {
+		response.Result = &Status{Code: http.StatusBadRequest, Message: fmt.Sprintf("Unable to read the instance types of %s %s: %v", request.Kind.Kind, request.Name, err)}
+		return response
+	}
+	if len(instanceTypes) == 0 {
+		response.Allowed = true
+		return response
+	}
+	auditResults, err := w.instanceSelector.Audit(w.filters, instanceTypes)
+	if err != nil {
+		response.Result = &Status{Code: http.StatusInternalServerError, Message: fmt.Sprintf("Unable to check the instance types of %s %s: %v", request.Kind.Kind, request.Name, err)}
+		return response
+	}
+	disallowed := []string{}
+	for _, instanceType := range instanceTypes {
+		if rejections, ok := auditResults.Failed[instanceType]; ok && len(rejections) != 0 {
+			disallowed = append(disallowed, fmt.Sprintf("%s (%s)", instanceType, rejections[0].Filter))
+		}
+	}
+	for _, instanceType := range auditResults.NotFound {
+		disallowed = append(disallowed, fmt.Sprintf("%s (not found)", instanceType))
+	}
+	if len(disallowed) != 0 {
+		sort.Strings(disallowed)
+		response.Result = &Status{Code: http.StatusForbidden, Message: fmt.Sprintf("%s %s references instance types which are not allowed by the instance type policy: %s", request.Kind.Kind, request.Name, strings.Join(disallowed, ", "))}
+		return response
+	}
+	response.Allowed = true
+	return response
+}
+
+// InstanceTypes returns the sorted instance types an object references. The instance types of Karpenter NodePools and Provisioners
+// are read from their node.kubernetes.io/instance-type requirements with the In operator. The instance types of other kinds
+// are read from every instanceType and instanceTypes field, like the overrides of an Auto Scaling group custom resource.
+func InstanceTypes(kind string, object []byte) ([]string, error) {
+	instanceTypes := map[string]bool{}
+	if kind == karpenter.KindNodePool || kind == karpenter.KindProvisioner {
+		requirements, err := karpenter.ParseNodePool(object)
+		if err != nil {
+			return nil, err
+		}
+		for _, requirement := range requirements {
+			if requirement.Key == karpenter.LabelInstanceType && requirement.Operator == karpenter.OperatorIn {
+				for _, value := range requirement.Values {
+					instanceTypes[value] = true
+				}
+			}
+		}
+	} else {
+		var fields interface{}
+		if err := json.Unmarshal(object, &fields); err != nil {
+			return nil, err
+		}
+		collectInstanceTypes(fields, instanceTypes)
+	}
+	sorted := []string{}
+	for instanceType := range instanceTypes {
+		sorted = append(sorted, instanceType)
+	}
+	sort.Strings(sorted)
+	return sorted, nil
+}
+
+// collectInstanceTypes walks the decoded json value and adds the values of instanceType and instanceTypes fields to instanceTypes
+func collectInstanceTypes(value interface{}, instanceTypes map[string]bool) {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, field := range typed {
+			switch {
+			case key == instanceTypeField:
+				if instanceType, ok := field.(string); ok && instanceType != "" {
+					instanceTypes[instanceType] = true
+				}
+			case key == instanceTypesField:
+				if list, ok := field.([]interface{}); ok {
+					for _, item := range list {
+						if instanceType, ok := item.(string); ok && instanceType != "" {
+							instanceTypes[instanceType] = true
+						}
+					}
+				}
+			default:
+				collectInstanceTypes(field, instanceTypes)
+			}
+		}
+	case []interface{}:
+		for _, item := range typed {
+			collectInstanceTypes(item, instanceTypes)
+		}
+	}
+}
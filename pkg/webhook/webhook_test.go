@@ -0,0 +1,140 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package webhook_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/pkg/test"
+	"github.com/aws/amazon-ec2-instance-selector/pkg/webhook"
+)
+
+const (
+	nodePool = `{"apiVersion": "karpenter.sh/v1", "kind": "NodePool", "metadata": {"name": "default"},
+"spec": {"template": {"spec": {"requirements": [
+  {"key": "node.kubernetes.io/instance-type", "operator": "In", "values": ["m5.large", "p3.16xlarge"]},
+  {"key": "kubernetes.io/arch", "operator": "In", "values": ["amd64"]}]}}}}`
+	autoScalingGroup = `{"apiVersion": "autoscaling.services.k8s.aws/v1alpha1", "kind": "AutoScalingGroup", "metadata": {"name": "web"},
+"spec": {"mixedInstancesPolicy": {"launchTemplate": {"overrides": [{"instanceType": "m5.large"}, {"instanceType": "m5a.large"}]}}}}`
+)
+
+// Mocking helpers
+
+type mockedInstanceSelector struct {
+	auditResults *selector.AuditResults
+	err          error
+	candidates   []string
+}
+
+func (m *mockedInstanceSelector) Audit(filters selector.Filters, candidates []string) (*selector.AuditResults, error) {
+	m.candidates = candidates
+	return m.auditResults, m.err
+}
+
+func newRequest(kind string, object string) *webhook.AdmissionRequest {
+	return &webhook.AdmissionRequest{
+		UID:       "705ab4f5-6393-11e8-b7cc-42010a800002",
+		Kind:      webhook.GroupVersionKind{Kind: kind},
+		Name:      "default",
+		Operation: "CREATE",
+		Object:    json.RawMessage(object),
+	}
+}
+
+// Tests
+
+func TestReview_Allowed(t *testing.T) {
+	instanceSelector := &mockedInstanceSelector{auditResults: &selector.AuditResults{Passed: []string{"m5.large", "m5a.large"}}}
+	response := webhook.New(instanceSelector, selector.Filters{}).Review(newRequest("AutoScalingGroup", autoScalingGroup))
+	h.Assert(t, response.Allowed, "The Auto Scaling group should be allowed")
+	h.Equals(t, "705ab4f5-6393-11e8-b7cc-42010a800002", response.UID)
+	h.Equals(t, []string{"m5.large", "m5a.large"}, instanceSelector.candidates)
+}
+
+func TestReview_Rejected(t *testing.T) {
+	instanceSelector := &mockedInstanceSelector{auditResults: &selector.AuditResults{
+		Passed:   []string{"m5.large"},
+		Failed:   map[string][]selector.FilterRejection{"p3.16xlarge": {{Filter: "gpusRange"}}},
+		NotFound: []string{},
+	}}
+	response := webhook.New(instanceSelector, selector.Filters{}).Review(newRequest("NodePool", nodePool))
+	h.Assert(t, !response.Allowed, "The NodePool should be rejected")
+	h.Equals(t, int32(http.StatusForbidden), response.Result.Code)
+	h.Equals(t, "NodePool default references instance types which are not allowed by the instance type policy: p3.16xlarge (gpusRange)", response.Result.Message)
+}
+
+func TestReview_NotFound(t *testing.T) {
+	instanceSelector := &mockedInstanceSelector{auditResults: &selector.AuditResults{NotFound: []string{"m5.largee"}}}
+	response := webhook.New(instanceSelector, selector.Filters{}).Review(newRequest("Nodegroup", `{"spec": {"instanceTypes": ["m5.largee"]}}`))
+	h.Assert(t, !response.Allowed, "Unknown instance types should be rejected")
+}
+
+func TestReview_NoInstanceTypes(t *testing.T) {
+	instanceSelector := &mockedInstanceSelector{err: errors.New("Audit should not be called")}
+	response := webhook.New(instanceSelector, selector.Filters{}).Review(newRequest("ConfigMap", `{"data": {"key": "value"}}`))
+	h.Assert(t, response.Allowed, "Objects without instance types should be allowed")
+	h.Assert(t, instanceSelector.candidates == nil, "Audit should not be called")
+}
+
+func TestReview_AuditError(t *testing.T) {
+	instanceSelector := &mockedInstanceSelector{err: errors.New("error")}
+	response := webhook.New(instanceSelector, selector.Filters{}).Review(newRequest("AutoScalingGroup", autoScalingGroup))
+	h.Assert(t, !response.Allowed, "The object should be rejected when the instance types cannot be checked")
+	h.Equals(t, int32(http.StatusInternalServerError), response.Result.Code)
+}
+
+func TestReview_InvalidObject(t *testing.T) {
+	response := webhook.New(&mockedInstanceSelector{}, selector.Filters{}).Review(newRequest("AutoScalingGroup", `{"spec":`))
+	h.Assert(t, !response.Allowed, "Invalid objects should be rejected")
+	h.Equals(t, int32(http.StatusBadRequest), response.Result.Code)
+}
+
+func TestInstanceTypes(t *testing.T) {
+	instanceTypes, err := webhook.InstanceTypes("NodePool", []byte(nodePool))
+	h.Ok(t, err)
+	h.Equals(t, []string{"m5.large", "p3.16xlarge"}, instanceTypes)
+	instanceTypes, err = webhook.InstanceTypes("AutoScalingGroup", []byte(autoScalingGroup))
+	h.Ok(t, err)
+	h.Equals(t, []string{"m5.large", "m5a.large"}, instanceTypes)
+}
+
+func TestServeHTTP(t *testing.T) {
+	instanceSelector := &mockedInstanceSelector{auditResults: &selector.AuditResults{Passed: []string{"m5.large", "m5a.large"}}}
+	body, err := json.Marshal(webhook.AdmissionReview{
+		APIVersion: "admission.k8s.io/v1",
+		Kind:       "AdmissionReview",
+		Request:    newRequest("AutoScalingGroup", autoScalingGroup),
+	})
+	h.Ok(t, err)
+	recorder := httptest.NewRecorder()
+	webhook.New(instanceSelector, selector.Filters{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader(body)))
+	h.Equals(t, http.StatusOK, recorder.Code)
+	review := webhook.AdmissionReview{}
+	h.Ok(t, json.Unmarshal(recorder.Body.Bytes(), &review))
+	h.Equals(t, "AdmissionReview", review.Kind)
+	h.Assert(t, review.Response.Allowed, "The Auto Scaling group should be allowed")
+	h.Equals(t, "705ab4f5-6393-11e8-b7cc-42010a800002", review.Response.UID)
+}
+
+func TestServeHTTP_InvalidReview(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	webhook.New(&mockedInstanceSelector{}, selector.Filters{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader([]byte("{}"))))
+	h.Equals(t, http.StatusBadRequest, recorder.Code)
+}